@@ -15,6 +15,7 @@ import (
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/fransfilastap/kontak/pkg/migrations"
 	"github.com/fransfilastap/kontak/pkg/security"
+	"github.com/fransfilastap/kontak/pkg/storage"
 	"github.com/fransfilastap/kontak/pkg/types"
 	"github.com/fransfilastap/kontak/pkg/wa"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,6 +25,10 @@ type Kontak struct {
 	HttpServer       *http.Server
 	WhatsappClient   *wa.WhatsappClient
 	BroadcastService *wa.BroadcastService
+	FlowService      *wa.FlowService
+	MediaService     *wa.MediaService
+	MediaJanitor     *wa.MediaJanitor
+	ContactSync      *wa.ContactSyncService
 	Config           *config.Config
 	qrChan           chan types.WaConnectEvent
 }
@@ -83,7 +88,26 @@ func NewKontak(config *config.Config) *Kontak {
 		logger.Fatal("Could not load device subscriptions: %v", err)
 	}
 
-	waClient := wa.NewWhatsappClient(ctx, config.DB, dbQueries, qrChan, subscriptionStore)
+	optOutStore := wa.NewOptOutStore(dbQueries)
+	eventBroker := wa.NewEventBroker()
+
+	mediaStorage, err := storage.New(storage.Config{
+		Driver:      config.MediaStorage,
+		LocalDir:    config.MediaDir,
+		S3Endpoint:  config.S3Endpoint,
+		S3Region:    config.S3Region,
+		S3Bucket:    config.S3Bucket,
+		S3AccessKey: config.S3AccessKey,
+		S3SecretKey: config.S3SecretKey,
+		S3PathStyle: config.S3PathStyle,
+	})
+	if err != nil {
+		logger.Fatal("Could not create media storage: %v", err)
+	}
+	urlSigner := storage.NewURLSigner(config.MediaURLSecret)
+
+	wa.DefaultCountryCode = config.DefaultCountryCode
+	waClient := wa.NewWhatsappClient(ctx, config.DB, dbQueries, qrChan, subscriptionStore, optOutStore, eventBroker, mediaStorage, urlSigner)
 	// Use the same store implementation for device management
 	store, err := wa.NewPostgresStore(ctx, config.DB, dbQueries, nil)
 	if err != nil {
@@ -91,21 +115,44 @@ func NewKontak(config *config.Config) *Kontak {
 	}
 	deviceManagement := wa.NewDeviceStore(store)
 
-	webhookHandler := http.NewWebhook(waClient, deviceManagement, dbQueries, subscriptionStore)
+	webhookHandler := http.NewWebhook(waClient, deviceManagement, dbQueries, subscriptionStore, optOutStore)
 	authHandler := http.NewAuthHandler(dbQueries, config)
-	groupHandler := http.NewGroupHandler(deviceManagement, waClient)
-	contactHandler := http.NewContactHandler(deviceManagement, waClient)
+	groupHandler := http.NewGroupHandler(deviceManagement, waClient, dbQueries)
+	contactHandler := http.NewContactHandler(deviceManagement, waClient, dbQueries)
 	inboxHandler := http.NewInboxHandler(dbQueries, waClient, deviceManagement)
 	broadcastHandler := http.NewBroadcastHandler(dbQueries, deviceManagement)
-	broadcastService := wa.NewBroadcastService(dbQueries, waClient)
-
-	httpServer := http.NewServer(addr, webhookHandler, authHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, dbQueries, subscriptionStore)
+	optOutHandler := http.NewOptOutHandler(optOutStore)
+	autoReplyHandler := http.NewAutoReplyHandler(dbQueries, deviceManagement)
+	awayMessageHandler := http.NewAwayMessageHandler(dbQueries, deviceManagement)
+	flowHandler := http.NewFlowHandler(dbQueries, deviceManagement)
+	botWebhookHandler := http.NewBotWebhookHandler(dbQueries, deviceManagement)
+	teamInboxHandler := http.NewTeamInboxHandler(dbQueries, deviceManagement)
+	labelHandler := http.NewLabelHandler(dbQueries, waClient, deviceManagement)
+	searchHandler := http.NewSearchHandler(dbQueries, deviceManagement)
+	eventStreamHandler := http.NewEventStreamHandler(deviceManagement, eventBroker)
+	historySyncHandler := http.NewHistorySyncHandler(dbQueries, deviceManagement)
+	userMediaQuota := int64(config.MediaUserQuotaMB) << 20
+	mediaHandler := http.NewMediaHandler(dbQueries, waClient, deviceManagement, mediaStorage, urlSigner, userMediaQuota)
+	communityHandler := http.NewCommunityHandler(deviceManagement, waClient, dbQueries)
+	newsletterHandler := http.NewNewsletterHandler(deviceManagement, waClient)
+	statusHandler := http.NewStatusHandler(deviceManagement, waClient, dbQueries)
+	broadcastService := wa.NewBroadcastService(dbQueries, waClient, optOutStore)
+	flowService := wa.NewFlowService(dbQueries, waClient)
+	mediaService := wa.NewMediaService(dbQueries, waClient)
+	mediaJanitor := wa.NewMediaJanitor(dbQueries, mediaStorage, userMediaQuota)
+	contactSync := wa.NewContactSyncService(dbQueries, waClient)
+
+	httpServer := http.NewServer(addr, webhookHandler, authHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, optOutHandler, autoReplyHandler, awayMessageHandler, flowHandler, botWebhookHandler, teamInboxHandler, labelHandler, searchHandler, eventStreamHandler, historySyncHandler, mediaHandler, communityHandler, newsletterHandler, statusHandler, dbQueries, subscriptionStore)
 
 	return &Kontak{
 		HttpServer: httpServer,
 
 		WhatsappClient:   waClient,
 		BroadcastService: broadcastService,
+		FlowService:      flowService,
+		MediaService:     mediaService,
+		MediaJanitor:     mediaJanitor,
+		ContactSync:      contactSync,
 		Config:           config,
 		qrChan:           qrChan,
 	}
@@ -115,6 +162,10 @@ func (app *Kontak) Run() {
 	var wg sync.WaitGroup
 	go app.HttpServer.Start()
 	go app.BroadcastService.Start(context.Background())
+	go app.FlowService.Start(context.Background())
+	go app.MediaService.Start(context.Background())
+	go app.MediaJanitor.Start(context.Background())
+	go app.ContactSync.Start(context.Background())
 
 	app.WhatsappClient.Connect(context.Background())
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/joho/godotenv"
@@ -22,6 +23,18 @@ type Config struct {
 	LogFileMaxBackups int
 	LogFileMaxAge     int
 	LogFileCompress   bool
+	MediaStorage      string
+	MediaDir          string
+	MediaURLSecret    string
+	MediaUserQuotaMB  int
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyle       bool
+	// DefaultCountryCode is the calling code for phone numbers given in national format
+	DefaultCountryCode string
 }
 
 func LoadConfig() *Config {
@@ -90,19 +103,62 @@ func LoadConfig() *Config {
 		logFileCompress = false
 	}
 
+	// Load media storage configuration
+	mediaStorage := os.Getenv("MEDIA_STORAGE")
+	if mediaStorage == "" {
+		mediaStorage = "local" // Default media storage driver
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "uploads" // Default local media directory
+	}
+
+	mediaURLSecret := os.Getenv("MEDIA_URL_SECRET")
+	if mediaURLSecret == "" {
+		mediaURLSecret = jwtSecret
+	}
+
+	mediaUserQuotaMB := 0 // Default no per-user media quota
+	if quota, err := strconv.Atoi(os.Getenv("MEDIA_USER_QUOTA_MB")); err == nil && quota > 0 {
+		mediaUserQuotaMB = quota
+	}
+
+	s3PathStyle := true // MinIO and most self-hosted stores need path-style requests
+	s3PathStyleStr := os.Getenv("S3_PATH_STYLE")
+	if s3PathStyleStr == "false" || s3PathStyleStr == "0" {
+		s3PathStyle = false
+	}
+
+	defaultCountryCode := "62" // National numbers such as 0812… are Indonesian unless configured otherwise
+	if code, ok := os.LookupEnv("DEFAULT_COUNTRY_CODE"); ok {
+		defaultCountryCode = strings.TrimPrefix(code, "+")
+	}
+
 	return &Config{
-		Port:              portInt,
-		Host:              host,
-		DB:                db,
-		JwtSecret:         jwtSecret,
-		InitUser:          initUser,
-		InitPass:          initPass,
-		LogLevel:          logLevel,
-		LogFileEnabled:    logFileEnabled,
-		LogFilePath:       logFilePath,
-		LogFileMaxSize:    logFileMaxSize,
-		LogFileMaxBackups: logFileMaxBackups,
-		LogFileMaxAge:     logFileMaxAge,
-		LogFileCompress:   logFileCompress,
+		Port:               portInt,
+		Host:               host,
+		DB:                 db,
+		JwtSecret:          jwtSecret,
+		InitUser:           initUser,
+		InitPass:           initPass,
+		LogLevel:           logLevel,
+		LogFileEnabled:     logFileEnabled,
+		LogFilePath:        logFilePath,
+		LogFileMaxSize:     logFileMaxSize,
+		LogFileMaxBackups:  logFileMaxBackups,
+		LogFileMaxAge:      logFileMaxAge,
+		LogFileCompress:    logFileCompress,
+		MediaStorage:       mediaStorage,
+		MediaDir:           mediaDir,
+		MediaURLSecret:     mediaURLSecret,
+		MediaUserQuotaMB:   mediaUserQuotaMB,
+		S3Endpoint:         os.Getenv("S3_ENDPOINT"),
+		S3Region:           os.Getenv("S3_REGION"),
+		S3Bucket:           os.Getenv("S3_BUCKET"),
+		S3AccessKey:        os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:        os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:        s3PathStyle,
+		DefaultCountryCode: defaultCountryCode,
 	}
 }
@@ -22,7 +22,8 @@ INSERT INTO broadcast_jobs (
         media_filename,
         cooldown,
         is_scheduled,
-        scheduled_at
+        scheduled_at,
+        is_transactional
     )
 VALUES (
         $1,
@@ -34,22 +35,24 @@ VALUES (
         $7,
         $8,
         COALESCE($9::boolean, FALSE),
-        $10::timestamptz
+        $10::timestamptz,
+        COALESCE($11::boolean, FALSE)
     )
-RETURNING id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at
+RETURNING id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at, is_transactional
 `
 
 type CreateBroadcastJobParams struct {
-	UserID        pgtype.Int4        `json:"user_id"`
-	DeviceID      pgtype.Text        `json:"device_id"`
-	Name          string             `json:"name"`
-	MessageType   pgtype.Text        `json:"message_type"`
-	Content       string             `json:"content"`
-	MediaUrl      pgtype.Text        `json:"media_url"`
-	MediaFilename pgtype.Text        `json:"media_filename"`
-	Cooldown      pgtype.Int4        `json:"cooldown"`
-	IsScheduled   bool               `json:"is_scheduled"`
-	ScheduledAt   pgtype.Timestamptz `json:"scheduled_at"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	DeviceID        pgtype.Text        `json:"device_id"`
+	Name            string             `json:"name"`
+	MessageType     pgtype.Text        `json:"message_type"`
+	Content         string             `json:"content"`
+	MediaUrl        pgtype.Text        `json:"media_url"`
+	MediaFilename   pgtype.Text        `json:"media_filename"`
+	Cooldown        pgtype.Int4        `json:"cooldown"`
+	IsScheduled     bool               `json:"is_scheduled"`
+	ScheduledAt     pgtype.Timestamptz `json:"scheduled_at"`
+	IsTransactional bool               `json:"is_transactional"`
 }
 
 func (q *Queries) CreateBroadcastJob(ctx context.Context, arg CreateBroadcastJobParams) (BroadcastJob, error) {
@@ -64,6 +67,7 @@ func (q *Queries) CreateBroadcastJob(ctx context.Context, arg CreateBroadcastJob
 		arg.Cooldown,
 		arg.IsScheduled,
 		arg.ScheduledAt,
+		arg.IsTransactional,
 	)
 	var i BroadcastJob
 	err := row.Scan(
@@ -81,6 +85,7 @@ func (q *Queries) CreateBroadcastJob(ctx context.Context, arg CreateBroadcastJob
 		&i.UpdatedAt,
 		&i.IsScheduled,
 		&i.ScheduledAt,
+		&i.IsTransactional,
 	)
 	return i, err
 }
@@ -101,7 +106,7 @@ func (q *Queries) CreateBroadcastRecipient(ctx context.Context, arg CreateBroadc
 }
 
 const getBroadcastJob = `-- name: GetBroadcastJob :one
-SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at
+SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at, is_transactional
 FROM broadcast_jobs
 WHERE id = $1
     AND user_id = $2
@@ -130,12 +135,13 @@ func (q *Queries) GetBroadcastJob(ctx context.Context, arg GetBroadcastJobParams
 		&i.UpdatedAt,
 		&i.IsScheduled,
 		&i.ScheduledAt,
+		&i.IsTransactional,
 	)
 	return i, err
 }
 
 const getBroadcastJobs = `-- name: GetBroadcastJobs :many
-SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at
+SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at, is_transactional
 FROM broadcast_jobs
 WHERE user_id = $1
 ORDER BY created_at DESC
@@ -165,6 +171,7 @@ func (q *Queries) GetBroadcastJobs(ctx context.Context, userID pgtype.Int4) ([]B
 			&i.UpdatedAt,
 			&i.IsScheduled,
 			&i.ScheduledAt,
+			&i.IsTransactional,
 		); err != nil {
 			return nil, err
 		}
@@ -210,7 +217,7 @@ func (q *Queries) GetBroadcastRecipients(ctx context.Context, jobID pgtype.UUID)
 }
 
 const getPendingBroadcastJobs = `-- name: GetPendingBroadcastJobs :many
-SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at
+SELECT id, user_id, device_id, name, message_type, content, media_url, media_filename, cooldown, status, created_at, updated_at, is_scheduled, scheduled_at, is_transactional
 FROM broadcast_jobs
 WHERE status = 'pending'
     AND (
@@ -247,6 +254,7 @@ func (q *Queries) GetPendingBroadcastJobs(ctx context.Context) ([]BroadcastJob,
 			&i.UpdatedAt,
 			&i.IsScheduled,
 			&i.ScheduledAt,
+			&i.IsTransactional,
 		); err != nil {
 			return nil, err
 		}
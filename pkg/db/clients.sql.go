@@ -147,6 +147,39 @@ func (q *Queries) GetClientByJID(ctx context.Context, jid pgtype.Text) (Client,
 	return i, err
 }
 
+const getClientForMember = `-- name: GetClientForMember :one
+SELECT id, name, whatsapp_number, jid, qr_code, is_connected, created_at, updated_at, deleted_at, user_id
+FROM clients c
+WHERE c.id = $1
+  AND (c.user_id = $2
+       OR c.user_id IS NULL
+       OR EXISTS (SELECT 1 FROM device_members m WHERE m.device_id = c.id AND m.user_id = $2))
+LIMIT 1
+`
+
+type GetClientForMemberParams struct {
+	ID     string      `json:"id"`
+	UserID pgtype.Int4 `json:"user_id"`
+}
+
+func (q *Queries) GetClientForMember(ctx context.Context, arg GetClientForMemberParams) (Client, error) {
+	row := q.db.QueryRow(ctx, getClientForMember, arg.ID, arg.UserID)
+	var i Client
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WhatsappNumber,
+		&i.Jid,
+		&i.QrCode,
+		&i.IsConnected,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
+	)
+	return i, err
+}
+
 const getClients = `-- name: GetClients :many
 SELECT id, name, whatsapp_number, jid, qr_code, is_connected, created_at, updated_at, deleted_at, user_id
 FROM clients
@@ -16,7 +16,10 @@ SELECT
     m.id, m.device_id, m.user_id, m.recipient, m.recipient_type, m.message_type, m.content, m.media_url, m.media_filename, m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at, m.read_at, m.direction, m.wa_message_id, m.sender_jid,
     COALESCE(wc.full_name, wc.push_name, '') AS sender_name
 FROM message_logs m
-LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id AND wc.jid = m.sender_jid
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
 WHERE m.device_id = $1 AND m.recipient = $2
 ORDER BY m.sent_at ASC
 LIMIT $3 OFFSET $4
@@ -168,7 +171,7 @@ func (q *Queries) GetConversations(ctx context.Context, arg GetConversationsPara
 }
 
 const getMessageHistory = `-- name: GetMessageHistory :many
-SELECT id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid
+SELECT id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid, media_deleted_at
 FROM message_logs
 WHERE user_id = $1
 ORDER BY sent_at DESC
@@ -208,6 +211,48 @@ func (q *Queries) GetMessageHistory(ctx context.Context, arg GetMessageHistoryPa
 			&i.Direction,
 			&i.WaMessageID,
 			&i.SenderJid,
+			&i.MediaDeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadIncomingMessages = `-- name: GetUnreadIncomingMessages :many
+SELECT wa_message_id, sender_jid
+FROM message_logs
+WHERE device_id = $1 AND recipient = $2 AND direction = 'incoming' AND status != 'read'
+  AND wa_message_id IS NOT NULL
+ORDER BY sent_at
+`
+
+type GetUnreadIncomingMessagesParams struct {
+	DeviceID  pgtype.Text `json:"device_id"`
+	Recipient string      `json:"recipient"`
+}
+
+type GetUnreadIncomingMessagesRow struct {
+	WaMessageID pgtype.Text `json:"wa_message_id"`
+	SenderJid   pgtype.Text `json:"sender_jid"`
+}
+
+func (q *Queries) GetUnreadIncomingMessages(ctx context.Context, arg GetUnreadIncomingMessagesParams) ([]GetUnreadIncomingMessagesRow, error) {
+	rows, err := q.db.Query(ctx, getUnreadIncomingMessages, arg.DeviceID, arg.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadIncomingMessagesRow
+	for rows.Next() {
+		var i GetUnreadIncomingMessagesRow
+		if err := rows.Scan(
+			&i.WaMessageID,
+			&i.SenderJid,
 		); err != nil {
 			return nil, err
 		}
@@ -222,7 +267,7 @@ func (q *Queries) GetMessageHistory(ctx context.Context, arg GetMessageHistoryPa
 const logIncomingMessage = `-- name: LogIncomingMessage :one
 INSERT INTO message_logs (device_id, recipient, recipient_type, message_type, content, media_url, media_filename, status, direction, sender_jid, wa_message_id)
 VALUES ($1, $2, $3, $4, $5, $6::text, $7::varchar(255), 'delivered', 'incoming', $8, $9)
-RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid
+RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid, media_deleted_at
 `
 
 type LogIncomingMessageParams struct {
@@ -269,6 +314,7 @@ func (q *Queries) LogIncomingMessage(ctx context.Context, arg LogIncomingMessage
 		&i.Direction,
 		&i.WaMessageID,
 		&i.SenderJid,
+		&i.MediaDeletedAt,
 	)
 	return i, err
 }
@@ -276,7 +322,7 @@ func (q *Queries) LogIncomingMessage(ctx context.Context, arg LogIncomingMessage
 const logOutgoingMessage = `-- name: LogOutgoingMessage :one
 INSERT INTO message_logs (device_id, recipient, recipient_type, message_type, content, media_url, media_filename, status, direction, wa_message_id)
 VALUES ($1, $2, $3, $4, $5, $6::text, $7::varchar(255), 'sent', 'outgoing', $8)
-RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid
+RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid, media_deleted_at
 `
 
 type LogOutgoingMessageParams struct {
@@ -321,13 +367,14 @@ func (q *Queries) LogOutgoingMessage(ctx context.Context, arg LogOutgoingMessage
 		&i.Direction,
 		&i.WaMessageID,
 		&i.SenderJid,
+		&i.MediaDeletedAt,
 	)
 	return i, err
 }
 
 const markConversationRead = `-- name: MarkConversationRead :exec
 UPDATE message_logs
-SET status = 'read'
+SET status = 'read', read_at = NOW()
 WHERE device_id = $1 AND recipient = $2 AND direction = 'incoming' AND status != 'read'
 `
 
@@ -362,7 +409,7 @@ VALUES ($1,
         $8::varchar(255), -- or NULL
         $9::jsonb, -- or NULL
         'sent')
-RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid
+RETURNING id, device_id, user_id, recipient, recipient_type, message_type, content, media_url, media_filename, buttons, template_id, status, sent_at, delivered_at, read_at, direction, wa_message_id, sender_jid, media_deleted_at
 `
 
 type SendMessageDataParams struct {
@@ -409,6 +456,7 @@ func (q *Queries) SendMessageData(ctx context.Context, arg SendMessageDataParams
 		&i.Direction,
 		&i.WaMessageID,
 		&i.SenderJid,
+		&i.MediaDeletedAt,
 	)
 	return i, err
 }
@@ -14,12 +14,15 @@ import (
 const getThreadMessages = `-- name: GetThreadMessages :many
 SELECT
     m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
-    m.message_type, m.content, m.media_url, m.media_filename,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
     m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
     m.read_at, m.direction, m.wa_message_id, m.sender_jid,
     COALESCE(wc.full_name, wc.push_name, '') AS sender_name
 FROM message_logs m
-LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id AND wc.jid = m.sender_jid
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
 WHERE m.device_id = $1 AND m.recipient = $2
 ORDER BY m.sent_at ASC
 LIMIT $3 OFFSET $4
@@ -33,25 +36,26 @@ type GetThreadMessagesParams struct {
 }
 
 type GetThreadMessagesRow struct {
-	ID            pgtype.UUID        `json:"id"`
-	DeviceID      pgtype.Text        `json:"device_id"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	Recipient     string             `json:"recipient"`
-	RecipientType pgtype.Text        `json:"recipient_type"`
-	MessageType   pgtype.Text        `json:"message_type"`
-	Content       string             `json:"content"`
-	MediaUrl      pgtype.Text        `json:"media_url"`
-	MediaFilename pgtype.Text        `json:"media_filename"`
-	Buttons       []byte             `json:"buttons"`
-	TemplateID    pgtype.UUID        `json:"template_id"`
-	Status        pgtype.Text        `json:"status"`
-	SentAt        pgtype.Timestamptz `json:"sent_at"`
-	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
-	ReadAt        pgtype.Timestamptz `json:"read_at"`
-	Direction     string             `json:"direction"`
-	WaMessageID   pgtype.Text        `json:"wa_message_id"`
-	SenderJid     pgtype.Text        `json:"sender_jid"`
-	SenderName    string             `json:"sender_name"`
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       pgtype.Text        `json:"device_id"`
+	UserID         pgtype.Int4        `json:"user_id"`
+	Recipient      string             `json:"recipient"`
+	RecipientType  pgtype.Text        `json:"recipient_type"`
+	MessageType    pgtype.Text        `json:"message_type"`
+	Content        string             `json:"content"`
+	MediaUrl       pgtype.Text        `json:"media_url"`
+	MediaFilename  pgtype.Text        `json:"media_filename"`
+	MediaDeletedAt pgtype.Timestamptz `json:"media_deleted_at"`
+	Buttons        []byte             `json:"buttons"`
+	TemplateID     pgtype.UUID        `json:"template_id"`
+	Status         pgtype.Text        `json:"status"`
+	SentAt         pgtype.Timestamptz `json:"sent_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	ReadAt         pgtype.Timestamptz `json:"read_at"`
+	Direction      string             `json:"direction"`
+	WaMessageID    pgtype.Text        `json:"wa_message_id"`
+	SenderJid      pgtype.Text        `json:"sender_jid"`
+	SenderName     string             `json:"sender_name"`
 }
 
 func (q *Queries) GetThreadMessages(ctx context.Context, arg GetThreadMessagesParams) ([]GetThreadMessagesRow, error) {
@@ -78,6 +82,199 @@ func (q *Queries) GetThreadMessages(ctx context.Context, arg GetThreadMessagesPa
 			&i.Content,
 			&i.MediaUrl,
 			&i.MediaFilename,
+			&i.MediaDeletedAt,
+			&i.Buttons,
+			&i.TemplateID,
+			&i.Status,
+			&i.SentAt,
+			&i.DeliveredAt,
+			&i.ReadAt,
+			&i.Direction,
+			&i.WaMessageID,
+			&i.SenderJid,
+			&i.SenderName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadMessagesAfter = `-- name: GetThreadMessagesAfter :many
+SELECT
+    m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
+    m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
+    m.read_at, m.direction, m.wa_message_id, m.sender_jid,
+    COALESCE(wc.full_name, wc.push_name, '') AS sender_name
+FROM message_logs m
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
+WHERE m.device_id = $1 AND m.recipient = $2
+  AND (m.sent_at, m.id) > ($3::timestamptz, $4::uuid)
+ORDER BY m.sent_at ASC, m.id ASC
+LIMIT $5
+`
+
+type GetThreadMessagesAfterParams struct {
+	DeviceID   pgtype.Text        `json:"device_id"`
+	Recipient  string             `json:"recipient"`
+	SentAt     pgtype.Timestamptz `json:"sent_at"`
+	ID         pgtype.UUID        `json:"id"`
+	QueryLimit int32              `json:"query_limit"`
+}
+
+type GetThreadMessagesAfterRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       pgtype.Text        `json:"device_id"`
+	UserID         pgtype.Int4        `json:"user_id"`
+	Recipient      string             `json:"recipient"`
+	RecipientType  pgtype.Text        `json:"recipient_type"`
+	MessageType    pgtype.Text        `json:"message_type"`
+	Content        string             `json:"content"`
+	MediaUrl       pgtype.Text        `json:"media_url"`
+	MediaFilename  pgtype.Text        `json:"media_filename"`
+	MediaDeletedAt pgtype.Timestamptz `json:"media_deleted_at"`
+	Buttons        []byte             `json:"buttons"`
+	TemplateID     pgtype.UUID        `json:"template_id"`
+	Status         pgtype.Text        `json:"status"`
+	SentAt         pgtype.Timestamptz `json:"sent_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	ReadAt         pgtype.Timestamptz `json:"read_at"`
+	Direction      string             `json:"direction"`
+	WaMessageID    pgtype.Text        `json:"wa_message_id"`
+	SenderJid      pgtype.Text        `json:"sender_jid"`
+	SenderName     string             `json:"sender_name"`
+}
+
+func (q *Queries) GetThreadMessagesAfter(ctx context.Context, arg GetThreadMessagesAfterParams) ([]GetThreadMessagesAfterRow, error) {
+	rows, err := q.db.Query(ctx, getThreadMessagesAfter,
+		arg.DeviceID,
+		arg.Recipient,
+		arg.SentAt,
+		arg.ID,
+		arg.QueryLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetThreadMessagesAfterRow
+	for rows.Next() {
+		var i GetThreadMessagesAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeviceID,
+			&i.UserID,
+			&i.Recipient,
+			&i.RecipientType,
+			&i.MessageType,
+			&i.Content,
+			&i.MediaUrl,
+			&i.MediaFilename,
+			&i.MediaDeletedAt,
+			&i.Buttons,
+			&i.TemplateID,
+			&i.Status,
+			&i.SentAt,
+			&i.DeliveredAt,
+			&i.ReadAt,
+			&i.Direction,
+			&i.WaMessageID,
+			&i.SenderJid,
+			&i.SenderName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadMessagesBefore = `-- name: GetThreadMessagesBefore :many
+SELECT
+    m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
+    m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
+    m.read_at, m.direction, m.wa_message_id, m.sender_jid,
+    COALESCE(wc.full_name, wc.push_name, '') AS sender_name
+FROM message_logs m
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
+WHERE m.device_id = $1 AND m.recipient = $2
+  AND (m.sent_at, m.id) < ($3::timestamptz, $4::uuid)
+ORDER BY m.sent_at DESC, m.id DESC
+LIMIT $5
+`
+
+type GetThreadMessagesBeforeParams struct {
+	DeviceID   pgtype.Text        `json:"device_id"`
+	Recipient  string             `json:"recipient"`
+	SentAt     pgtype.Timestamptz `json:"sent_at"`
+	ID         pgtype.UUID        `json:"id"`
+	QueryLimit int32              `json:"query_limit"`
+}
+
+type GetThreadMessagesBeforeRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       pgtype.Text        `json:"device_id"`
+	UserID         pgtype.Int4        `json:"user_id"`
+	Recipient      string             `json:"recipient"`
+	RecipientType  pgtype.Text        `json:"recipient_type"`
+	MessageType    pgtype.Text        `json:"message_type"`
+	Content        string             `json:"content"`
+	MediaUrl       pgtype.Text        `json:"media_url"`
+	MediaFilename  pgtype.Text        `json:"media_filename"`
+	MediaDeletedAt pgtype.Timestamptz `json:"media_deleted_at"`
+	Buttons        []byte             `json:"buttons"`
+	TemplateID     pgtype.UUID        `json:"template_id"`
+	Status         pgtype.Text        `json:"status"`
+	SentAt         pgtype.Timestamptz `json:"sent_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	ReadAt         pgtype.Timestamptz `json:"read_at"`
+	Direction      string             `json:"direction"`
+	WaMessageID    pgtype.Text        `json:"wa_message_id"`
+	SenderJid      pgtype.Text        `json:"sender_jid"`
+	SenderName     string             `json:"sender_name"`
+}
+
+func (q *Queries) GetThreadMessagesBefore(ctx context.Context, arg GetThreadMessagesBeforeParams) ([]GetThreadMessagesBeforeRow, error) {
+	rows, err := q.db.Query(ctx, getThreadMessagesBefore,
+		arg.DeviceID,
+		arg.Recipient,
+		arg.SentAt,
+		arg.ID,
+		arg.QueryLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetThreadMessagesBeforeRow
+	for rows.Next() {
+		var i GetThreadMessagesBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeviceID,
+			&i.UserID,
+			&i.Recipient,
+			&i.RecipientType,
+			&i.MessageType,
+			&i.Content,
+			&i.MediaUrl,
+			&i.MediaFilename,
+			&i.MediaDeletedAt,
 			&i.Buttons,
 			&i.TemplateID,
 			&i.Status,
@@ -110,19 +307,61 @@ SELECT
     t.last_message_type,
     t.last_message_direction,
     t.unread_count,
-    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name
+    t.status,
+    t.assigned_user_id,
+    t.is_pinned,
+    t.is_archived,
+    t.is_muted,
+    t.muted_until,
+    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name,
+    u.email AS assignee_email,
+    ARRAY(
+        SELECT tl.label_id
+        FROM thread_labels tl
+        WHERE tl.device_id = t.device_id AND tl.chat_jid = t.chat_jid
+    )::uuid[] AS label_ids
 FROM message_threads t
 LEFT JOIN whatsapp_contacts wc ON wc.device_id = t.device_id AND wc.jid = t.chat_jid
 LEFT JOIN whatsapp_groups wg ON wg.device_id = t.device_id AND wg.group_id = t.chat_jid
+LEFT JOIN users u ON u.id = t.assigned_user_id
 WHERE t.device_id = $1
-ORDER BY t.last_message_at DESC
-LIMIT $3 OFFSET $2
+  AND ($2::varchar IS NULL OR t.status = $2)
+  AND ($3::int IS NULL OR t.assigned_user_id = $3)
+  AND (NOT $4::bool OR t.assigned_user_id IS NULL)
+  AND ($5::bool IS NULL OR t.is_archived = $5)
+  AND ($6::uuid IS NULL OR EXISTS (
+      SELECT 1 FROM thread_labels tl
+      WHERE tl.device_id = t.device_id AND tl.chat_jid = t.chat_jid AND tl.label_id = $6
+  ))
+  AND ($7::bool IS NULL OR (t.is_pinned, t.last_message_at, t.id) < (
+      $7, $8::timestamptz, $9::uuid
+  ))
+  AND ($10::bool IS NULL OR (t.is_pinned, t.last_message_at, t.id) > (
+      $10, $11::timestamptz, $12::uuid
+  ))
+ORDER BY
+    CASE WHEN $10::bool IS NULL THEN t.is_pinned END DESC,
+    CASE WHEN $10::bool IS NULL THEN t.last_message_at END DESC,
+    CASE WHEN $10::bool IS NULL THEN t.id END DESC,
+    t.is_pinned ASC, t.last_message_at ASC, t.id ASC
+LIMIT $14 OFFSET $13
 `
 
 type GetThreadsParams struct {
-	DeviceID    string `json:"device_id"`
-	QueryOffset int32  `json:"query_offset"`
-	QueryLimit  int32  `json:"query_limit"`
+	DeviceID       string             `json:"device_id"`
+	Status         pgtype.Text        `json:"status"`
+	AssignedUserID pgtype.Int4        `json:"assigned_user_id"`
+	UnassignedOnly bool               `json:"unassigned_only"`
+	Archived       pgtype.Bool        `json:"archived"`
+	LabelID        pgtype.UUID        `json:"label_id"`
+	CursorPinned   pgtype.Bool        `json:"cursor_pinned"`
+	CursorAt       pgtype.Timestamptz `json:"cursor_at"`
+	CursorID       pgtype.UUID        `json:"cursor_id"`
+	BeforePinned   pgtype.Bool        `json:"before_pinned"`
+	BeforeAt       pgtype.Timestamptz `json:"before_at"`
+	BeforeID       pgtype.UUID        `json:"before_id"`
+	QueryOffset    int32              `json:"query_offset"`
+	QueryLimit     int32              `json:"query_limit"`
 }
 
 type GetThreadsRow struct {
@@ -135,11 +374,34 @@ type GetThreadsRow struct {
 	LastMessageType      string             `json:"last_message_type"`
 	LastMessageDirection string             `json:"last_message_direction"`
 	UnreadCount          int32              `json:"unread_count"`
+	Status               string             `json:"status"`
+	AssignedUserID       pgtype.Int4        `json:"assigned_user_id"`
+	IsPinned             bool               `json:"is_pinned"`
+	IsArchived           bool               `json:"is_archived"`
+	IsMuted              bool               `json:"is_muted"`
+	MutedUntil           pgtype.Timestamptz `json:"muted_until"`
 	ChatName             string             `json:"chat_name"`
+	AssigneeEmail        pgtype.Text        `json:"assignee_email"`
+	LabelIds             []pgtype.UUID      `json:"label_ids"`
 }
 
 func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetThreadsRow, error) {
-	rows, err := q.db.Query(ctx, getThreads, arg.DeviceID, arg.QueryOffset, arg.QueryLimit)
+	rows, err := q.db.Query(ctx, getThreads,
+		arg.DeviceID,
+		arg.Status,
+		arg.AssignedUserID,
+		arg.UnassignedOnly,
+		arg.Archived,
+		arg.LabelID,
+		arg.CursorPinned,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.BeforePinned,
+		arg.BeforeAt,
+		arg.BeforeID,
+		arg.QueryOffset,
+		arg.QueryLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +419,15 @@ func (q *Queries) GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetTh
 			&i.LastMessageType,
 			&i.LastMessageDirection,
 			&i.UnreadCount,
+			&i.Status,
+			&i.AssignedUserID,
+			&i.IsPinned,
+			&i.IsArchived,
+			&i.IsMuted,
+			&i.MutedUntil,
 			&i.ChatName,
+			&i.AssigneeEmail,
+			&i.LabelIds,
 		); err != nil {
 			return nil, err
 		}
@@ -185,6 +455,73 @@ func (q *Queries) ResetThreadUnread(ctx context.Context, arg ResetThreadUnreadPa
 	return err
 }
 
+const setThreadArchived = `-- name: SetThreadArchived :exec
+UPDATE message_threads
+SET is_archived = $3,
+    is_pinned = CASE WHEN $3 THEN FALSE ELSE is_pinned END,
+    updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2
+`
+
+type SetThreadArchivedParams struct {
+	DeviceID   string `json:"device_id"`
+	ChatJid    string `json:"chat_jid"`
+	IsArchived bool   `json:"is_archived"`
+}
+
+func (q *Queries) SetThreadArchived(ctx context.Context, arg SetThreadArchivedParams) error {
+	_, err := q.db.Exec(ctx, setThreadArchived,
+		arg.DeviceID,
+		arg.ChatJid,
+		arg.IsArchived,
+	)
+	return err
+}
+
+const setThreadMuted = `-- name: SetThreadMuted :exec
+UPDATE message_threads
+SET is_muted = $3, muted_until = $4, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2
+`
+
+type SetThreadMutedParams struct {
+	DeviceID   string             `json:"device_id"`
+	ChatJid    string             `json:"chat_jid"`
+	IsMuted    bool               `json:"is_muted"`
+	MutedUntil pgtype.Timestamptz `json:"muted_until"`
+}
+
+func (q *Queries) SetThreadMuted(ctx context.Context, arg SetThreadMutedParams) error {
+	_, err := q.db.Exec(ctx, setThreadMuted,
+		arg.DeviceID,
+		arg.ChatJid,
+		arg.IsMuted,
+		arg.MutedUntil,
+	)
+	return err
+}
+
+const setThreadPinned = `-- name: SetThreadPinned :exec
+UPDATE message_threads
+SET is_pinned = $3, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2
+`
+
+type SetThreadPinnedParams struct {
+	DeviceID string `json:"device_id"`
+	ChatJid  string `json:"chat_jid"`
+	IsPinned bool   `json:"is_pinned"`
+}
+
+func (q *Queries) SetThreadPinned(ctx context.Context, arg SetThreadPinnedParams) error {
+	_, err := q.db.Exec(ctx, setThreadPinned,
+		arg.DeviceID,
+		arg.ChatJid,
+		arg.IsPinned,
+	)
+	return err
+}
+
 const upsertThread = `-- name: UpsertThread :exec
 INSERT INTO message_threads (device_id, chat_jid, chat_type, last_message_at, last_message_content, last_message_type, last_message_direction, unread_count)
 VALUES ($1, $2, $3, NOW(), $4, $5, $6,
@@ -200,6 +537,11 @@ ON CONFLICT (device_id, chat_jid) DO UPDATE SET
         THEN message_threads.unread_count + 1
         ELSE message_threads.unread_count
     END,
+    away_sent_at = CASE
+        WHEN EXCLUDED.last_message_direction = 'outgoing' AND NOT $8::bool
+        THEN NULL
+        ELSE message_threads.away_sent_at
+    END,
     updated_at = NOW()
 `
 
@@ -211,6 +553,7 @@ type UpsertThreadParams struct {
 	MessageType string `json:"message_type"`
 	Direction   string `json:"direction"`
 	IsIncoming  bool   `json:"is_incoming"`
+	IsAutomated bool   `json:"is_automated"`
 }
 
 func (q *Queries) UpsertThread(ctx context.Context, arg UpsertThreadParams) error {
@@ -222,6 +565,7 @@ func (q *Queries) UpsertThread(ctx context.Context, arg UpsertThreadParams) erro
 		arg.MessageType,
 		arg.Direction,
 		arg.IsIncoming,
+		arg.IsAutomated,
 	)
 	return err
 }
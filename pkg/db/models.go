@@ -28,21 +28,76 @@ type ApiKeyLog struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type AutoReplyCooldown struct {
+	RuleID        pgtype.UUID        `json:"rule_id"`
+	ChatJid       string             `json:"chat_jid"`
+	LastRepliedAt pgtype.Timestamptz `json:"last_replied_at"`
+}
+
+type AutoReplyRule struct {
+	ID              pgtype.UUID        `json:"id"`
+	DeviceID        string             `json:"device_id"`
+	Name            string             `json:"name"`
+	Priority        int32              `json:"priority"`
+	IsActive        bool               `json:"is_active"`
+	MatchType       string             `json:"match_type"`
+	MatchValue      string             `json:"match_value"`
+	CaseSensitive   bool               `json:"case_sensitive"`
+	MessageTypes    []string           `json:"message_types"`
+	ChatScope       string             `json:"chat_scope"`
+	SenderJids      []string           `json:"sender_jids"`
+	GroupJids       []string           `json:"group_jids"`
+	HoursCondition  string             `json:"hours_condition"`
+	BusinessHours   []byte             `json:"business_hours"`
+	ReplyType       string             `json:"reply_type"`
+	ReplyText       string             `json:"reply_text"`
+	TemplateID      pgtype.UUID        `json:"template_id"`
+	MediaUrl        pgtype.Text        `json:"media_url"`
+	MediaFilename   pgtype.Text        `json:"media_filename"`
+	CooldownSeconds int32              `json:"cooldown_seconds"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AwayMessageSetting struct {
+	DeviceID              string             `json:"device_id"`
+	IsEnabled             bool               `json:"is_enabled"`
+	Message               string             `json:"message"`
+	BusinessHours         []byte             `json:"business_hours"`
+	ResendIntervalMinutes int32              `json:"resend_interval_minutes"`
+	IncludeGroups         bool               `json:"include_groups"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotWebhook struct {
+	DeviceID        string             `json:"device_id"`
+	IsEnabled       bool               `json:"is_enabled"`
+	Url             string             `json:"url"`
+	Secret          pgtype.Text        `json:"secret"`
+	TimeoutSeconds  int32              `json:"timeout_seconds"`
+	FallbackMessage pgtype.Text        `json:"fallback_message"`
+	IncludeGroups   bool               `json:"include_groups"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
 type BroadcastJob struct {
-	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	DeviceID      pgtype.Text        `json:"device_id"`
-	Name          string             `json:"name"`
-	MessageType   pgtype.Text        `json:"message_type"`
-	Content       string             `json:"content"`
-	MediaUrl      pgtype.Text        `json:"media_url"`
-	MediaFilename pgtype.Text        `json:"media_filename"`
-	Cooldown      pgtype.Int4        `json:"cooldown"`
-	Status        pgtype.Text        `json:"status"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
-	IsScheduled   bool               `json:"is_scheduled"`
-	ScheduledAt   pgtype.Timestamptz `json:"scheduled_at"`
+	ID              pgtype.UUID        `json:"id"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	DeviceID        pgtype.Text        `json:"device_id"`
+	Name            string             `json:"name"`
+	MessageType     pgtype.Text        `json:"message_type"`
+	Content         string             `json:"content"`
+	MediaUrl        pgtype.Text        `json:"media_url"`
+	MediaFilename   pgtype.Text        `json:"media_filename"`
+	Cooldown        pgtype.Int4        `json:"cooldown"`
+	Status          pgtype.Text        `json:"status"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	IsScheduled     bool               `json:"is_scheduled"`
+	ScheduledAt     pgtype.Timestamptz `json:"scheduled_at"`
+	IsTransactional bool               `json:"is_transactional"`
 }
 
 type BroadcastRecipient struct {
@@ -67,6 +122,37 @@ type Client struct {
 	UserID         pgtype.Int4        `json:"user_id"`
 }
 
+type ContactAttribute struct {
+	ID        pgtype.UUID        `json:"id"`
+	DeviceID  string             `json:"device_id"`
+	Key       string             `json:"key"`
+	Label     string             `json:"label"`
+	Type      string             `json:"type"`
+	Options   []string           `json:"options"`
+	Required  bool               `json:"required"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ContactDetail struct {
+	ID         pgtype.UUID        `json:"id"`
+	DeviceID   string             `json:"device_id"`
+	Jid        string             `json:"jid"`
+	Email      pgtype.Text        `json:"email"`
+	ExternalID pgtype.Text        `json:"external_id"`
+	Notes      pgtype.Text        `json:"notes"`
+	Tags       []string           `json:"tags"`
+	Attributes []byte             `json:"attributes"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DeviceMember struct {
+	DeviceID  string             `json:"device_id"`
+	UserID    int32              `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type DeviceSubscription struct {
 	ID        pgtype.UUID        `json:"id"`
 	DeviceID  string             `json:"device_id"`
@@ -76,25 +162,199 @@ type DeviceSubscription struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type Flow struct {
+	ID              pgtype.UUID        `json:"id"`
+	DeviceID        string             `json:"device_id"`
+	Name            string             `json:"name"`
+	TriggerType     string             `json:"trigger_type"`
+	TriggerKeywords []string           `json:"trigger_keywords"`
+	IsActive        bool               `json:"is_active"`
+	CurrentVersion  int32              `json:"current_version"`
+	WebhookUrl      pgtype.Text        `json:"webhook_url"`
+	TimeoutSeconds  int32              `json:"timeout_seconds"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	WebhookSecret   pgtype.Text        `json:"webhook_secret"`
+}
+
+type FlowSession struct {
+	ID             pgtype.UUID        `json:"id"`
+	FlowID         pgtype.UUID        `json:"flow_id"`
+	FlowVersion    int32              `json:"flow_version"`
+	DeviceID       string             `json:"device_id"`
+	ChatJid        string             `json:"chat_jid"`
+	CurrentStep    string             `json:"current_step"`
+	Answers        []byte             `json:"answers"`
+	Status         string             `json:"status"`
+	LastActivityAt pgtype.Timestamptz `json:"last_activity_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type FlowVersion struct {
+	ID         pgtype.UUID        `json:"id"`
+	FlowID     pgtype.UUID        `json:"flow_id"`
+	Version    int32              `json:"version"`
+	Definition []byte             `json:"definition"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type GroupEvent struct {
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       string             `json:"device_id"`
+	GroupJid       string             `json:"group_jid"`
+	Action         string             `json:"action"`
+	ParticipantJid pgtype.Text        `json:"participant_jid"`
+	PhoneJid       pgtype.Text        `json:"phone_jid"`
+	ActorJid       pgtype.Text        `json:"actor_jid"`
+	Value          pgtype.Text        `json:"value"`
+	OccurredAt     pgtype.Timestamptz `json:"occurred_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type GroupParticipant struct {
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       string             `json:"device_id"`
+	GroupJid       string             `json:"group_jid"`
+	ParticipantJid string             `json:"participant_jid"`
+	PhoneJid       pgtype.Text        `json:"phone_jid"`
+	Role           string             `json:"role"`
+	JoinedAt       pgtype.Timestamptz `json:"joined_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type HistorySyncProgress struct {
+	DeviceID      string             `json:"device_id"`
+	SyncType      string             `json:"sync_type"`
+	ChunkOrder    int32              `json:"chunk_order"`
+	Progress      int32              `json:"progress"`
+	Conversations int32              `json:"conversations"`
+	Messages      int32              `json:"messages"`
+	StartedAt     pgtype.Timestamptz `json:"started_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+}
+
+type HistorySyncSetting struct {
+	DeviceID           string             `json:"device_id"`
+	IsEnabled          bool               `json:"is_enabled"`
+	MaxDays            int32              `json:"max_days"`
+	MaxMessagesPerChat int32              `json:"max_messages_per_chat"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Label struct {
+	ID        pgtype.UUID        `json:"id"`
+	DeviceID  string             `json:"device_id"`
+	WaLabelID string             `json:"wa_label_id"`
+	Name      string             `json:"name"`
+	Color     int32              `json:"color"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MediaCleanupRun struct {
+	ID              pgtype.UUID        `json:"id"`
+	DeviceID        string             `json:"device_id"`
+	Reason          string             `json:"reason"`
+	MessagesCleared int32              `json:"messages_cleared"`
+	ObjectsDeleted  int32              `json:"objects_deleted"`
+	BytesFreed      int64              `json:"bytes_freed"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type MediaDownloadSetting struct {
+	DeviceID          string             `json:"device_id"`
+	AutoDownloadTypes []string           `json:"auto_download_types"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MediaObject struct {
+	ID         pgtype.UUID        `json:"id"`
+	DeviceID   string             `json:"device_id"`
+	StorageKey string             `json:"storage_key"`
+	Sha256     []byte             `json:"sha256"`
+	SizeBytes  int64              `json:"size_bytes"`
+	Mimetype   pgtype.Text        `json:"mimetype"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type MediaRetentionSetting struct {
+	DeviceID              string             `json:"device_id"`
+	ImageRetentionDays    pgtype.Int4        `json:"image_retention_days"`
+	VideoRetentionDays    pgtype.Int4        `json:"video_retention_days"`
+	DocumentRetentionDays pgtype.Int4        `json:"document_retention_days"`
+	AudioRetentionDays    pgtype.Int4        `json:"audio_retention_days"`
+	QuotaBytes            pgtype.Int8        `json:"quota_bytes"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MessageChange struct {
+	MessageID  pgtype.UUID        `json:"message_id"`
+	DeviceID   string             `json:"device_id"`
+	ChatJid    string             `json:"chat_jid"`
+	CreatedSeq int64              `json:"created_seq"`
+	Seq        int64              `json:"seq"`
+	EditedAt   pgtype.Timestamptz `json:"edited_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+	ChangedAt  pgtype.Timestamptz `json:"changed_at"`
+	Xid        int64              `json:"xid"`
+	CreatedXid int64              `json:"created_xid"`
+}
+
 type MessageLog struct {
-	ID            pgtype.UUID        `json:"id"`
-	DeviceID      pgtype.Text        `json:"device_id"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	Recipient     string             `json:"recipient"`
-	RecipientType pgtype.Text        `json:"recipient_type"`
-	MessageType   pgtype.Text        `json:"message_type"`
-	Content       string             `json:"content"`
-	MediaUrl      pgtype.Text        `json:"media_url"`
-	MediaFilename pgtype.Text        `json:"media_filename"`
-	Buttons       []byte             `json:"buttons"`
-	TemplateID    pgtype.UUID        `json:"template_id"`
-	Status        pgtype.Text        `json:"status"`
-	SentAt        pgtype.Timestamptz `json:"sent_at"`
-	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
-	ReadAt        pgtype.Timestamptz `json:"read_at"`
-	Direction     string             `json:"direction"`
-	WaMessageID   pgtype.Text        `json:"wa_message_id"`
-	SenderJid     pgtype.Text        `json:"sender_jid"`
+	ID             pgtype.UUID        `json:"id"`
+	DeviceID       pgtype.Text        `json:"device_id"`
+	UserID         pgtype.Int4        `json:"user_id"`
+	Recipient      string             `json:"recipient"`
+	RecipientType  pgtype.Text        `json:"recipient_type"`
+	MessageType    pgtype.Text        `json:"message_type"`
+	Content        string             `json:"content"`
+	MediaUrl       pgtype.Text        `json:"media_url"`
+	MediaFilename  pgtype.Text        `json:"media_filename"`
+	Buttons        []byte             `json:"buttons"`
+	TemplateID     pgtype.UUID        `json:"template_id"`
+	Status         pgtype.Text        `json:"status"`
+	SentAt         pgtype.Timestamptz `json:"sent_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	ReadAt         pgtype.Timestamptz `json:"read_at"`
+	Direction      string             `json:"direction"`
+	WaMessageID    pgtype.Text        `json:"wa_message_id"`
+	SenderJid      pgtype.Text        `json:"sender_jid"`
+	MediaDeletedAt pgtype.Timestamptz `json:"media_deleted_at"`
+}
+
+type MessageMedium struct {
+	MessageID     pgtype.UUID        `json:"message_id"`
+	MediaType     string             `json:"media_type"`
+	Mimetype      pgtype.Text        `json:"mimetype"`
+	DirectPath    string             `json:"direct_path"`
+	MediaKey      []byte             `json:"media_key"`
+	FileEncSha256 []byte             `json:"file_enc_sha256"`
+	FileSha256    []byte             `json:"file_sha256"`
+	FileLength    pgtype.Int8        `json:"file_length"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     pgtype.Text        `json:"last_error"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type MessageReaction struct {
+	MessageID pgtype.UUID        `json:"message_id"`
+	SenderJid string             `json:"sender_jid"`
+	Emoji     string             `json:"emoji"`
+	ReactedAt pgtype.Timestamptz `json:"reacted_at"`
+}
+
+type MessageSearch struct {
+	MessageID pgtype.UUID `json:"message_id"`
+	Document  interface{} `json:"document"`
 }
 
 type MessageTemplate struct {
@@ -119,6 +379,115 @@ type MessageThread struct {
 	UnreadCount          int32              `json:"unread_count"`
 	CreatedAt            pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
+	AwaySentAt           pgtype.Timestamptz `json:"away_sent_at"`
+	Status               string             `json:"status"`
+	AssignedUserID       pgtype.Int4        `json:"assigned_user_id"`
+	IsPinned             bool               `json:"is_pinned"`
+	IsArchived           bool               `json:"is_archived"`
+	IsMuted              bool               `json:"is_muted"`
+	MutedUntil           pgtype.Timestamptz `json:"muted_until"`
+}
+
+type OptOut struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    int32              `json:"user_id"`
+	Jid       string             `json:"jid"`
+	DeviceID  pgtype.Text        `json:"device_id"`
+	Source    string             `json:"source"`
+	Keyword   pgtype.Text        `json:"keyword"`
+	Reason    pgtype.Text        `json:"reason"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type OptOutSetting struct {
+	UserID              int32              `json:"user_id"`
+	Keywords            []string           `json:"keywords"`
+	SendConfirmation    bool               `json:"send_confirmation"`
+	ConfirmationMessage string             `json:"confirmation_message"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Profile struct {
+	ID                  pgtype.UUID        `json:"id"`
+	DeviceID            string             `json:"device_id"`
+	Jid                 string             `json:"jid"`
+	PictureID           pgtype.Text        `json:"picture_id"`
+	PictureUrl          pgtype.Text        `json:"picture_url"`
+	PictureUpdatedAt    pgtype.Timestamptz `json:"picture_updated_at"`
+	About               pgtype.Text        `json:"about"`
+	AboutUpdatedAt      pgtype.Timestamptz `json:"about_updated_at"`
+	IsBusiness          bool               `json:"is_business"`
+	BusinessCategory    pgtype.Text        `json:"business_category"`
+	BusinessDescription pgtype.Text        `json:"business_description"`
+	BusinessAddress     pgtype.Text        `json:"business_address"`
+	BusinessEmail       pgtype.Text        `json:"business_email"`
+	BusinessWebsites    []string           `json:"business_websites"`
+	BusinessHours       []byte             `json:"business_hours"`
+	BusinessTimezone    pgtype.Text        `json:"business_timezone"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ReadReceiptSetting struct {
+	DeviceID  string             `json:"device_id"`
+	IsEnabled bool               `json:"is_enabled"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type SearchSetting struct {
+	DeviceID  string             `json:"device_id"`
+	Language  string             `json:"language"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type StatusPost struct {
+	ID              pgtype.UUID        `json:"id"`
+	DeviceID        string             `json:"device_id"`
+	WaMessageID     string             `json:"wa_message_id"`
+	Type            string             `json:"type"`
+	Content         string             `json:"content"`
+	BackgroundColor pgtype.Text        `json:"background_color"`
+	Font            pgtype.Text        `json:"font"`
+	Viewers         []string           `json:"viewers"`
+	PostedAt        pgtype.Timestamptz `json:"posted_at"`
+}
+
+type StatusView struct {
+	ID           pgtype.UUID        `json:"id"`
+	StatusPostID pgtype.UUID        `json:"status_post_id"`
+	ViewerJid    string             `json:"viewer_jid"`
+	PhoneJid     pgtype.Text        `json:"phone_jid"`
+	ViewedAt     pgtype.Timestamptz `json:"viewed_at"`
+}
+
+type ThreadAuditLog struct {
+	ID        pgtype.UUID        `json:"id"`
+	DeviceID  string             `json:"device_id"`
+	ChatJid   string             `json:"chat_jid"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	Action    string             `json:"action"`
+	OldValue  pgtype.Text        `json:"old_value"`
+	NewValue  pgtype.Text        `json:"new_value"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ThreadLabel struct {
+	DeviceID  string             `json:"device_id"`
+	ChatJid   string             `json:"chat_jid"`
+	LabelID   pgtype.UUID        `json:"label_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ThreadNote struct {
+	ID        pgtype.UUID        `json:"id"`
+	DeviceID  string             `json:"device_id"`
+	ChatJid   string             `json:"chat_jid"`
+	UserID    int32              `json:"user_id"`
+	Content   string             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type User struct {
@@ -141,15 +510,22 @@ type WhatsappContact struct {
 	PhoneNumber  pgtype.Text        `json:"phone_number"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	IsOnline     bool               `json:"is_online"`
+	LastSeenAt   pgtype.Timestamptz `json:"last_seen_at"`
 }
 
 type WhatsappGroup struct {
-	ID               pgtype.UUID        `json:"id"`
-	DeviceID         pgtype.Text        `json:"device_id"`
-	GroupID          string             `json:"group_id"`
-	GroupName        string             `json:"group_name"`
-	GroupDescription pgtype.Text        `json:"group_description"`
-	ParticipantCount pgtype.Int4        `json:"participant_count"`
-	CreatedAt        pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ID                  pgtype.UUID        `json:"id"`
+	DeviceID            pgtype.Text        `json:"device_id"`
+	GroupID             string             `json:"group_id"`
+	GroupName           string             `json:"group_name"`
+	GroupDescription    pgtype.Text        `json:"group_description"`
+	ParticipantCount    pgtype.Int4        `json:"participant_count"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	IsAnnounce          bool               `json:"is_announce"`
+	IsLocked            bool               `json:"is_locked"`
+	IsCommunity         bool               `json:"is_community"`
+	ParentGroupID       pgtype.Text        `json:"parent_group_id"`
+	IsAnnouncementGroup bool               `json:"is_announcement_group"`
 }
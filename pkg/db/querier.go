@@ -11,72 +11,223 @@ import (
 )
 
 type Querier interface {
+	AddDeviceMember(ctx context.Context, arg AddDeviceMemberParams) error
+	AddOptOut(ctx context.Context, arg AddOptOutParams) (OptOut, error)
+	AddThreadLabel(ctx context.Context, arg AddThreadLabelParams) error
+	AssignThread(ctx context.Context, arg AssignThreadParams) error
+	ClaimAutoReplyCooldown(ctx context.Context, arg ClaimAutoReplyCooldownParams) (pgtype.UUID, error)
+	ClaimAwayMessage(ctx context.Context, arg ClaimAwayMessageParams) (pgtype.UUID, error)
+	ClaimMediaDownloads(ctx context.Context, limit int32) ([]pgtype.UUID, error)
+	ClearProfilePictureByURL(ctx context.Context, arg ClearProfilePictureByURLParams) error
+	CloseFlowSession(ctx context.Context, arg CloseFlowSessionParams) (int64, error)
+	CloseStaleHandoffSessions(ctx context.Context, maxAgeSeconds int32) (int64, error)
+	CompleteMediaDownload(ctx context.Context, arg CompleteMediaDownloadParams) error
+	CountChatMessages(ctx context.Context, arg CountChatMessagesParams) (int64, error)
 	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAutoReplyRule(ctx context.Context, arg CreateAutoReplyRuleParams) (AutoReplyRule, error)
 	CreateBroadcastJob(ctx context.Context, arg CreateBroadcastJobParams) (BroadcastJob, error)
 	CreateBroadcastRecipient(ctx context.Context, arg CreateBroadcastRecipientParams) error
+	CreateContactAttribute(ctx context.Context, arg CreateContactAttributeParams) (ContactAttribute, error)
 	// filename: subscriptions.sql
 	CreateDeviceSubscription(ctx context.Context, arg CreateDeviceSubscriptionParams) (DeviceSubscription, error)
+	CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, error)
+	CreateFlowSession(ctx context.Context, arg CreateFlowSessionParams) (FlowSession, error)
+	CreateFlowVersion(ctx context.Context, arg CreateFlowVersionParams) (FlowVersion, error)
+	CreateGroupEvent(ctx context.Context, arg CreateGroupEventParams) error
+	CreateLabel(ctx context.Context, arg CreateLabelParams) (Label, error)
+	CreateMediaCleanupRun(ctx context.Context, arg CreateMediaCleanupRunParams) error
+	CreateMediaObject(ctx context.Context, arg CreateMediaObjectParams) error
+	CreateMessageMedia(ctx context.Context, arg CreateMessageMediaParams) error
 	// filename: queries/clients/create_new_client.sql
 	CreateNewClient(ctx context.Context, arg CreateNewClientParams) (Client, error)
 	CreateNewMessageTemplate(ctx context.Context, arg CreateNewMessageTemplateParams) (MessageTemplate, error)
+	CreateStatusPost(ctx context.Context, arg CreateStatusPostParams) (StatusPost, error)
+	CreateThreadAuditLog(ctx context.Context, arg CreateThreadAuditLogParams) error
+	CreateThreadNote(ctx context.Context, arg CreateThreadNoteParams) (ThreadNote, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAPIKey(ctx context.Context, id pgtype.UUID) error
 	DeleteAllDeviceSubscriptions(ctx context.Context, deviceID string) error
+	DeleteAutoReplyRule(ctx context.Context, arg DeleteAutoReplyRuleParams) error
+	DeleteBotWebhook(ctx context.Context, deviceID string) error
 	DeleteClient(ctx context.Context, id string) error
+	DeleteContactAttribute(ctx context.Context, arg DeleteContactAttributeParams) (int64, error)
+	DeleteContactDetails(ctx context.Context, arg DeleteContactDetailsParams) (int64, error)
 	DeleteDeviceSubscription(ctx context.Context, arg DeleteDeviceSubscriptionParams) (DeviceSubscription, error)
+	DeleteFlow(ctx context.Context, arg DeleteFlowParams) error
+	DeleteGroupParticipant(ctx context.Context, arg DeleteGroupParticipantParams) error
+	DeleteGroupParticipants(ctx context.Context, arg DeleteGroupParticipantsParams) error
+	DeleteLabel(ctx context.Context, arg DeleteLabelParams) error
+	DeleteLabelByWaID(ctx context.Context, arg DeleteLabelByWaIDParams) error
+	DeleteMessageByWaID(ctx context.Context, arg DeleteMessageByWaIDParams) (int64, error)
+	DeleteMessageReaction(ctx context.Context, arg DeleteMessageReactionParams) error
 	DeleteMessageTemplate(ctx context.Context, arg DeleteMessageTemplateParams) error
+	DeleteStaleContacts(ctx context.Context, arg DeleteStaleContactsParams) (int64, error)
+	DeleteStaleGroupParticipants(ctx context.Context, arg DeleteStaleGroupParticipantsParams) error
+	DeleteThreadNote(ctx context.Context, arg DeleteThreadNoteParams) (int64, error)
+	DeleteUnusedMediaObject(ctx context.Context, arg DeleteUnusedMediaObjectParams) (int64, error)
 	DeleteUser(ctx context.Context, id int32) error
+	DeleteWhatsAppGroup(ctx context.Context, arg DeleteWhatsAppGroupParams) error
+	EditMessageContent(ctx context.Context, arg EditMessageContentParams) (int64, error)
+	EnsureContact(ctx context.Context, arg EnsureContactParams) error
+	ExpireFlowSession(ctx context.Context, id pgtype.UUID) (int64, error)
+	ExpireMediaBefore(ctx context.Context, arg ExpireMediaBeforeParams) ([]string, error)
+	ExpireMediaObject(ctx context.Context, arg ExpireMediaObjectParams) (int64, error)
+	FailMediaDownload(ctx context.Context, arg FailMediaDownloadParams) error
 	GetAPIKeyByID(ctx context.Context, id pgtype.UUID) (ApiKey, error)
 	GetAPIKeyByPrefix(ctx context.Context, keyPrefix string) (ApiKey, error)
 	GetAPIKeyUsageLogs(ctx context.Context, arg GetAPIKeyUsageLogsParams) ([]ApiKeyLog, error)
 	GetAPIKeysByUserID(ctx context.Context, userID int32) ([]ApiKey, error)
+	GetActiveAutoReplyRules(ctx context.Context, deviceID string) ([]AutoReplyRule, error)
+	GetActiveFlows(ctx context.Context, deviceID string) ([]Flow, error)
 	GetAllDeviceSubscriptions(ctx context.Context) ([]DeviceSubscription, error)
+	GetAutoReplyRule(ctx context.Context, arg GetAutoReplyRuleParams) (AutoReplyRule, error)
+	GetAutoReplyRules(ctx context.Context, deviceID string) ([]AutoReplyRule, error)
+	GetAwayMessageSettings(ctx context.Context, deviceID string) (AwayMessageSetting, error)
+	GetBotWebhook(ctx context.Context, deviceID string) (BotWebhook, error)
 	GetBroadcastJob(ctx context.Context, arg GetBroadcastJobParams) (BroadcastJob, error)
 	GetBroadcastJobs(ctx context.Context, userID pgtype.Int4) ([]BroadcastJob, error)
 	GetBroadcastRecipients(ctx context.Context, jobID pgtype.UUID) ([]BroadcastRecipient, error)
 	GetClient(ctx context.Context, id string) (Client, error)
 	GetClientByIDAndUserID(ctx context.Context, arg GetClientByIDAndUserIDParams) (Client, error)
 	GetClientByJID(ctx context.Context, jid pgtype.Text) (Client, error)
+	GetClientForMember(ctx context.Context, arg GetClientForMemberParams) (Client, error)
 	GetClients(ctx context.Context) ([]Client, error)
 	GetClientsByUserID(ctx context.Context, userID pgtype.Int4) ([]Client, error)
+	GetContactWithDetails(ctx context.Context, arg GetContactWithDetailsParams) (GetContactWithDetailsRow, error)
 	GetConversationMessages(ctx context.Context, arg GetConversationMessagesParams) ([]GetConversationMessagesRow, error)
 	GetConversations(ctx context.Context, arg GetConversationsParams) ([]GetConversationsRow, error)
-	GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) ([]WhatsappContact, error)
+	GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) ([]GetDeviceContactsRow, error)
 	GetDeviceGroups(ctx context.Context, deviceID pgtype.Text) ([]WhatsappGroup, error)
+	GetDeviceMediaUsage(ctx context.Context, deviceID string) ([]GetDeviceMediaUsageRow, error)
+	GetDeviceMembers(ctx context.Context, deviceID string) ([]GetDeviceMembersRow, error)
 	GetDeviceSubscription(ctx context.Context, arg GetDeviceSubscriptionParams) (DeviceSubscription, error)
 	GetDeviceSubscriptions(ctx context.Context, deviceID string) ([]DeviceSubscription, error)
+	GetExpiredFlowSessions(ctx context.Context) ([]FlowSession, error)
+	GetFlow(ctx context.Context, arg GetFlowParams) (Flow, error)
+	GetFlowByID(ctx context.Context, iD pgtype.UUID) (Flow, error)
+	GetFlowVersion(ctx context.Context, arg GetFlowVersionParams) (FlowVersion, error)
+	GetFlowVersions(ctx context.Context, flowID pgtype.UUID) ([]FlowVersion, error)
+	GetFlows(ctx context.Context, deviceID string) ([]Flow, error)
+	GetHistorySyncProgress(ctx context.Context, deviceID string) (HistorySyncProgress, error)
+	GetHistorySyncSettings(ctx context.Context, deviceID string) (HistorySyncSetting, error)
+	GetLabel(ctx context.Context, arg GetLabelParams) (Label, error)
+	GetLabelByWaID(ctx context.Context, arg GetLabelByWaIDParams) (Label, error)
+	GetLabels(ctx context.Context, deviceID string) ([]Label, error)
+	GetLatestMessageChangePosition(ctx context.Context, deviceID string) (GetLatestMessageChangePositionRow, error)
+	GetMediaDownloadSettings(ctx context.Context, deviceID string) (MediaDownloadSetting, error)
+	GetMediaObjectDevices(ctx context.Context, storageKey string) ([]string, error)
+	GetMediaRetentionSettings(ctx context.Context, deviceID string) (MediaRetentionSetting, error)
+	GetMessageChanges(ctx context.Context, arg GetMessageChangesParams) ([]GetMessageChangesRow, error)
 	GetMessageHistory(ctx context.Context, arg GetMessageHistoryParams) ([]MessageLog, error)
+	GetMessageMedia(ctx context.Context, messageID pgtype.UUID) (GetMessageMediaRow, error)
+	GetMessageMediaByWaID(ctx context.Context, arg GetMessageMediaByWaIDParams) (GetMessageMediaByWaIDRow, error)
+	GetMessageReactions(ctx context.Context, messageIds []pgtype.UUID) ([]MessageReaction, error)
 	GetMessageTemplateByID(ctx context.Context, id pgtype.UUID) (MessageTemplate, error)
+	GetOpenFlowSession(ctx context.Context, arg GetOpenFlowSessionParams) (FlowSession, error)
+	GetOptOutSettings(ctx context.Context, userID int32) (OptOutSetting, error)
+	GetOptOuts(ctx context.Context, userID int32) ([]OptOut, error)
 	GetPendingBroadcastJobs(ctx context.Context) ([]BroadcastJob, error)
 	GetPendingRecipients(ctx context.Context, jobID pgtype.UUID) ([]BroadcastRecipient, error)
+	GetProfile(ctx context.Context, arg GetProfileParams) (Profile, error)
+	GetReadReceiptSettings(ctx context.Context, deviceID string) (ReadReceiptSetting, error)
+	GetSearchSettings(ctx context.Context, deviceID string) (SearchSetting, error)
+	GetStatusPost(ctx context.Context, arg GetStatusPostParams) (StatusPost, error)
+	GetThread(ctx context.Context, arg GetThreadParams) (MessageThread, error)
+	GetThreadAuditLogs(ctx context.Context, arg GetThreadAuditLogsParams) ([]GetThreadAuditLogsRow, error)
+	GetThreadLabels(ctx context.Context, arg GetThreadLabelsParams) ([]Label, error)
 	GetThreadMessages(ctx context.Context, arg GetThreadMessagesParams) ([]GetThreadMessagesRow, error)
+	GetThreadMessagesAfter(ctx context.Context, arg GetThreadMessagesAfterParams) ([]GetThreadMessagesAfterRow, error)
+	GetThreadMessagesBefore(ctx context.Context, arg GetThreadMessagesBeforeParams) ([]GetThreadMessagesBeforeRow, error)
+	GetThreadNotes(ctx context.Context, arg GetThreadNotesParams) ([]GetThreadNotesRow, error)
 	GetThreads(ctx context.Context, arg GetThreadsParams) ([]GetThreadsRow, error)
+	GetUnreadIncomingMessages(ctx context.Context, arg GetUnreadIncomingMessagesParams) ([]GetUnreadIncomingMessagesRow, error)
 	GetUserByAPIKey(ctx context.Context, apiKey pgtype.Text) (User, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, email string) (User, error)
+	GetUserMediaUsage(ctx context.Context, userID pgtype.Int4) (int64, error)
 	GetUserTemplates(ctx context.Context, userID pgtype.Int4) ([]MessageTemplate, error)
 	GetUsers(ctx context.Context) ([]User, error)
+	ImportHistoryMessage(ctx context.Context, arg ImportHistoryMessageParams) (pgtype.UUID, error)
+	IsOptedOut(ctx context.Context, arg IsOptedOutParams) (bool, error)
+	IsTextSearchConfig(ctx context.Context, cfgname string) (bool, error)
+	ListContactAttributes(ctx context.Context, deviceID string) ([]ContactAttribute, error)
+	ListContactTags(ctx context.Context, deviceID string) ([]ListContactTagsRow, error)
+	ListDeviceMediaUsage(ctx context.Context) ([]ListDeviceMediaUsageRow, error)
+	ListGroupEvents(ctx context.Context, arg ListGroupEventsParams) ([]ListGroupEventsRow, error)
+	ListGroupParticipants(ctx context.Context, arg ListGroupParticipantsParams) ([]ListGroupParticipantsRow, error)
+	ListMediaCleanupRuns(ctx context.Context, arg ListMediaCleanupRunsParams) ([]MediaCleanupRun, error)
+	ListMediaRetentionSettings(ctx context.Context) ([]MediaRetentionSetting, error)
+	ListOldestMediaObjects(ctx context.Context, arg ListOldestMediaObjectsParams) ([]ListOldestMediaObjectsRow, error)
+	ListProfilePictureIDs(ctx context.Context, deviceID string) ([]ListProfilePictureIDsRow, error)
+	ListStatusPosts(ctx context.Context, arg ListStatusPostsParams) ([]ListStatusPostsRow, error)
+	ListStatusViews(ctx context.Context, statusPostID pgtype.UUID) ([]ListStatusViewsRow, error)
 	LogAPIKeyUsage(ctx context.Context, arg LogAPIKeyUsageParams) error
 	LogIncomingMessage(ctx context.Context, arg LogIncomingMessageParams) (MessageLog, error)
 	LogOutgoingMessage(ctx context.Context, arg LogOutgoingMessageParams) (MessageLog, error)
 	MarkConversationRead(ctx context.Context, arg MarkConversationReadParams) error
+	MarkMediaRetryRequested(ctx context.Context, arg MarkMediaRetryRequestedParams) error
+	RecordStatusView(ctx context.Context, arg RecordStatusViewParams) error
+	RefreshWhatsAppGroupParticipantCount(ctx context.Context, arg RefreshWhatsAppGroupParticipantCountParams) error
+	ReindexMessageSearch(ctx context.Context, deviceID pgtype.Text) (int64, error)
+	RemoveContactAttributeValues(ctx context.Context, arg RemoveContactAttributeValuesParams) error
+	RemoveDeviceMember(ctx context.Context, arg RemoveDeviceMemberParams) (int64, error)
+	RemoveOptOut(ctx context.Context, arg RemoveOptOutParams) error
+	RemoveThreadLabel(ctx context.Context, arg RemoveThreadLabelParams) error
+	ReopenThread(ctx context.Context, arg ReopenThreadParams) (string, error)
 	ResetThreadUnread(ctx context.Context, arg ResetThreadUnreadParams) error
 	RevokeUserAPIKey(ctx context.Context, id int32) error
+	SearchContacts(ctx context.Context, arg SearchContactsParams) ([]SearchContactsRow, error)
+	SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error)
+	SearchThreads(ctx context.Context, arg SearchThreadsParams) ([]SearchThreadsRow, error)
 	SendMessageData(ctx context.Context, arg SendMessageDataParams) (MessageLog, error)
+	SetBusinessProfile(ctx context.Context, arg SetBusinessProfileParams) error
 	SetClientJID(ctx context.Context, arg SetClientJIDParams) (Client, error)
 	SetConnectionStatus(ctx context.Context, arg SetConnectionStatusParams) (Client, error)
+	SetContactPresence(ctx context.Context, arg SetContactPresenceParams) error
+	SetFlowCurrentVersion(ctx context.Context, arg SetFlowCurrentVersionParams) error
+	SetGroupParticipantRole(ctx context.Context, arg SetGroupParticipantRoleParams) error
+	SetProfileAbout(ctx context.Context, arg SetProfileAboutParams) error
+	SetProfilePicture(ctx context.Context, arg SetProfilePictureParams) error
+	SetThreadArchived(ctx context.Context, arg SetThreadArchivedParams) error
+	SetThreadMuted(ctx context.Context, arg SetThreadMutedParams) error
+	SetThreadPinned(ctx context.Context, arg SetThreadPinnedParams) error
+	SetThreadStatus(ctx context.Context, arg SetThreadStatusParams) error
 	SetUserAPIKey(ctx context.Context, arg SetUserAPIKeyParams) (User, error)
 	SetUserAPIPrefix(ctx context.Context, arg SetUserAPIPrefixParams) error
+	SetWhatsAppGroupDescription(ctx context.Context, arg SetWhatsAppGroupDescriptionParams) error
+	SetWhatsAppGroupName(ctx context.Context, arg SetWhatsAppGroupNameParams) error
+	SetWhatsAppGroupParent(ctx context.Context, arg SetWhatsAppGroupParentParams) error
 	UpdateAPIKeyLastUsed(ctx context.Context, id pgtype.UUID) error
+	UpdateAutoReplyRule(ctx context.Context, arg UpdateAutoReplyRuleParams) (AutoReplyRule, error)
 	UpdateBroadcastJobStatus(ctx context.Context, arg UpdateBroadcastJobStatusParams) error
 	UpdateBroadcastRecipientStatus(ctx context.Context, arg UpdateBroadcastRecipientStatusParams) error
+	UpdateContactAttribute(ctx context.Context, arg UpdateContactAttributeParams) (ContactAttribute, error)
 	UpdateDeviceSubscription(ctx context.Context, arg UpdateDeviceSubscriptionParams) (DeviceSubscription, error)
+	UpdateFlow(ctx context.Context, arg UpdateFlowParams) (Flow, error)
+	UpdateFlowSession(ctx context.Context, arg UpdateFlowSessionParams) error
+	UpdateHistorySyncProgress(ctx context.Context, arg UpdateHistorySyncProgressParams) (HistorySyncProgress, error)
+	UpdateLabel(ctx context.Context, arg UpdateLabelParams) (Label, error)
+	UpdateMediaDirectPath(ctx context.Context, arg UpdateMediaDirectPathParams) error
 	UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) error
 	UpdateMessageTemplate(ctx context.Context, arg UpdateMessageTemplateParams) (MessageTemplate, error)
 	UpdateQRCode(ctx context.Context, arg UpdateQRCodeParams) (Client, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertAwayMessageSettings(ctx context.Context, arg UpsertAwayMessageSettingsParams) (AwayMessageSetting, error)
+	UpsertBotWebhook(ctx context.Context, arg UpsertBotWebhookParams) (BotWebhook, error)
+	UpsertContactDetails(ctx context.Context, arg UpsertContactDetailsParams) (ContactDetail, error)
+	UpsertContactPushName(ctx context.Context, arg UpsertContactPushNameParams) error
 	UpsertDeviceSubscriptions(ctx context.Context, arg UpsertDeviceSubscriptionsParams) error
+	UpsertGroupParticipant(ctx context.Context, arg UpsertGroupParticipantParams) error
+	UpsertHistorySyncSettings(ctx context.Context, arg UpsertHistorySyncSettingsParams) (HistorySyncSetting, error)
+	UpsertHistoryThread(ctx context.Context, arg UpsertHistoryThreadParams) error
+	UpsertLabelByWaID(ctx context.Context, arg UpsertLabelByWaIDParams) (Label, error)
+	UpsertMediaDownloadSettings(ctx context.Context, arg UpsertMediaDownloadSettingsParams) (MediaDownloadSetting, error)
+	UpsertMediaRetentionSettings(ctx context.Context, arg UpsertMediaRetentionSettingsParams) (MediaRetentionSetting, error)
+	UpsertMessageReaction(ctx context.Context, arg UpsertMessageReactionParams) (int64, error)
+	UpsertOptOutSettings(ctx context.Context, arg UpsertOptOutSettingsParams) (OptOutSetting, error)
+	UpsertReadReceiptSettings(ctx context.Context, arg UpsertReadReceiptSettingsParams) (ReadReceiptSetting, error)
+	UpsertSearchSettings(ctx context.Context, arg UpsertSearchSettingsParams) (SearchSetting, error)
 	UpsertThread(ctx context.Context, arg UpsertThreadParams) error
 	UpsertWhatsAppContact(ctx context.Context, arg UpsertWhatsAppContactParams) error
 	UpsertWhatsAppGroup(ctx context.Context, arg UpsertWhatsAppGroupParams) error
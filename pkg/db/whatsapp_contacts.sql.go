@@ -11,22 +11,68 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const deleteStaleContacts = `-- name: DeleteStaleContacts :execrows
+DELETE FROM whatsapp_contacts wc
+WHERE wc.device_id = $1
+  AND NOT (wc.jid = ANY ($2::text[]))
+  AND NOT EXISTS (SELECT 1
+                  FROM contact_details cd
+                  WHERE cd.device_id = wc.device_id
+                    AND cd.jid = wc.jid)
+`
+
+type DeleteStaleContactsParams struct {
+	DeviceID pgtype.Text `json:"device_id"`
+	Jids     []string    `json:"jids"`
+}
+
+func (q *Queries) DeleteStaleContacts(ctx context.Context, arg DeleteStaleContactsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleContacts, arg.DeviceID, arg.Jids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getDeviceContacts = `-- name: GetDeviceContacts :many
-SELECT id, device_id, jid, full_name, push_name, business_name, phone_number, created_at, updated_at
-FROM whatsapp_contacts
-WHERE device_id = $1
-ORDER BY COALESCE(full_name, push_name, jid) ASC
+SELECT wc.id, wc.device_id, wc.jid, wc.full_name, wc.push_name, wc.business_name, wc.phone_number, wc.created_at, wc.updated_at, wc.is_online, wc.last_seen_at,
+       p.picture_url,
+       p.about,
+       COALESCE(p.is_business, FALSE) AS is_business,
+       p.business_category
+FROM whatsapp_contacts wc
+LEFT JOIN profiles p ON p.device_id = wc.device_id AND p.jid = wc.jid
+WHERE wc.device_id = $1
+ORDER BY COALESCE(wc.full_name, wc.push_name, wc.jid) ASC
 `
 
-func (q *Queries) GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) ([]WhatsappContact, error) {
+type GetDeviceContactsRow struct {
+	ID               pgtype.UUID        `json:"id"`
+	DeviceID         pgtype.Text        `json:"device_id"`
+	Jid              string             `json:"jid"`
+	FullName         pgtype.Text        `json:"full_name"`
+	PushName         pgtype.Text        `json:"push_name"`
+	BusinessName     pgtype.Text        `json:"business_name"`
+	PhoneNumber      pgtype.Text        `json:"phone_number"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	IsOnline         bool               `json:"is_online"`
+	LastSeenAt       pgtype.Timestamptz `json:"last_seen_at"`
+	PictureUrl       pgtype.Text        `json:"picture_url"`
+	About            pgtype.Text        `json:"about"`
+	IsBusiness       bool               `json:"is_business"`
+	BusinessCategory pgtype.Text        `json:"business_category"`
+}
+
+func (q *Queries) GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) ([]GetDeviceContactsRow, error) {
 	rows, err := q.db.Query(ctx, getDeviceContacts, deviceID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []WhatsappContact
+	var items []GetDeviceContactsRow
 	for rows.Next() {
-		var i WhatsappContact
+		var i GetDeviceContactsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.DeviceID,
@@ -37,6 +83,12 @@ func (q *Queries) GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) (
 			&i.PhoneNumber,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsOnline,
+			&i.LastSeenAt,
+			&i.PictureUrl,
+			&i.About,
+			&i.IsBusiness,
+			&i.BusinessCategory,
 		); err != nil {
 			return nil, err
 		}
@@ -48,6 +100,57 @@ func (q *Queries) GetDeviceContacts(ctx context.Context, deviceID pgtype.Text) (
 	return items, nil
 }
 
+const setContactPresence = `-- name: SetContactPresence :exec
+INSERT INTO whatsapp_contacts (device_id, jid, is_online, last_seen_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (device_id, jid)
+    DO UPDATE SET is_online    = EXCLUDED.is_online,
+                  last_seen_at = COALESCE(EXCLUDED.last_seen_at, whatsapp_contacts.last_seen_at)
+`
+
+type SetContactPresenceParams struct {
+	DeviceID   pgtype.Text        `json:"device_id"`
+	Jid        string             `json:"jid"`
+	IsOnline   bool               `json:"is_online"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+}
+
+func (q *Queries) SetContactPresence(ctx context.Context, arg SetContactPresenceParams) error {
+	_, err := q.db.Exec(ctx, setContactPresence,
+		arg.DeviceID,
+		arg.Jid,
+		arg.IsOnline,
+		arg.LastSeenAt,
+	)
+	return err
+}
+
+const upsertContactPushName = `-- name: UpsertContactPushName :exec
+INSERT INTO whatsapp_contacts (device_id, jid, push_name, phone_number, updated_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (device_id, jid)
+    DO UPDATE SET push_name  = EXCLUDED.push_name,
+                  updated_at = EXCLUDED.updated_at
+    WHERE whatsapp_contacts.push_name IS DISTINCT FROM EXCLUDED.push_name
+`
+
+type UpsertContactPushNameParams struct {
+	DeviceID    pgtype.Text `json:"device_id"`
+	Jid         string      `json:"jid"`
+	PushName    pgtype.Text `json:"push_name"`
+	PhoneNumber pgtype.Text `json:"phone_number"`
+}
+
+func (q *Queries) UpsertContactPushName(ctx context.Context, arg UpsertContactPushNameParams) error {
+	_, err := q.db.Exec(ctx, upsertContactPushName,
+		arg.DeviceID,
+		arg.Jid,
+		arg.PushName,
+		arg.PhoneNumber,
+	)
+	return err
+}
+
 const upsertWhatsAppContact = `-- name: UpsertWhatsAppContact :exec
 INSERT INTO whatsapp_contacts (device_id,
                                jid,
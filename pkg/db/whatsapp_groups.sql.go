@@ -11,8 +11,23 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const deleteWhatsAppGroup = `-- name: DeleteWhatsAppGroup :exec
+DELETE FROM whatsapp_groups
+WHERE device_id = $1 AND group_id = $2
+`
+
+type DeleteWhatsAppGroupParams struct {
+	DeviceID pgtype.Text `json:"device_id"`
+	GroupID  string      `json:"group_id"`
+}
+
+func (q *Queries) DeleteWhatsAppGroup(ctx context.Context, arg DeleteWhatsAppGroupParams) error {
+	_, err := q.db.Exec(ctx, deleteWhatsAppGroup, arg.DeviceID, arg.GroupID)
+	return err
+}
+
 const getDeviceGroups = `-- name: GetDeviceGroups :many
-SELECT id, device_id, group_id, group_name, group_description, participant_count, created_at, updated_at
+SELECT id, device_id, group_id, group_name, group_description, participant_count, created_at, updated_at, is_announce, is_locked, is_community, parent_group_id, is_announcement_group
 FROM whatsapp_groups
 WHERE device_id = $1
 ORDER BY group_name ASC
@@ -36,6 +51,11 @@ func (q *Queries) GetDeviceGroups(ctx context.Context, deviceID pgtype.Text) ([]
 			&i.ParticipantCount,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsAnnounce,
+			&i.IsLocked,
+			&i.IsCommunity,
+			&i.ParentGroupID,
+			&i.IsAnnouncementGroup,
 		); err != nil {
 			return nil, err
 		}
@@ -47,28 +67,126 @@ func (q *Queries) GetDeviceGroups(ctx context.Context, deviceID pgtype.Text) ([]
 	return items, nil
 }
 
+const refreshWhatsAppGroupParticipantCount = `-- name: RefreshWhatsAppGroupParticipantCount :exec
+UPDATE whatsapp_groups
+SET participant_count = (
+        SELECT COUNT(*) FROM group_participants gp
+        WHERE gp.device_id = whatsapp_groups.device_id AND gp.group_jid = whatsapp_groups.group_id
+    ),
+    updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2
+`
+
+type RefreshWhatsAppGroupParticipantCountParams struct {
+	DeviceID pgtype.Text `json:"device_id"`
+	GroupID  string      `json:"group_id"`
+}
+
+func (q *Queries) RefreshWhatsAppGroupParticipantCount(ctx context.Context, arg RefreshWhatsAppGroupParticipantCountParams) error {
+	_, err := q.db.Exec(ctx, refreshWhatsAppGroupParticipantCount, arg.DeviceID, arg.GroupID)
+	return err
+}
+
+const setWhatsAppGroupDescription = `-- name: SetWhatsAppGroupDescription :exec
+UPDATE whatsapp_groups
+SET group_description = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2
+`
+
+type SetWhatsAppGroupDescriptionParams struct {
+	DeviceID         pgtype.Text `json:"device_id"`
+	GroupID          string      `json:"group_id"`
+	GroupDescription pgtype.Text `json:"group_description"`
+}
+
+func (q *Queries) SetWhatsAppGroupDescription(ctx context.Context, arg SetWhatsAppGroupDescriptionParams) error {
+	_, err := q.db.Exec(ctx, setWhatsAppGroupDescription,
+		arg.DeviceID,
+		arg.GroupID,
+		arg.GroupDescription,
+	)
+	return err
+}
+
+const setWhatsAppGroupName = `-- name: SetWhatsAppGroupName :exec
+UPDATE whatsapp_groups
+SET group_name = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2
+`
+
+type SetWhatsAppGroupNameParams struct {
+	DeviceID  pgtype.Text `json:"device_id"`
+	GroupID   string      `json:"group_id"`
+	GroupName string      `json:"group_name"`
+}
+
+func (q *Queries) SetWhatsAppGroupName(ctx context.Context, arg SetWhatsAppGroupNameParams) error {
+	_, err := q.db.Exec(ctx, setWhatsAppGroupName,
+		arg.DeviceID,
+		arg.GroupID,
+		arg.GroupName,
+	)
+	return err
+}
+
+const setWhatsAppGroupParent = `-- name: SetWhatsAppGroupParent :exec
+UPDATE whatsapp_groups
+SET parent_group_id = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2
+`
+
+type SetWhatsAppGroupParentParams struct {
+	DeviceID      pgtype.Text `json:"device_id"`
+	GroupID       string      `json:"group_id"`
+	ParentGroupID pgtype.Text `json:"parent_group_id"`
+}
+
+func (q *Queries) SetWhatsAppGroupParent(ctx context.Context, arg SetWhatsAppGroupParentParams) error {
+	_, err := q.db.Exec(ctx, setWhatsAppGroupParent,
+		arg.DeviceID,
+		arg.GroupID,
+		arg.ParentGroupID,
+	)
+	return err
+}
+
 const upsertWhatsAppGroup = `-- name: UpsertWhatsAppGroup :exec
 INSERT INTO whatsapp_groups (device_id,
                              group_id,
                              group_name,
                              group_description,
                              participant_count,
-                             updated_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+                             is_announce,
+                             is_locked,
+                             updated_at,
+                             is_community,
+                             parent_group_id,
+                             is_announcement_group)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (device_id, group_id)
-    DO UPDATE SET group_name        = EXCLUDED.group_name,
-                  group_description = EXCLUDED.group_description,
-                  participant_count = EXCLUDED.participant_count,
-                  updated_at        = EXCLUDED.updated_at
+    DO UPDATE SET group_name            = EXCLUDED.group_name,
+                  group_description     = EXCLUDED.group_description,
+                  participant_count     = EXCLUDED.participant_count,
+                  is_announce           = EXCLUDED.is_announce,
+                  is_locked             = EXCLUDED.is_locked,
+                  updated_at            = EXCLUDED.updated_at,
+                  is_community          = EXCLUDED.is_community,
+                  parent_group_id       = EXCLUDED.parent_group_id,
+                  is_announcement_group = EXCLUDED.is_announcement_group
 `
 
 type UpsertWhatsAppGroupParams struct {
-	DeviceID         pgtype.Text        `json:"device_id"`
-	GroupID          string             `json:"group_id"`
-	GroupName        string             `json:"group_name"`
-	GroupDescription pgtype.Text        `json:"group_description"`
-	ParticipantCount pgtype.Int4        `json:"participant_count"`
-	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	DeviceID            pgtype.Text        `json:"device_id"`
+	GroupID             string             `json:"group_id"`
+	GroupName           string             `json:"group_name"`
+	GroupDescription    pgtype.Text        `json:"group_description"`
+	ParticipantCount    pgtype.Int4        `json:"participant_count"`
+	IsAnnounce          bool               `json:"is_announce"`
+	IsLocked            bool               `json:"is_locked"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	IsCommunity         bool               `json:"is_community"`
+	ParentGroupID       pgtype.Text        `json:"parent_group_id"`
+	IsAnnouncementGroup bool               `json:"is_announcement_group"`
 }
 
 func (q *Queries) UpsertWhatsAppGroup(ctx context.Context, arg UpsertWhatsAppGroupParams) error {
@@ -78,7 +196,12 @@ func (q *Queries) UpsertWhatsAppGroup(ctx context.Context, arg UpsertWhatsAppGro
 		arg.GroupName,
 		arg.GroupDescription,
 		arg.ParticipantCount,
+		arg.IsAnnounce,
+		arg.IsLocked,
 		arg.UpdatedAt,
+		arg.IsCommunity,
+		arg.ParentGroupID,
+		arg.IsAnnouncementGroup,
 	)
 	return err
 }
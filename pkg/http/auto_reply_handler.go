@@ -0,0 +1,303 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// AutoReplyHandler manages the per-device auto-reply rules
+type AutoReplyHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewAutoReplyHandler creates a new AutoReplyHandler
+func NewAutoReplyHandler(db db.Querier, deviceStore *wa.DeviceStore) *AutoReplyHandler {
+	return &AutoReplyHandler{db: db, deviceStore: deviceStore}
+}
+
+type AutoReplyRuleRequest struct {
+	Name            string            `json:"name" validate:"required"`
+	Priority        int32             `json:"priority"`
+	IsActive        *bool             `json:"is_active"`
+	MatchType       string            `json:"match_type" validate:"required"`
+	MatchValue      string            `json:"match_value"`
+	CaseSensitive   bool              `json:"case_sensitive"`
+	MessageTypes    []string          `json:"message_types"`
+	ChatScope       string            `json:"chat_scope"`
+	SenderJIDs      []string          `json:"sender_jids"`
+	GroupJIDs       []string          `json:"group_jids"`
+	HoursCondition  string            `json:"hours_condition"`
+	BusinessHours   *wa.BusinessHours `json:"business_hours"`
+	ReplyType       string            `json:"reply_type" validate:"required"`
+	ReplyText       string            `json:"reply_text"`
+	TemplateID      string            `json:"template_id"`
+	MediaURL        string            `json:"media_url"`
+	MediaFilename   string            `json:"media_filename"`
+	CooldownSeconds int32             `json:"cooldown_seconds"`
+}
+
+// GetAutoReplyRules returns the auto-reply rules of a device
+// @Summary List auto-reply rules
+// @Description Get all auto-reply rules of a device ordered by priority
+// @Tags auto-replies
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auto-replies/{client_id} [get]
+// @Security BearerAuth
+func (h *AutoReplyHandler) GetAutoReplyRules(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	rules, err := h.db.GetAutoReplyRules(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if rules == nil {
+		rules = []db.AutoReplyRule{}
+	}
+
+	return c.JSON(http.StatusOK, rules)
+}
+
+// GetAutoReplyRule returns a single auto-reply rule
+// @Summary Get auto-reply rule
+// @Description Get an auto-reply rule of a device
+// @Tags auto-replies
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auto-replies/{client_id}/{id} [get]
+// @Security BearerAuth
+func (h *AutoReplyHandler) GetAutoReplyRule(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var ruleID pgtype.UUID
+	if err := ruleID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid rule ID"})
+	}
+
+	rule, err := h.db.GetAutoReplyRule(c.Request().Context(), db.GetAutoReplyRuleParams{ID: ruleID, DeviceID: clientID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Auto-reply rule not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// CreateAutoReplyRule creates an auto-reply rule for a device
+// @Summary Create auto-reply rule
+// @Description Create a keyword or rule-based auto-reply for a device
+// @Tags auto-replies
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body AutoReplyRuleRequest true "Rule data"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auto-replies/{client_id} [post]
+// @Security BearerAuth
+func (h *AutoReplyHandler) CreateAutoReplyRule(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	params, err := h.bindRule(c, clientID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	rule, err := h.db.CreateAutoReplyRule(c.Request().Context(), params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateAutoReplyRule replaces an auto-reply rule
+// @Summary Update auto-reply rule
+// @Description Update an auto-reply rule of a device
+// @Tags auto-replies
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Rule ID"
+// @Param request body AutoReplyRuleRequest true "Rule data"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auto-replies/{client_id}/{id} [put]
+// @Security BearerAuth
+func (h *AutoReplyHandler) UpdateAutoReplyRule(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var ruleID pgtype.UUID
+	if err := ruleID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid rule ID"})
+	}
+
+	params, err := h.bindRule(c, clientID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	rule, err := h.db.UpdateAutoReplyRule(c.Request().Context(), db.UpdateAutoReplyRuleParams{
+		ID:              ruleID,
+		DeviceID:        params.DeviceID,
+		Name:            params.Name,
+		Priority:        params.Priority,
+		IsActive:        params.IsActive,
+		MatchType:       params.MatchType,
+		MatchValue:      params.MatchValue,
+		CaseSensitive:   params.CaseSensitive,
+		MessageTypes:    params.MessageTypes,
+		ChatScope:       params.ChatScope,
+		SenderJids:      params.SenderJids,
+		GroupJids:       params.GroupJids,
+		HoursCondition:  params.HoursCondition,
+		BusinessHours:   params.BusinessHours,
+		ReplyType:       params.ReplyType,
+		ReplyText:       params.ReplyText,
+		TemplateID:      params.TemplateID,
+		MediaUrl:        params.MediaUrl,
+		MediaFilename:   params.MediaFilename,
+		CooldownSeconds: params.CooldownSeconds,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Auto-reply rule not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAutoReplyRule deletes an auto-reply rule
+// @Summary Delete auto-reply rule
+// @Description Delete an auto-reply rule of a device
+// @Tags auto-replies
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Rule ID"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/auto-replies/{client_id}/{id} [delete]
+// @Security BearerAuth
+func (h *AutoReplyHandler) DeleteAutoReplyRule(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var ruleID pgtype.UUID
+	if err := ruleID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid rule ID"})
+	}
+
+	if err := h.db.DeleteAutoReplyRule(c.Request().Context(), db.DeleteAutoReplyRuleParams{ID: ruleID, DeviceID: clientID}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Auto-reply rule deleted successfully"})
+}
+
+// bindRule decodes and validates a rule request into insert parameters for the device.
+func (h *AutoReplyHandler) bindRule(c echo.Context, clientID string) (db.CreateAutoReplyRuleParams, error) {
+	var req AutoReplyRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return db.CreateAutoReplyRuleParams{}, err
+	}
+	if err := c.Validate(req); err != nil {
+		return db.CreateAutoReplyRuleParams{}, err
+	}
+
+	params := db.CreateAutoReplyRuleParams{
+		DeviceID:        clientID,
+		Name:            req.Name,
+		Priority:        req.Priority,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+		MatchType:       req.MatchType,
+		MatchValue:      req.MatchValue,
+		CaseSensitive:   req.CaseSensitive,
+		MessageTypes:    req.MessageTypes,
+		ChatScope:       req.ChatScope,
+		HoursCondition:  req.HoursCondition,
+		ReplyType:       req.ReplyType,
+		ReplyText:       req.ReplyText,
+		MediaUrl:        pgtype.Text{String: req.MediaURL, Valid: req.MediaURL != ""},
+		MediaFilename:   pgtype.Text{String: req.MediaFilename, Valid: req.MediaFilename != ""},
+		CooldownSeconds: req.CooldownSeconds,
+	}
+	if params.MessageTypes == nil {
+		params.MessageTypes = []string{}
+	}
+	if params.ChatScope == "" {
+		params.ChatScope = wa.AutoReplyScopeIndividual
+	}
+	if params.HoursCondition == "" {
+		params.HoursCondition = wa.AutoReplyHoursAlways
+	}
+
+	var err error
+	if params.SenderJids, err = wa.NormalizeJIDs(req.SenderJIDs); err != nil {
+		return db.CreateAutoReplyRuleParams{}, err
+	}
+	if params.GroupJids, err = wa.NormalizeJIDs(req.GroupJIDs); err != nil {
+		return db.CreateAutoReplyRuleParams{}, err
+	}
+
+	if req.BusinessHours != nil {
+		if params.BusinessHours, err = json.Marshal(req.BusinessHours); err != nil {
+			return db.CreateAutoReplyRuleParams{}, err
+		}
+	}
+
+	if req.TemplateID != "" {
+		if err := params.TemplateID.Scan(req.TemplateID); err != nil {
+			return db.CreateAutoReplyRuleParams{}, errors.New("invalid template ID")
+		}
+		template, err := h.db.GetMessageTemplateByID(c.Request().Context(), params.TemplateID)
+		if err != nil || template.UserID.Int32 != getUserIDFromContext(c) {
+			return db.CreateAutoReplyRuleParams{}, errors.New("template not found")
+		}
+	}
+
+	if err := wa.ValidateAutoReplyRule(c.Request().Context(), params); err != nil {
+		return db.CreateAutoReplyRuleParams{}, err
+	}
+	return params, nil
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// AwayMessageHandler manages the per-device business hours and away message
+type AwayMessageHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewAwayMessageHandler creates a new AwayMessageHandler
+func NewAwayMessageHandler(db db.Querier, deviceStore *wa.DeviceStore) *AwayMessageHandler {
+	return &AwayMessageHandler{db: db, deviceStore: deviceStore}
+}
+
+type AwayMessageSettingsRequest struct {
+	IsEnabled             bool             `json:"is_enabled"`
+	Message               string           `json:"message"`
+	BusinessHours         wa.BusinessHours `json:"business_hours"`
+	ResendIntervalMinutes int32            `json:"resend_interval_minutes"`
+	IncludeGroups         bool             `json:"include_groups"`
+}
+
+// GetAwayMessageSettings returns the business hours and away message of a device
+// @Summary Get away message settings
+// @Description Get the business hours, holidays and away message of a device
+// @Tags away-message
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/away-message/{client_id} [get]
+// @Security BearerAuth
+func (h *AwayMessageHandler) GetAwayMessageSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetAwayMessageSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = wa.DefaultAwayMessageSettings(clientID)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateAwayMessageSettings saves the business hours and away message of a device
+// @Summary Update away message settings
+// @Description Update the business hours, holidays and away message of a device
+// @Tags away-message
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body AwayMessageSettingsRequest true "Away message settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/away-message/{client_id} [put]
+// @Security BearerAuth
+func (h *AwayMessageHandler) UpdateAwayMessageSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req AwayMessageSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := req.BusinessHours.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.IsEnabled && req.Message == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Message is required when the away message is enabled"})
+	}
+	if req.ResendIntervalMinutes < 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Resend interval must not be negative"})
+	}
+	if req.ResendIntervalMinutes == 0 {
+		req.ResendIntervalMinutes = wa.DefaultAwayResendInterval
+	}
+
+	businessHours, err := json.Marshal(req.BusinessHours)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.UpsertAwayMessageSettings(c.Request().Context(), db.UpsertAwayMessageSettingsParams{
+		DeviceID:              clientID,
+		IsEnabled:             req.IsEnabled,
+		Message:               req.Message,
+		BusinessHours:         businessHours,
+		ResendIntervalMinutes: req.ResendIntervalMinutes,
+		IncludeGroups:         req.IncludeGroups,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
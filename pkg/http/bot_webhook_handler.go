@@ -0,0 +1,140 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// BotWebhookHandler manages the per-device bot webhook
+type BotWebhookHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewBotWebhookHandler creates a new BotWebhookHandler
+func NewBotWebhookHandler(db db.Querier, deviceStore *wa.DeviceStore) *BotWebhookHandler {
+	return &BotWebhookHandler{db: db, deviceStore: deviceStore}
+}
+
+type BotWebhookRequest struct {
+	IsEnabled       *bool  `json:"is_enabled"`
+	URL             string `json:"url" validate:"required"`
+	Secret          string `json:"secret"`
+	TimeoutSeconds  int32  `json:"timeout_seconds"`
+	FallbackMessage string `json:"fallback_message"`
+	IncludeGroups   bool   `json:"include_groups"`
+}
+
+// GetBotWebhook returns the bot webhook of a device
+// @Summary Get bot webhook
+// @Description Get the endpoint incoming messages of a device are forwarded to
+// @Tags bot-webhook
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/bot-webhook/{client_id} [get]
+// @Security BearerAuth
+func (h *BotWebhookHandler) GetBotWebhook(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	bot, err := h.db.GetBotWebhook(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Bot webhook not configured"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, bot)
+}
+
+// UpdateBotWebhook configures the bot webhook of a device
+// @Summary Update bot webhook
+// @Description Forward every incoming message of a device to a URL and send the replies in its JSON response back into the chat
+// @Tags bot-webhook
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body BotWebhookRequest true "Bot webhook settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/bot-webhook/{client_id} [put]
+// @Security BearerAuth
+func (h *BotWebhookHandler) UpdateBotWebhook(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req BotWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if u, err := url.ParseRequestURI(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "URL must be an http or https URL"})
+	}
+	if req.TimeoutSeconds < 0 || req.TimeoutSeconds > 30 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Timeout must be between 1 and 30 seconds"})
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = 10
+	}
+
+	bot, err := h.db.UpsertBotWebhook(c.Request().Context(), db.UpsertBotWebhookParams{
+		DeviceID:        clientID,
+		IsEnabled:       req.IsEnabled == nil || *req.IsEnabled,
+		Url:             req.URL,
+		Secret:          pgtype.Text{String: req.Secret, Valid: req.Secret != ""},
+		TimeoutSeconds:  req.TimeoutSeconds,
+		FallbackMessage: pgtype.Text{String: req.FallbackMessage, Valid: req.FallbackMessage != ""},
+		IncludeGroups:   req.IncludeGroups,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, bot)
+}
+
+// DeleteBotWebhook removes the bot webhook of a device
+// @Summary Delete bot webhook
+// @Description Stop forwarding incoming messages of a device to a bot
+// @Tags bot-webhook
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/bot-webhook/{client_id} [delete]
+// @Security BearerAuth
+func (h *BotWebhookHandler) DeleteBotWebhook(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.DeleteBotWebhook(c.Request().Context(), clientID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Bot webhook deleted successfully"})
+}
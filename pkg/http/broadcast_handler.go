@@ -23,13 +23,14 @@ func NewBroadcastHandler(db db.Querier, deviceStore *wa.DeviceStore) *BroadcastH
 }
 
 type CreateBroadcastRequest struct {
-	DeviceID    string   `json:"device_id" validate:"required"`
-	Name        string   `json:"name" validate:"required"`
-	Content     string   `json:"content" validate:"required"`
-	MessageType string   `json:"message_type" validate:"required"`
-	Cooldown    int32    `json:"cooldown"`
-	Recipients  []string `json:"recipients" validate:"required"`
-	ScheduledAt string   `json:"scheduled_at"`
+	DeviceID      string   `json:"device_id" validate:"required"`
+	Name          string   `json:"name" validate:"required"`
+	Content       string   `json:"content" validate:"required"`
+	MessageType   string   `json:"message_type" validate:"required"`
+	Cooldown      int32    `json:"cooldown"`
+	Recipients    []string `json:"recipients" validate:"required"`
+	ScheduledAt   string   `json:"scheduled_at"`
+	Transactional bool     `json:"transactional"`
 }
 
 // CreateBroadcast creates a new broadcast job
@@ -71,6 +72,15 @@ func (h *BroadcastHandler) CreateBroadcast(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
+	recipients := make([]string, 0, len(req.Recipients))
+	for _, recipient := range req.Recipients {
+		jid, err := wa.NormalizeRecipient(recipient)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		recipients = append(recipients, jid)
+	}
+
 	var isScheduled bool
 	var scheduledAt pgtype.Timestamptz
 	if req.ScheduledAt != "" {
@@ -84,21 +94,22 @@ func (h *BroadcastHandler) CreateBroadcast(c echo.Context) error {
 	}
 
 	job, err := h.db.CreateBroadcastJob(c.Request().Context(), db.CreateBroadcastJobParams{
-		UserID:      pgtype.Int4{Int32: userID, Valid: true},
-		DeviceID:    pgtype.Text{String: req.DeviceID, Valid: true},
-		Name:        req.Name,
-		MessageType: pgtype.Text{String: req.MessageType, Valid: true},
-		Content:     req.Content,
-		Cooldown:    pgtype.Int4{Int32: req.Cooldown, Valid: true},
-		IsScheduled: isScheduled,
-		ScheduledAt: scheduledAt,
+		UserID:          pgtype.Int4{Int32: userID, Valid: true},
+		DeviceID:        pgtype.Text{String: req.DeviceID, Valid: true},
+		Name:            req.Name,
+		MessageType:     pgtype.Text{String: req.MessageType, Valid: true},
+		Content:         req.Content,
+		Cooldown:        pgtype.Int4{Int32: req.Cooldown, Valid: true},
+		IsScheduled:     isScheduled,
+		ScheduledAt:     scheduledAt,
+		IsTransactional: req.Transactional,
 	})
 	if err != nil {
 		logger.Error("CreateBroadcast: failed to create job error=%v", err)
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
-	for _, recipientJid := range req.Recipients {
+	for _, recipientJid := range recipients {
 		err = h.db.CreateBroadcastRecipient(c.Request().Context(), db.CreateBroadcastRecipientParams{
 			JobID:        job.ID,
 			RecipientJid: recipientJid,
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// CommunityHandler manages WhatsApp Communities: a community groups subgroups together
+// and owns an announcement group that reaches every member.
+type CommunityHandler struct {
+	device   *wa.DeviceStore
+	waClient *wa.WhatsappClient
+	db       db.Querier
+}
+
+func NewCommunityHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient, db db.Querier) *CommunityHandler {
+	return &CommunityHandler{
+		device:   device,
+		waClient: waClient,
+		db:       db,
+	}
+}
+
+type CreateCommunityRequest struct {
+	Name        string `json:"name" validate:"required,max=25"`
+	Description string `json:"description"`
+}
+
+// LinkSubgroupRequest links an existing group by group_jid, or creates a new group in the
+// community from name and participants.
+type LinkSubgroupRequest struct {
+	GroupJID     string   `json:"group_jid"`
+	Name         string   `json:"name" validate:"max=25"`
+	Participants []string `json:"participants"`
+}
+
+type AnnouncementRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+type SubgroupResponse struct {
+	JID  string `json:"jid"`
+	Name string `json:"name"`
+	// IsAnnouncementGroup marks the community's announcement group
+	IsAnnouncementGroup bool `json:"is_announcement_group"`
+}
+
+type AnnouncementResponse struct {
+	GroupJID  string `json:"group_jid"`
+	MessageID string `json:"message_id"`
+}
+
+// authorizeCommunity authorizes the device owner and parses the community of the request.
+func (h *CommunityHandler) authorizeCommunity(c echo.Context) (string, types.JID, int, error) {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return "", types.JID{}, status, err
+	}
+	community, err := groupJIDParam(c, "community_jid")
+	if err != nil {
+		return "", types.JID{}, http.StatusBadRequest, errors.New("Invalid community JID")
+	}
+	return clientID, community, http.StatusOK, nil
+}
+
+// CreateCommunity creates a WhatsApp community
+// @Summary Create community
+// @Description Create a community with a name of at most 25 characters. WhatsApp creates its announcement group automatically.
+// @Tags communities
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body CreateCommunityRequest true "Community"
+// @Success 201 {object} GroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/communities/{client_id} [post]
+// @Security BearerAuth
+func (h *CommunityHandler) CreateCommunity(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req CreateCommunityRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	info, err := h.waClient.CreateCommunity(clientID, req.Name)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if req.Description != "" {
+		if err := h.waClient.SetGroupDescription(clientID, info.JID, req.Description); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		info.Topic = req.Description
+	}
+
+	return c.JSON(http.StatusCreated, newGroupResponse(info))
+}
+
+// GetSubgroups lists the groups of a community
+// @Summary List community subgroups
+// @Description List the groups linked to a community, including its announcement group
+// @Tags communities
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param community_jid path string true "Community JID"
+// @Success 200 {array} SubgroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/communities/{client_id}/{community_jid}/subgroups [get]
+// @Security BearerAuth
+func (h *CommunityHandler) GetSubgroups(c echo.Context) error {
+	clientID, community, status, err := h.authorizeCommunity(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	groups, err := h.waClient.GetSubgroups(clientID, community)
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	resp := make([]SubgroupResponse, 0, len(groups))
+	for _, g := range groups {
+		resp = append(resp, SubgroupResponse{
+			JID:                 g.JID.String(),
+			Name:                g.Name,
+			IsAnnouncementGroup: g.IsDefaultSubGroup,
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// LinkSubgroup adds a group to a community
+// @Summary Link community subgroup
+// @Description Link an existing group (group_jid) to the community, or create a new group in it (name and participants)
+// @Tags communities
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param community_jid path string true "Community JID"
+// @Param request body LinkSubgroupRequest true "Subgroup"
+// @Success 200 {object} GenericResponse
+// @Success 201 {object} CreateGroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/communities/{client_id}/{community_jid}/subgroups [post]
+// @Security BearerAuth
+func (h *CommunityHandler) LinkSubgroup(c echo.Context) error {
+	clientID, community, status, err := h.authorizeCommunity(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req LinkSubgroupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if (req.GroupJID == "") == (req.Name == "") {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Set either group_jid or name"})
+	}
+
+	if req.Name != "" {
+		participants, err := parseParticipants(req.Participants)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+		info, err := h.waClient.CreateSubgroup(clientID, community, req.Name, participants)
+		if err != nil {
+			return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusCreated, CreateGroupResponse{
+			Group:   newGroupResponse(info),
+			Results: participantResults(participants, info.Participants),
+		})
+	}
+
+	group, err := types.ParseJID(req.GroupJID)
+	if err != nil || group.Server != types.GroupServer {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group JID"})
+	}
+	if err := h.waClient.LinkSubgroup(clientID, community, group); err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group linked to the community"})
+}
+
+// UnlinkSubgroup removes a group from a community
+// @Summary Unlink community subgroup
+// @Description Remove a group from the community; the group itself keeps existing
+// @Tags communities
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param community_jid path string true "Community JID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/communities/{client_id}/{community_jid}/subgroups/{group_jid} [delete]
+// @Security BearerAuth
+func (h *CommunityHandler) UnlinkSubgroup(c echo.Context) error {
+	clientID, community, status, err := h.authorizeCommunity(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	group, err := groupJIDParam(c, "group_jid")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.waClient.UnlinkSubgroup(clientID, community, group); err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group unlinked from the community"})
+}
+
+// PostAnnouncement sends a message to the announcement group of a community
+// @Summary Post community announcement
+// @Description Send a text message to the community's announcement group, which reaches every member of the community.
+// @Description Only community admins can post there.
+// @Tags communities
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param community_jid path string true "Community JID"
+// @Param request body AnnouncementRequest true "Announcement"
+// @Success 200 {object} AnnouncementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/communities/{client_id}/{community_jid}/announcements [post]
+// @Security BearerAuth
+func (h *CommunityHandler) PostAnnouncement(c echo.Context) error {
+	clientID, community, status, err := h.authorizeCommunity(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req AnnouncementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	group, err := h.waClient.GetAnnouncementGroup(clientID, community)
+	if errors.Is(err, wa.ErrNoAnnouncementGroup) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	chatJID := group.String()
+	waMessageID, err := h.waClient.SendMessage(clientID, chatJID, req.Text)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.db.LogOutgoingMessage(ctx, db.LogOutgoingMessageParams{
+		DeviceID:      pgtype.Text{String: clientID, Valid: true},
+		Recipient:     chatJID,
+		RecipientType: pgtype.Text{String: "group", Valid: true},
+		MessageType:   pgtype.Text{String: "text", Valid: true},
+		Content:       req.Text,
+		WaMessageID:   pgtype.Text{String: waMessageID, Valid: true},
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	_ = h.db.UpsertThread(ctx, db.UpsertThreadParams{
+		DeviceID:    clientID,
+		ChatJid:     chatJID,
+		ChatType:    "group",
+		Content:     req.Text,
+		MessageType: "text",
+		Direction:   "outgoing",
+		IsIncoming:  false,
+	})
+
+	return c.JSON(http.StatusOK, AnnouncementResponse{GroupJID: chatJID, MessageID: waMessageID})
+}
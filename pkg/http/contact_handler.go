@@ -1,28 +1,104 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow/types"
 )
 
 type ContactHandler struct {
 	device   *wa.DeviceStore
 	waClient *wa.WhatsappClient
+	db       db.Querier
 }
 
-func NewContactHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient) *ContactHandler {
+func NewContactHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient, db db.Querier) *ContactHandler {
 	return &ContactHandler{
 		device:   device,
 		waClient: waClient,
+		db:       db,
 	}
 }
 
+// ProfileResponse is the cached profile of a contact or group.
+type ProfileResponse struct {
+	JID              string     `json:"jid"`
+	PictureURL       string     `json:"picture_url,omitempty"`
+	PictureUpdatedAt *time.Time `json:"picture_updated_at,omitempty"`
+	About            string     `json:"about,omitempty"`
+	AboutUpdatedAt   *time.Time `json:"about_updated_at,omitempty"`
+	IsBusiness       bool       `json:"is_business"`
+	// Business is set for WhatsApp Business accounts
+	Business  *BusinessProfileResponse `json:"business,omitempty"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type BusinessProfileResponse struct {
+	Category    string                    `json:"category,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Address     string                    `json:"address,omitempty"`
+	Email       string                    `json:"email,omitempty"`
+	Websites    []string                  `json:"websites"`
+	Timezone    string                    `json:"timezone,omitempty"`
+	Hours       []wa.ContactBusinessHours `json:"hours"`
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func newProfileResponse(p db.Profile) ProfileResponse {
+	resp := ProfileResponse{
+		JID:              p.Jid,
+		PictureURL:       p.PictureUrl.String,
+		PictureUpdatedAt: optionalTime(p.PictureUpdatedAt),
+		About:            p.About.String,
+		AboutUpdatedAt:   optionalTime(p.AboutUpdatedAt),
+		IsBusiness:       p.IsBusiness,
+		UpdatedAt:        p.UpdatedAt.Time,
+	}
+	if p.IsBusiness {
+		business := &BusinessProfileResponse{
+			Category:    p.BusinessCategory.String,
+			Description: p.BusinessDescription.String,
+			Address:     p.BusinessAddress.String,
+			Email:       p.BusinessEmail.String,
+			Websites:    p.BusinessWebsites,
+			Timezone:    p.BusinessTimezone.String,
+			Hours:       []wa.ContactBusinessHours{},
+		}
+		if business.Websites == nil {
+			business.Websites = []string{}
+		}
+		if len(p.BusinessHours) > 0 {
+			_ = json.Unmarshal(p.BusinessHours, &business.Hours)
+		}
+		resp.Business = business
+	}
+	return resp
+}
+
 // SyncContacts syncs contacts from WhatsApp for a device
 // @Summary Sync contacts
-// @Description Fetch and sync contacts from WhatsApp for a device
+// @Description Fetch and sync contacts from WhatsApp for a device. Their profile pictures, about texts and business
+// @Description profiles are refreshed in the background.
 // @Tags contacts
 // @Accept json
 // @Produce json
@@ -62,6 +138,13 @@ func (h *ContactHandler) SyncContacts(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
+	// Pictures, about texts and business profiles take a request per contact
+	go func() {
+		if err := h.waClient.RefreshDeviceProfiles(clientID); err != nil {
+			logger.Error("Failed to refresh profiles of device %s: %v", clientID, err)
+		}
+	}()
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "Successfully synced contacts",
 	})
@@ -69,7 +152,7 @@ func (h *ContactHandler) SyncContacts(c echo.Context) error {
 
 // GetContacts returns the list of contacts for a device
 // @Summary List contacts
-// @Description Get all contacts for a device
+// @Description Get all contacts for a device with their cached profile picture, about text and business category
 // @Tags contacts
 // @Accept json
 // @Produce json
@@ -106,3 +189,750 @@ func (h *ContactHandler) GetContacts(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, contacts)
 }
+
+type PresenceSubscriptionRequest struct {
+	JID string `json:"jid" validate:"required"`
+}
+
+// SubscribePresence subscribes to the online status of a contact
+// @Summary Subscribe to contact presence
+// @Description Receive online and last seen updates of a contact on the event stream until the device disconnects
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body PresenceSubscriptionRequest true "Contact"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/presence [post]
+// @Security BearerAuth
+func (h *ContactHandler) SubscribePresence(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req PresenceSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	jid, err := types.ParseJID(req.JID)
+	if err != nil || jid.Server != types.DefaultUserServer {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid contact JID"})
+	}
+
+	if err := h.waClient.SubscribePresence(clientID, jid); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Subscribed to presence"})
+}
+
+type CheckNumbersRequest struct {
+	Numbers []string `json:"numbers" validate:"required,min=1,max=500"`
+}
+
+// CheckNumbers validates phone numbers and looks them up on WhatsApp
+// @Summary Check phone numbers
+// @Description Normalize phone numbers to E.164, using the default country code for national numbers such as 0812…,
+// @Description and look up whether they are registered on WhatsApp, with their JID and verified business name.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body CheckNumbersRequest true "Numbers, at most 500"
+// @Success 200 {array} wa.NumberCheck
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/check [post]
+// @Security BearerAuth
+func (h *ContactHandler) CheckNumbers(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req CheckNumbersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	checks, err := h.waClient.CheckNumbers(clientID, req.Numbers)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, checks)
+}
+
+// GetProfile returns the profile of a contact or group
+// @Summary Get profile
+// @Description Get the cached profile picture, about text and business profile (category, description, address, hours,
+// @Description websites) of a contact or group. refresh=true fetches it from WhatsApp first; profiles that were never
+// @Description cached are fetched automatically.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param jid path string true "Contact or group JID"
+// @Param refresh query bool false "Fetch the profile from WhatsApp first"
+// @Success 200 {object} ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/{jid}/profile [get]
+// @Security BearerAuth
+func (h *ContactHandler) GetProfile(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	raw, err := url.PathUnescape(c.Param("jid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JID"})
+	}
+	jid, err := types.ParseJID(raw)
+	if err != nil || (jid.Server != types.DefaultUserServer && jid.Server != types.GroupServer) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JID"})
+	}
+
+	ctx := c.Request().Context()
+	params := db.GetProfileParams{DeviceID: clientID, Jid: jid.String()}
+	profile, err := h.db.GetProfile(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) || c.QueryParam("refresh") == "true" {
+		if err := h.waClient.RefreshProfile(clientID, jid); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		profile, err = h.db.GetProfile(ctx, params)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Profile not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+// RefreshProfiles refreshes the profiles of all contacts and groups
+// @Summary Refresh profiles
+// @Description Fetch the profile pictures, about texts and business profiles of all contacts and groups of a device
+// @Description from WhatsApp in the background. Pictures that did not change are not downloaded again.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 202 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/profiles/refresh [post]
+// @Security BearerAuth
+func (h *ContactHandler) RefreshProfiles(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	go func() {
+		if err := h.waClient.RefreshDeviceProfiles(clientID); err != nil {
+			logger.Error("Failed to refresh profiles of device %s: %v", clientID, err)
+		}
+	}()
+
+	return c.JSON(http.StatusAccepted, GenericResponse{Message: "Refreshing profiles"})
+}
+
+type ContactAttributeRequest struct {
+	// Key is the name of the attribute in requests and in templates ({{attr.<key>}}): lowercase
+	// letters, digits and underscores
+	Key   string `json:"key" validate:"required,max=64"`
+	Label string `json:"label" validate:"required,max=255"`
+	// Type is text, number, boolean, date (YYYY-MM-DD) or select
+	Type string `json:"type" validate:"required,oneof=text number boolean date select"`
+	// Options are the allowed values of a select attribute
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type UpdateContactAttributeRequest struct {
+	Label    string   `json:"label" validate:"required,max=255"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type ContactDetailsRequest struct {
+	Email      string   `json:"email" validate:"omitempty,email,max=255"`
+	ExternalID string   `json:"external_id" validate:"max=255"`
+	Notes      string   `json:"notes"`
+	Tags       []string `json:"tags"`
+	// Attributes are values of the attributes defined for the device, keyed by attribute key
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// ContactDetailsResponse is a contact with the fields kept about it besides what WhatsApp knows.
+type ContactDetailsResponse struct {
+	JID         string                 `json:"jid"`
+	Name        string                 `json:"name,omitempty"`
+	PushName    string                 `json:"push_name,omitempty"`
+	PhoneNumber string                 `json:"phone_number,omitempty"`
+	Email       string                 `json:"email,omitempty"`
+	ExternalID  string                 `json:"external_id,omitempty"`
+	Notes       string                 `json:"notes,omitempty"`
+	Tags        []string               `json:"tags"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+func newContactDetailsResponse(row db.GetContactWithDetailsRow) ContactDetailsResponse {
+	resp := ContactDetailsResponse{
+		JID:         row.Jid,
+		Name:        row.FullName.String,
+		PushName:    row.PushName.String,
+		PhoneNumber: row.PhoneNumber.String,
+		Email:       row.Email.String,
+		ExternalID:  row.ExternalID.String,
+		Notes:       row.Notes.String,
+		Tags:        row.Tags,
+		Attributes:  map[string]interface{}{},
+	}
+	if resp.Name == "" {
+		resp.Name = row.BusinessName.String
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	_ = json.Unmarshal(row.Attributes, &resp.Attributes)
+	return resp
+}
+
+// parseContactJID reads the jid path parameter, given as a user JID or a phone number.
+func parseContactJID(c echo.Context) (types.JID, error) {
+	raw, err := url.PathUnescape(c.Param("jid"))
+	if err != nil {
+		return types.JID{}, err
+	}
+	recipient, err := wa.NormalizeRecipient(raw)
+	if err != nil {
+		return types.JID{}, err
+	}
+	jid, err := types.ParseJID(recipient)
+	if err != nil || jid.Server != types.DefaultUserServer {
+		return types.JID{}, errors.New("not a user JID")
+	}
+	return jid, nil
+}
+
+// GetContactAttributes lists the custom attributes defined for contacts
+// @Summary List contact attributes
+// @Description Get the custom attributes contacts of a device can have, with their type and allowed options
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} db.ContactAttribute
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/attributes [get]
+// @Security BearerAuth
+func (h *ContactHandler) GetContactAttributes(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	attributes, err := h.db.ListContactAttributes(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, attributes)
+}
+
+// CreateContactAttribute defines a custom contact attribute
+// @Summary Create contact attribute
+// @Description Define a custom attribute for the contacts of a device. Values written to contacts are checked against
+// @Description its type; select attributes only accept one of their options.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body ContactAttributeRequest true "Attribute"
+// @Success 201 {object} db.ContactAttribute
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/attributes [post]
+// @Security BearerAuth
+func (h *ContactHandler) CreateContactAttribute(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ContactAttributeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if !wa.IsValidAttributeKey(req.Key) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Key must be lowercase letters, digits and underscores, starting with a letter"})
+	}
+	options, err := attributeOptions(req.Type, req.Options)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	existing, err := h.db.ListContactAttributes(ctx, clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	for _, attr := range existing {
+		if attr.Key == req.Key {
+			return c.JSON(http.StatusConflict, ErrorResponse{Error: "Attribute " + req.Key + " already exists"})
+		}
+	}
+
+	attribute, err := h.db.CreateContactAttribute(ctx, db.CreateContactAttributeParams{
+		DeviceID: clientID,
+		Key:      req.Key,
+		Label:    req.Label,
+		Type:     req.Type,
+		Options:  options,
+		Required: req.Required,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, attribute)
+}
+
+// UpdateContactAttribute changes the label, options or required flag of a contact attribute
+// @Summary Update contact attribute
+// @Description Change the label, options or required flag of a custom contact attribute. Its key and type cannot
+// @Description change; stored values are not checked again.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param key path string true "Attribute key"
+// @Param request body UpdateContactAttributeRequest true "Attribute"
+// @Success 200 {object} db.ContactAttribute
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/attributes/{key} [put]
+// @Security BearerAuth
+func (h *ContactHandler) UpdateContactAttribute(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req UpdateContactAttributeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	key := c.Param("key")
+	var current *db.ContactAttribute
+	existing, err := h.db.ListContactAttributes(ctx, clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	for i := range existing {
+		if existing[i].Key == key {
+			current = &existing[i]
+		}
+	}
+	if current == nil {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Attribute not found"})
+	}
+	options, err := attributeOptions(current.Type, req.Options)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	attribute, err := h.db.UpdateContactAttribute(ctx, db.UpdateContactAttributeParams{
+		Label:    req.Label,
+		Options:  options,
+		Required: req.Required,
+		DeviceID: clientID,
+		Key:      key,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, attribute)
+}
+
+// DeleteContactAttribute removes a contact attribute and its values
+// @Summary Delete contact attribute
+// @Description Remove a custom contact attribute and its value from every contact of the device
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param key path string true "Attribute key"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/attributes/{key} [delete]
+// @Security BearerAuth
+func (h *ContactHandler) DeleteContactAttribute(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	key := c.Param("key")
+	deleted, err := h.db.DeleteContactAttribute(ctx, db.DeleteContactAttributeParams{DeviceID: clientID, Key: key})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Attribute not found"})
+	}
+	if err := h.db.RemoveContactAttributeValues(ctx, db.RemoveContactAttributeValuesParams{Key: key, DeviceID: clientID}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Attribute deleted"})
+}
+
+// attributeOptions checks the options of an attribute: select attributes need at least one,
+// other types take none.
+func attributeOptions(attributeType string, options []string) ([]string, error) {
+	if attributeType != wa.AttributeTypeSelect {
+		if len(options) > 0 {
+			return nil, errors.New("Only select attributes have options")
+		}
+		return []string{}, nil
+	}
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		if option = strings.TrimSpace(option); option != "" {
+			cleaned = append(cleaned, option)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil, errors.New("Select attributes need at least one option")
+	}
+	return cleaned, nil
+}
+
+// GetContactDetails returns a contact with its email, notes, tags and attributes
+// @Summary Get contact details
+// @Description Get a contact with the fields kept about it: email, external CRM ID, notes, tags and custom attributes.
+// @Description These are not touched by contact syncs.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param jid path string true "Contact JID or phone number"
+// @Success 200 {object} ContactDetailsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/{jid}/details [get]
+// @Security BearerAuth
+func (h *ContactHandler) GetContactDetails(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	jid, err := parseContactJID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid contact JID"})
+	}
+
+	contact, err := h.db.GetContactWithDetails(c.Request().Context(), db.GetContactWithDetailsParams{
+		DeviceID: pgtype.Text{String: clientID, Valid: true},
+		Jid:      jid.String(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Contact not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newContactDetailsResponse(contact))
+}
+
+// UpdateContactDetails sets the email, notes, tags and attributes of a contact
+// @Summary Update contact details
+// @Description Replace the email, external CRM ID, notes, tags and custom attributes of a contact. Tags are stored in
+// @Description lowercase; attributes must be defined for the device and match their type. Contacts that were not synced
+// @Description yet are added.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param jid path string true "Contact JID or phone number"
+// @Param request body ContactDetailsRequest true "Contact details"
+// @Success 200 {object} ContactDetailsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/{jid}/details [put]
+// @Security BearerAuth
+func (h *ContactHandler) UpdateContactDetails(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	jid, err := parseContactJID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid contact JID"})
+	}
+
+	var req ContactDetailsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	definitions, err := h.db.ListContactAttributes(ctx, clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if err := wa.ValidateAttributes(definitions, req.Attributes); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	attributes := make(map[string]interface{}, len(req.Attributes))
+	for key, value := range req.Attributes {
+		if value != nil {
+			attributes[key] = value
+		}
+	}
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	deviceID := pgtype.Text{String: clientID, Valid: true}
+	if err := h.db.EnsureContact(ctx, db.EnsureContactParams{
+		DeviceID:    deviceID,
+		Jid:         jid.String(),
+		PhoneNumber: optionalText(jid.User),
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if _, err := h.db.UpsertContactDetails(ctx, db.UpsertContactDetailsParams{
+		DeviceID:   clientID,
+		Jid:        jid.String(),
+		Email:      optionalText(strings.TrimSpace(req.Email)),
+		ExternalID: optionalText(strings.TrimSpace(req.ExternalID)),
+		Notes:      optionalText(req.Notes),
+		Tags:       wa.NormalizeTags(req.Tags),
+		Attributes: encoded,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	contact, err := h.db.GetContactWithDetails(ctx, db.GetContactWithDetailsParams{DeviceID: deviceID, Jid: jid.String()})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newContactDetailsResponse(contact))
+}
+
+// DeleteContactDetails clears the email, notes, tags and attributes of a contact
+// @Summary Delete contact details
+// @Description Clear the email, external CRM ID, notes, tags and custom attributes of a contact
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param jid path string true "Contact JID or phone number"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/{jid}/details [delete]
+// @Security BearerAuth
+func (h *ContactHandler) DeleteContactDetails(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	jid, err := parseContactJID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid contact JID"})
+	}
+
+	deleted, err := h.db.DeleteContactDetails(c.Request().Context(), db.DeleteContactDetailsParams{DeviceID: clientID, Jid: jid.String()})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Contact details not found"})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Contact details deleted"})
+}
+
+// SearchContacts finds contacts by text, tags, email, external ID and attribute values
+// @Summary Search contacts
+// @Description Search the contacts of a device. q matches name, JID, email, external ID and notes; every given tag
+// @Description must be present; attr.<key>=value matches attribute values exactly, e.g. attr.plan=gold&attr.vip=true.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param q query string false "Text to look for"
+// @Param tag query []string false "Tags the contact must have" collectionFormat(multi)
+// @Param email query string false "Email, case insensitive"
+// @Param external_id query string false "External CRM ID"
+// @Param limit query int false "Limit (default 50, max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} ContactDetailsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/search [get]
+// @Security BearerAuth
+func (h *ContactHandler) SearchContacts(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	params := db.SearchContactsParams{
+		DeviceID:   pgtype.Text{String: clientID, Valid: true},
+		Email:      optionalText(c.QueryParam("email")),
+		ExternalID: optionalText(c.QueryParam("external_id")),
+	}
+	if query := strings.TrimSpace(c.QueryParam("q")); query != "" {
+		params.Pattern = pgtype.Text{String: "%" + escapeLike(query) + "%", Valid: true}
+	}
+	if tags := wa.NormalizeTags(c.QueryParams()["tag"]); len(tags) > 0 {
+		params.Tags = tags
+	}
+	params.Attributes, err = h.attributeFilter(ctx, clientID, c.QueryParams())
+	if errors.Is(err, wa.ErrInvalidAttribute) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	params.QueryLimit = int32(limit)
+	params.QueryOffset = int32(offset)
+
+	rows, err := h.db.SearchContacts(ctx, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	contacts := make([]ContactDetailsResponse, 0, len(rows))
+	for _, row := range rows {
+		contacts = append(contacts, newContactDetailsResponse(db.GetContactWithDetailsRow(row)))
+	}
+
+	return c.JSON(http.StatusOK, contacts)
+}
+
+// attributeFilter turns the attr.<key> query parameters into the JSON the attributes of a
+// contact must contain, with each value read as the type of its attribute.
+func (h *ContactHandler) attributeFilter(ctx context.Context, clientID string, query url.Values) ([]byte, error) {
+	values := make(map[string]string)
+	for name, params := range query {
+		if key, ok := strings.CutPrefix(name, wa.AttributeVariablePrefix); ok && len(params) > 0 {
+			values[key] = params[0]
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := h.db.ListContactAttributes(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defined := make(map[string]db.ContactAttribute, len(definitions))
+	for _, def := range definitions {
+		defined[def.Key] = def
+	}
+
+	filter := make(map[string]interface{}, len(values))
+	for key, text := range values {
+		def, ok := defined[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not defined", wa.ErrInvalidAttribute, key)
+		}
+		if filter[key], err = wa.ParseAttributeValue(def, text); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(filter)
+}
+
+// GetContactTags lists the tags in use with the number of contacts that have them
+// @Summary List contact tags
+// @Description Get the tags used on the contacts of a device with how many contacts have each
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} db.ListContactTagsRow
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/contacts/{client_id}/tags [get]
+// @Security BearerAuth
+func (h *ContactHandler) GetContactTags(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	tags, err := h.db.ListContactTags(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errInvalidCursor    = errors.New("invalid cursor")
+	errInvalidSyncToken = errors.New("invalid sync token")
+)
+
+// encodeMessageCursor returns an opaque cursor pointing at a message's position in its thread,
+// which is ordered by (sent_at, id).
+func encodeMessageCursor(sentAt pgtype.Timestamptz, id pgtype.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(formatPosition(sentAt, id)))
+}
+
+// decodeMessageCursor reverses encodeMessageCursor.
+func decodeMessageCursor(cursor string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+	return parsePosition(string(raw))
+}
+
+// encodeThreadCursor returns an opaque cursor pointing at a thread's position in the inbox,
+// which is ordered by (is_pinned, last_message_at, id), newest first.
+func encodeThreadCursor(pinned bool, lastMessageAt pgtype.Timestamptz, id pgtype.UUID) string {
+	raw := strconv.FormatBool(pinned) + "|" + formatPosition(lastMessageAt, id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeThreadCursor reverses encodeThreadCursor.
+func decodeThreadCursor(cursor string) (pgtype.Bool, pgtype.Timestamptz, pgtype.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pgtype.Bool{}, pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	pinnedPart, position, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pgtype.Bool{}, pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	pinned, err := strconv.ParseBool(pinnedPart)
+	if err != nil {
+		return pgtype.Bool{}, pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	at, id, err := parsePosition(position)
+	if err != nil {
+		return pgtype.Bool{}, pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+
+	return pgtype.Bool{Bool: pinned, Valid: true}, at, id, nil
+}
+
+// encodeSyncToken returns an opaque token for a position in a device's message change feed,
+// which is ordered by (xid, seq).
+func encodeSyncToken(xid, seq int64) string {
+	raw := "x" + strconv.FormatInt(xid, 10) + "|" + strconv.FormatInt(seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSyncToken reverses encodeSyncToken. Tokens from before changes were ordered by
+// transaction hold only a seq; they point among the changes written back then, which
+// have xid 0.
+func decodeSyncToken(token string) (int64, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, 0, errInvalidSyncToken
+	}
+
+	if seqPart, ok := strings.CutPrefix(string(raw), "s"); ok {
+		seq, err := strconv.ParseInt(seqPart, 10, 64)
+		if err != nil || seq < 0 {
+			return 0, 0, errInvalidSyncToken
+		}
+		return 0, seq, nil
+	}
+
+	position, ok := strings.CutPrefix(string(raw), "x")
+	if !ok {
+		return 0, 0, errInvalidSyncToken
+	}
+	xidPart, seqPart, ok := strings.Cut(position, "|")
+	if !ok {
+		return 0, 0, errInvalidSyncToken
+	}
+	xid, err := strconv.ParseInt(xidPart, 10, 64)
+	if err != nil || xid < 0 {
+		return 0, 0, errInvalidSyncToken
+	}
+	seq, err := strconv.ParseInt(seqPart, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, 0, errInvalidSyncToken
+	}
+
+	return xid, seq, nil
+}
+
+func formatPosition(at pgtype.Timestamptz, id pgtype.UUID) string {
+	return at.Time.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+}
+
+func parsePosition(raw string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	atPart, idPart, ok := strings.Cut(raw, "|")
+	if !ok {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, atPart)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	var id pgtype.UUID
+	if err := id.Scan(idPart); err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, errInvalidCursor
+	}
+
+	return pgtype.Timestamptz{Time: at, Valid: true}, id, nil
+}
@@ -3,15 +3,14 @@ package http
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
 )
@@ -21,10 +20,20 @@ type DeviceHandler struct {
 	deviceManagement  *wa.DeviceStore
 	db                db.Querier
 	subscriptionStore *wa.SubscriptionStore
+	optOutStore       *wa.OptOutStore
 }
 
-func NewWebhook(whatsappClient *wa.WhatsappClient, management *wa.DeviceStore, db db.Querier, subscriptionStore *wa.SubscriptionStore) *DeviceHandler {
-	return &DeviceHandler{whatsappClient: whatsappClient, deviceManagement: management, db: db, subscriptionStore: subscriptionStore}
+func NewWebhook(whatsappClient *wa.WhatsappClient, management *wa.DeviceStore, db db.Querier, subscriptionStore *wa.SubscriptionStore, optOutStore *wa.OptOutStore) *DeviceHandler {
+	return &DeviceHandler{whatsappClient: whatsappClient, deviceManagement: management, db: db, subscriptionStore: subscriptionStore, optOutStore: optOutStore}
+}
+
+// isSuppressed reports whether a non-transactional message to the recipient must be blocked
+// because the recipient is on the user's suppression list.
+func (w *DeviceHandler) isSuppressed(ctx context.Context, userID int32, recipient string, transactional bool) (bool, error) {
+	if transactional {
+		return false, nil
+	}
+	return w.optOutStore.IsOptedOut(ctx, userID, recipient)
 }
 
 // RegisterDevice registers a new WhatsApp device from the provided request data.
@@ -228,6 +237,7 @@ func (w *DeviceHandler) GetDeviceQR(c echo.Context) error {
 // @Param request body SendMessageRequest true "Message data"
 // @Success 200 {object} SendMessageResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /v1/chats [post]
 // @Security ApiKeyAuth
@@ -247,6 +257,12 @@ func (w *DeviceHandler) SendMessage(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	if suppressed, err := w.isSuppressed(c.Request().Context(), userID, message.MobileNumber, message.Transactional); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	} else if suppressed {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: wa.ErrRecipientOptedOut.Error()})
+	}
+
 	_, err = w.whatsappClient.SendMessage(client.ID, message.MobileNumber, message.Text)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, err.Error())
@@ -264,8 +280,10 @@ func (w *DeviceHandler) SendMessage(c echo.Context) error {
 // @Param mobile_number formData string true "Mobile number"
 // @Param media_url formData file true "Media file"
 // @Param caption formData string false "Caption"
+// @Param transactional formData bool false "Bypass the opt-out list for transactional messages"
 // @Success 200 {object} GenericResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /v1/chats/media [post]
 // @Security ApiKeyAuth
@@ -294,6 +312,12 @@ func (w *DeviceHandler) SendMediaMessage(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	if suppressed, err := w.isSuppressed(c.Request().Context(), userID, message.MobileNumber, message.Transactional); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	} else if suppressed {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: wa.ErrRecipientOptedOut.Error()})
+	}
+
 	fileHeader, err := c.FormFile("media_url")
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "File is required")
@@ -320,13 +344,15 @@ func (w *DeviceHandler) SendMediaMessage(c echo.Context) error {
 
 // SendTemplateMessage sends a message using a template
 // @Summary Send template message
-// @Description Send a message using a template
+// @Description Send a message using a template. Besides the given variables, placeholders can use the name, phone,
+// @Description email, external_id and tags of the recipient contact and its attributes as {{attr.<key>}}.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param request body SendTemplateMessageRequest true "Template message data"
 // @Success 200 {object} GenericResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /v1/chats/template [post]
 // @Security ApiKeyAuth
@@ -347,6 +373,12 @@ func (w *DeviceHandler) SendTemplateMessage(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
+	if suppressed, err := w.isSuppressed(c.Request().Context(), userID, request.To, request.Transactional); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	} else if suppressed {
+		return c.JSON(http.StatusForbidden, ErrorResponse{Error: wa.ErrRecipientOptedOut.Error()})
+	}
+
 	// Parse template ID
 	var templateID pgtype.UUID
 	if err := templateID.Scan(request.TemplateID); err != nil {
@@ -359,14 +391,24 @@ func (w *DeviceHandler) SendTemplateMessage(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, GenericResponse{Message: "Template not found"})
 	}
 
-	// Process the template with variables
-	processedMessage := template.Content
+	// Fields stored on the contact fill placeholders the request leaves out
+	variables := make(map[string]interface{}, len(request.Variables))
+	if jid, err := wa.NormalizeRecipient(request.To); err == nil {
+		contact, err := wa.ContactVariables(c.Request().Context(), w.db, client.ID, jid)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		for key, value := range contact {
+			variables[key] = value
+		}
+	}
 	for key, value := range request.Variables {
-		// Simple placeholder replacement
-		placeholder := "{{" + key + "}}"
-		processedMessage = strings.Replace(processedMessage, placeholder, fmt.Sprintf("%v", value), -1)
+		variables[key] = value
 	}
 
+	// Process the template with variables
+	processedMessage := wa.RenderTemplate(template.Content, variables)
+
 	// Send the processed message
 	_, err = w.whatsappClient.SendMessage(client.ID, request.To, processedMessage)
 	if err != nil {
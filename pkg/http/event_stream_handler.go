@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/labstack/echo/v4"
+)
+
+// eventStreamKeepAlive is how often an idle stream sends a comment so proxies keep it open.
+const eventStreamKeepAlive = 30 * time.Second
+
+// EventStreamHandler streams live device events, such as typing and presence, as Server-Sent Events.
+type EventStreamHandler struct {
+	deviceStore *wa.DeviceStore
+	eventBroker *wa.EventBroker
+}
+
+// NewEventStreamHandler creates a new EventStreamHandler
+func NewEventStreamHandler(deviceStore *wa.DeviceStore, eventBroker *wa.EventBroker) *EventStreamHandler {
+	return &EventStreamHandler{deviceStore: deviceStore, eventBroker: eventBroker}
+}
+
+// Stream sends the live events of a device until the client disconnects
+// @Summary Stream live events
+// @Description Stream live events of a device as Server-Sent Events. The event name is the event type
+// @Description (chat_presence, presence, group_join_request, history_sync, newsletter_join, newsletter_leave,
+// @Description newsletter_live_update) and the data is a JSON object with type, device_id, time and data.
+// @Tags events
+// @Produce text/event-stream
+// @Param client_id path string true "Device ID"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/events/{client_id} [get]
+// @Security BearerAuth
+func (h *EventStreamHandler) Stream(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	events, unsubscribe := h.eventBroker.Subscribe(clientID)
+	defer unsubscribe()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var event Event
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			event = Event{Comment: []byte("keep-alive")}
+		case evt := <-events:
+			data, err := json.Marshal(evt)
+			if err != nil {
+				logger.Error("Failed to encode %s event for device %s: %v", evt.Type, clientID, err)
+				continue
+			}
+			event = Event{
+				ID:    []byte(evt.Time.Format(time.RFC3339Nano)),
+				Event: []byte(evt.Type),
+				Data:  data,
+			}
+		}
+
+		if err := event.MarshalTo(w); err != nil {
+			return nil
+		}
+		w.Flush()
+	}
+}
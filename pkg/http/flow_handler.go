@@ -0,0 +1,424 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// FlowHandler manages conversational flows and their versions
+type FlowHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewFlowHandler creates a new FlowHandler
+func NewFlowHandler(db db.Querier, deviceStore *wa.DeviceStore) *FlowHandler {
+	return &FlowHandler{db: db, deviceStore: deviceStore}
+}
+
+type FlowRequest struct {
+	Name            string          `json:"name" validate:"required"`
+	TriggerType     string          `json:"trigger_type"`
+	TriggerKeywords []string        `json:"trigger_keywords"`
+	IsActive        *bool           `json:"is_active"`
+	WebhookURL      string          `json:"webhook_url"`
+	WebhookSecret   string          `json:"webhook_secret"`
+	TimeoutSeconds  int32           `json:"timeout_seconds"`
+	Definition      json.RawMessage `json:"definition"`
+}
+
+type FlowVersionRequest struct {
+	Version int32 `json:"version" validate:"required"`
+}
+
+// FlowResponse is a flow together with the definition of its current version
+type FlowResponse struct {
+	db.Flow
+	Definition json.RawMessage `json:"definition"`
+}
+
+// GetFlows returns the flows of a device
+// @Summary List flows
+// @Description Get all conversational flows of a device
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id} [get]
+// @Security BearerAuth
+func (h *FlowHandler) GetFlows(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	flows, err := h.db.GetFlows(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if flows == nil {
+		flows = []db.Flow{}
+	}
+
+	return c.JSON(http.StatusOK, flows)
+}
+
+// GetFlow returns a flow with the definition of its current version
+// @Summary Get flow
+// @Description Get a conversational flow with its current definition
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Flow ID"
+// @Success 200 {object} FlowResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/{id} [get]
+// @Security BearerAuth
+func (h *FlowHandler) GetFlow(c echo.Context) error {
+	flow, status, err := h.findFlow(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	version, err := h.db.GetFlowVersion(c.Request().Context(), db.GetFlowVersionParams{FlowID: flow.ID, Version: flow.CurrentVersion})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, FlowResponse{Flow: flow, Definition: version.Definition})
+}
+
+// CreateFlow creates a flow and its first version
+// @Summary Create flow
+// @Description Create a conversational flow from a JSON definition
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body FlowRequest true "Flow data"
+// @Success 201 {object} FlowResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id} [post]
+// @Security BearerAuth
+func (h *FlowHandler) CreateFlow(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	req, err := bindFlowRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if len(req.Definition) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Definition is required"})
+	}
+
+	ctx := c.Request().Context()
+	flow, err := h.db.CreateFlow(ctx, db.CreateFlowParams{
+		DeviceID:        clientID,
+		Name:            req.Name,
+		TriggerType:     req.TriggerType,
+		TriggerKeywords: req.TriggerKeywords,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+		WebhookUrl:      pgtype.Text{String: req.WebhookURL, Valid: req.WebhookURL != ""},
+		TimeoutSeconds:  req.TimeoutSeconds,
+		WebhookSecret:   pgtype.Text{String: req.WebhookSecret, Valid: req.WebhookSecret != ""},
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	flow, err = h.publishVersion(c, flow, req.Definition)
+	if err != nil {
+		_ = h.db.DeleteFlow(ctx, db.DeleteFlowParams{ID: flow.ID, DeviceID: clientID})
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, FlowResponse{Flow: flow, Definition: req.Definition})
+}
+
+// UpdateFlow updates a flow; a new definition is published as a new version
+// @Summary Update flow
+// @Description Update a conversational flow. Sending a definition publishes it as a new version; sessions in progress keep the version they started on.
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Flow ID"
+// @Param request body FlowRequest true "Flow data"
+// @Success 200 {object} FlowResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/{id} [put]
+// @Security BearerAuth
+func (h *FlowHandler) UpdateFlow(c echo.Context) error {
+	flow, status, err := h.findFlow(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	req, err := bindFlowRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	flow, err = h.db.UpdateFlow(ctx, db.UpdateFlowParams{
+		ID:              flow.ID,
+		DeviceID:        flow.DeviceID,
+		Name:            req.Name,
+		TriggerType:     req.TriggerType,
+		TriggerKeywords: req.TriggerKeywords,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+		WebhookUrl:      pgtype.Text{String: req.WebhookURL, Valid: req.WebhookURL != ""},
+		TimeoutSeconds:  req.TimeoutSeconds,
+		WebhookSecret:   pgtype.Text{String: req.WebhookSecret, Valid: req.WebhookSecret != ""},
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	definition := req.Definition
+	if len(definition) > 0 {
+		if flow, err = h.publishVersion(c, flow, definition); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+	} else {
+		version, err := h.db.GetFlowVersion(ctx, db.GetFlowVersionParams{FlowID: flow.ID, Version: flow.CurrentVersion})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		definition = version.Definition
+	}
+
+	return c.JSON(http.StatusOK, FlowResponse{Flow: flow, Definition: definition})
+}
+
+// DeleteFlow deletes a flow with all its versions and sessions
+// @Summary Delete flow
+// @Description Delete a conversational flow
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Flow ID"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/{id} [delete]
+// @Security BearerAuth
+func (h *FlowHandler) DeleteFlow(c echo.Context) error {
+	flow, status, err := h.findFlow(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.DeleteFlow(c.Request().Context(), db.DeleteFlowParams{ID: flow.ID, DeviceID: flow.DeviceID}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Flow deleted successfully"})
+}
+
+// GetFlowVersions returns every published version of a flow
+// @Summary List flow versions
+// @Description Get all published versions of a conversational flow, newest first
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Flow ID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/{id}/versions [get]
+// @Security BearerAuth
+func (h *FlowHandler) GetFlowVersions(c echo.Context) error {
+	flow, status, err := h.findFlow(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	versions, err := h.db.GetFlowVersions(c.Request().Context(), flow.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if versions == nil {
+		versions = []db.FlowVersion{}
+	}
+
+	return c.JSON(http.StatusOK, versions)
+}
+
+// SetFlowVersion switches a flow to a previously published version
+// @Summary Roll back flow
+// @Description Make an earlier published version the current version of a flow
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Flow ID"
+// @Param request body FlowVersionRequest true "Version"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/{id}/version [put]
+// @Security BearerAuth
+func (h *FlowHandler) SetFlowVersion(c echo.Context) error {
+	flow, status, err := h.findFlow(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req FlowVersionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	_, err = h.db.GetFlowVersion(ctx, db.GetFlowVersionParams{FlowID: flow.ID, Version: req.Version})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Flow version not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.SetFlowCurrentVersion(ctx, db.SetFlowCurrentVersionParams{ID: flow.ID, CurrentVersion: req.Version}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Flow version updated successfully"})
+}
+
+// CloseFlowSession ends the open flow session of a chat, e.g. once an agent resolved a handoff
+// @Summary Close flow session
+// @Description End the active or handed off flow session of a chat
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/flows/{client_id}/sessions/{chat_jid} [delete]
+// @Security BearerAuth
+func (h *FlowHandler) CloseFlowSession(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	chatJID, err := url.PathUnescape(c.Param("chat_jid"))
+	if err != nil || chatJID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Chat JID is required"})
+	}
+
+	closed, err := h.db.CloseFlowSession(c.Request().Context(), db.CloseFlowSessionParams{DeviceID: clientID, ChatJid: chatJID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if closed == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "No open flow session for this chat"})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Flow session closed successfully"})
+}
+
+// findFlow loads the flow addressed by the client_id and id path parameters.
+func (h *FlowHandler) findFlow(c echo.Context) (db.Flow, int, error) {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return db.Flow{}, status, err
+	}
+
+	var flowID pgtype.UUID
+	if err := flowID.Scan(c.Param("id")); err != nil {
+		return db.Flow{}, http.StatusBadRequest, errors.New("Invalid flow ID")
+	}
+
+	flow, err := h.db.GetFlow(c.Request().Context(), db.GetFlowParams{ID: flowID, DeviceID: clientID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Flow{}, http.StatusNotFound, errors.New("Flow not found")
+	}
+	if err != nil {
+		return db.Flow{}, http.StatusInternalServerError, err
+	}
+	return flow, http.StatusOK, nil
+}
+
+// publishVersion stores definition as the next version of the flow and makes it current.
+func (h *FlowHandler) publishVersion(c echo.Context, flow db.Flow, definition json.RawMessage) (db.Flow, error) {
+	ctx := c.Request().Context()
+	version, err := h.db.CreateFlowVersion(ctx, db.CreateFlowVersionParams{FlowID: flow.ID, Definition: definition})
+	if err != nil {
+		return flow, err
+	}
+	if err := h.db.SetFlowCurrentVersion(ctx, db.SetFlowCurrentVersionParams{ID: flow.ID, CurrentVersion: version.Version}); err != nil {
+		return flow, err
+	}
+	flow.CurrentVersion = version.Version
+	return flow, nil
+}
+
+func bindFlowRequest(c echo.Context) (FlowRequest, error) {
+	var req FlowRequest
+	if err := c.Bind(&req); err != nil {
+		return req, err
+	}
+	if err := c.Validate(req); err != nil {
+		return req, err
+	}
+
+	if req.TriggerType == "" {
+		req.TriggerType = wa.FlowTriggerKeyword
+	}
+	switch req.TriggerType {
+	case wa.FlowTriggerKeyword:
+		if len(req.TriggerKeywords) == 0 {
+			return req, errors.New("trigger_keywords is required for keyword flows")
+		}
+	case wa.FlowTriggerAny:
+	default:
+		return req, errors.New("trigger_type must be keyword or any")
+	}
+	if req.TriggerKeywords == nil {
+		req.TriggerKeywords = []string{}
+	}
+	if req.TimeoutSeconds < 0 {
+		return req, errors.New("timeout_seconds must not be negative")
+	}
+	if req.WebhookURL != "" {
+		if u, err := url.ParseRequestURI(req.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return req, errors.New("webhook_url must be an http or https URL")
+		}
+	}
+	if len(req.Definition) > 0 {
+		if _, err := wa.ParseFlowDefinition(req.Definition); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
@@ -2,21 +2,32 @@ package http
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/fransfilastap/kontak/pkg/wa"
 	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
 )
 
 type GroupHandler struct {
 	device   *wa.DeviceStore
 	waClient *wa.WhatsappClient
+	db       db.Querier
 }
 
-func NewGroupHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient) *GroupHandler {
+func NewGroupHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient, db db.Querier) *GroupHandler {
 	return &GroupHandler{
 		device:   device,
 		waClient: waClient,
+		db:       db,
 	}
 }
 
@@ -108,3 +119,849 @@ func (g *GroupHandler) GetJoinedGroups(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, groups)
 }
+
+type CreateGroupRequest struct {
+	Name         string   `json:"name" validate:"required,max=25"`
+	Participants []string `json:"participants" validate:"required,min=1"`
+}
+
+type GroupParticipantsRequest struct {
+	// Action is add, remove, promote or demote
+	Action       string   `json:"action" validate:"required,oneof=add remove promote demote"`
+	Participants []string `json:"participants" validate:"required,min=1"`
+}
+
+type GroupNameRequest struct {
+	Name string `json:"name" validate:"required,max=25"`
+}
+
+type GroupDescriptionRequest struct {
+	Description string `json:"description"`
+}
+
+type GroupSettingsRequest struct {
+	Announce *bool `json:"announce"`
+	Locked   *bool `json:"locked"`
+}
+
+type JoinGroupRequest struct {
+	// Code is an invite code or a full https://chat.whatsapp.com/ link
+	Code string `json:"code" validate:"required"`
+}
+
+type GroupJoinRequestsRequest struct {
+	// Action is approve or reject
+	Action       string   `json:"action" validate:"required,oneof=approve reject"`
+	Participants []string `json:"participants"`
+	// All applies the action to every pending request instead of the listed participants
+	All bool `json:"all"`
+}
+
+type GroupParticipantResponse struct {
+	JID          string `json:"jid"`
+	PhoneNumber  string `json:"phone_number,omitempty"`
+	IsAdmin      bool   `json:"is_admin"`
+	IsSuperAdmin bool   `json:"is_super_admin"`
+}
+
+type GroupResponse struct {
+	JID          string                     `json:"jid"`
+	Name         string                     `json:"name"`
+	Description  string                     `json:"description"`
+	OwnerJID     string                     `json:"owner_jid,omitempty"`
+	IsAnnounce   bool                       `json:"is_announce"`
+	IsLocked     bool                       `json:"is_locked"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	Participants []GroupParticipantResponse `json:"participants"`
+}
+
+// GroupParticipantResult is the outcome of a participant change for one requested participant
+type GroupParticipantResult struct {
+	JID     string `json:"jid"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type CreateGroupResponse struct {
+	Group   GroupResponse            `json:"group"`
+	Results []GroupParticipantResult `json:"results"`
+}
+
+type GroupPictureResponse struct {
+	PictureID string `json:"picture_id"`
+}
+
+type GroupInviteLinkResponse struct {
+	Link string `json:"link"`
+	Code string `json:"code"`
+}
+
+type JoinGroupResponse struct {
+	GroupJID string `json:"group_jid"`
+	// Joined is false when the group requires admin approval and only a join request was sent
+	Joined bool `json:"joined"`
+}
+
+type GroupJoinRequestResponse struct {
+	JID         string    `json:"jid"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// participantErrors explains the error codes WhatsApp returns for participant changes
+var participantErrors = map[int]string{
+	401: "Not allowed to change this participant",
+	403: "The participant's privacy settings do not allow adding them; invite them with a link instead",
+	404: "Not a participant of the group",
+	408: "The participant left the group recently and cannot be added yet",
+	409: "Already a participant of the group",
+	500: "The group is full",
+}
+
+func newGroupResponse(info *types.GroupInfo) GroupResponse {
+	resp := GroupResponse{
+		JID:          info.JID.String(),
+		Name:         info.Name,
+		Description:  info.Topic,
+		IsAnnounce:   info.IsAnnounce,
+		IsLocked:     info.IsLocked,
+		CreatedAt:    info.GroupCreated,
+		Participants: make([]GroupParticipantResponse, 0, len(info.Participants)),
+	}
+	if !info.OwnerJID.IsEmpty() {
+		resp.OwnerJID = info.OwnerJID.String()
+	}
+	for _, p := range info.Participants {
+		participant := GroupParticipantResponse{
+			JID:          p.JID.String(),
+			IsAdmin:      p.IsAdmin,
+			IsSuperAdmin: p.IsSuperAdmin,
+		}
+		if !p.PhoneNumber.IsEmpty() {
+			participant.PhoneNumber = p.PhoneNumber.String()
+		}
+		resp.Participants = append(resp.Participants, participant)
+	}
+	return resp
+}
+
+// participantResults reports the outcome for each requested participant. WhatsApp may
+// answer with the LID of a participant that was requested by phone number, so both are matched.
+func participantResults(requested []types.JID, participants []types.GroupParticipant) []GroupParticipantResult {
+	results := make([]GroupParticipantResult, 0, len(requested))
+	for _, jid := range requested {
+		result := GroupParticipantResult{JID: jid.String(), Error: "No result from WhatsApp"}
+		for _, p := range participants {
+			if p.JID != jid && p.PhoneNumber != jid && p.LID != jid {
+				continue
+			}
+			result = GroupParticipantResult{JID: jid.String(), Success: p.Error == 0, Code: p.Error}
+			if p.Error != 0 {
+				result.Error = participantErrors[p.Error]
+				if result.Error == "" {
+					result.Error = fmt.Sprintf("WhatsApp rejected the change with code %d", p.Error)
+				}
+			}
+			break
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// parseParticipants accepts phone numbers, in international or national format, and JIDs.
+func parseParticipants(participants []string) ([]types.JID, error) {
+	jids := make([]types.JID, 0, len(participants))
+	for _, p := range participants {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return nil, errors.New("Participant must not be empty")
+		}
+		if !strings.ContainsRune(p, '@') {
+			jid, err := wa.PhoneJID(p)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid participant: %s", p)
+			}
+			jids = append(jids, jid)
+			continue
+		}
+		jid, err := types.ParseJID(p)
+		if err != nil || jid.User == "" || (jid.Server != types.DefaultUserServer && jid.Server != types.HiddenUserServer) {
+			return nil, fmt.Errorf("Invalid participant: %s", p)
+		}
+		jids = append(jids, jid)
+	}
+	return jids, nil
+}
+
+// groupErrorStatus maps the errors WhatsApp returns for group requests to a status code.
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, whatsmeow.ErrInviteLinkInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, whatsmeow.ErrNotInGroup), errors.Is(err, whatsmeow.ErrGroupInviteLinkUnauthorized):
+		return http.StatusForbidden
+	case errors.Is(err, whatsmeow.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, whatsmeow.ErrInviteLinkRevoked):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// groupJIDParam parses a path parameter holding a group JID.
+func groupJIDParam(c echo.Context, name string) (types.JID, error) {
+	raw, err := url.PathUnescape(c.Param(name))
+	if err != nil {
+		return types.JID{}, errors.New("Invalid group JID")
+	}
+	jid, err := types.ParseJID(raw)
+	if err != nil || jid.Server != types.GroupServer {
+		return types.JID{}, errors.New("Invalid group JID")
+	}
+	return jid, nil
+}
+
+// authorizeGroup authorizes the device owner and parses the group of the request.
+func (g *GroupHandler) authorizeGroup(c echo.Context) (string, types.JID, int, error) {
+	clientID, status, err := authorizeDevice(c, g.device)
+	if err != nil {
+		return "", types.JID{}, status, err
+	}
+	group, err := groupJIDParam(c, "group_jid")
+	if err != nil {
+		return "", types.JID{}, http.StatusBadRequest, err
+	}
+	return clientID, group, http.StatusOK, nil
+}
+
+// CreateGroup creates a WhatsApp group
+// @Summary Create group
+// @Description Create a group with a name of at most 25 characters and its first participants (phone numbers or JIDs).
+// @Description The results list which participants could not be added and why.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body CreateGroupRequest true "Group"
+// @Success 201 {object} CreateGroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id} [post]
+// @Security BearerAuth
+func (g *GroupHandler) CreateGroup(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, g.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req CreateGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	participants, err := parseParticipants(req.Participants)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	info, err := g.waClient.CreateGroup(clientID, req.Name, participants)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, CreateGroupResponse{
+		Group:   newGroupResponse(info),
+		Results: participantResults(participants, info.Participants),
+	})
+}
+
+// GetGroup returns the current info of a group
+// @Summary Get group
+// @Description Get the name, description, settings and participants of a group from WhatsApp
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid} [get]
+// @Security BearerAuth
+func (g *GroupHandler) GetGroup(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	info, err := g.waClient.GetGroupInfo(clientID, group)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newGroupResponse(info))
+}
+
+// UpdateGroupParticipants adds, removes, promotes or demotes group participants
+// @Summary Update group participants
+// @Description Add or remove participants, or promote them to or demote them from admin. Returns the result per participant;
+// @Description the request succeeds even when some participants could not be changed.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param request body GroupParticipantsRequest true "Participant change"
+// @Success 200 {array} GroupParticipantResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/participants [post]
+// @Security BearerAuth
+func (g *GroupHandler) UpdateGroupParticipants(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req GroupParticipantsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	participants, err := parseParticipants(req.Participants)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := g.waClient.UpdateGroupParticipants(clientID, group, participants, whatsmeow.ParticipantChange(req.Action))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, participantResults(participants, result))
+}
+
+// SetGroupName renames a group
+// @Summary Set group name
+// @Description Change the name of a group (at most 25 characters)
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param request body GroupNameRequest true "Group name"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/name [put]
+// @Security BearerAuth
+func (g *GroupHandler) SetGroupName(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req GroupNameRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := g.waClient.SetGroupName(clientID, group, req.Name); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group name updated"})
+}
+
+// SetGroupDescription changes the description of a group
+// @Summary Set group description
+// @Description Change the description of a group; an empty description removes it
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param request body GroupDescriptionRequest true "Group description"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/description [put]
+// @Security BearerAuth
+func (g *GroupHandler) SetGroupDescription(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req GroupDescriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := g.waClient.SetGroupDescription(clientID, group, req.Description); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group description updated"})
+}
+
+// SetGroupPicture changes the picture of a group
+// @Summary Set group picture
+// @Description Upload a JPEG image as the group picture
+// @Tags groups
+// @Accept multipart/form-data
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param picture formData file true "JPEG image"
+// @Success 200 {object} GroupPictureResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/picture [put]
+// @Security BearerAuth
+func (g *GroupHandler) SetGroupPicture(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	fileHeader, err := c.FormFile("picture")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Picture is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	defer file.Close()
+
+	picture, err := io.ReadAll(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if http.DetectContentType(picture) != "image/jpeg" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Picture must be a JPEG image"})
+	}
+
+	pictureID, err := g.waClient.SetGroupPhoto(clientID, group, picture)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GroupPictureResponse{PictureID: pictureID})
+}
+
+// RemoveGroupPicture removes the picture of a group
+// @Summary Remove group picture
+// @Description Remove the group picture
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/picture [delete]
+// @Security BearerAuth
+func (g *GroupHandler) RemoveGroupPicture(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := g.waClient.SetGroupPhoto(clientID, group, nil); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group picture removed"})
+}
+
+// UpdateGroupSettings toggles the announce-only and locked-info settings of a group
+// @Summary Update group settings
+// @Description Set announce to let only admins send messages, and locked to let only admins edit the group info.
+// @Description Omitted settings stay unchanged.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param request body GroupSettingsRequest true "Group settings"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/settings [put]
+// @Security BearerAuth
+func (g *GroupHandler) UpdateGroupSettings(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req GroupSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.Announce == nil && req.Locked == nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Set announce, locked or both"})
+	}
+
+	if req.Announce != nil {
+		if err := g.waClient.SetGroupAnnounce(clientID, group, *req.Announce); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+	}
+	if req.Locked != nil {
+		if err := g.waClient.SetGroupLocked(clientID, group, *req.Locked); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Group settings updated"})
+}
+
+// LeaveGroup leaves a group
+// @Summary Leave group
+// @Description Leave a group; it is removed from the device's group list
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/leave [post]
+// @Security BearerAuth
+func (g *GroupHandler) LeaveGroup(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := g.waClient.LeaveGroup(clientID, group); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Left the group"})
+}
+
+// GetGroupInviteLink returns the invite link of a group
+// @Summary Get group invite link
+// @Description Get the invite link of a group. Only group admins can see it.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GroupInviteLinkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/invite-link [get]
+// @Security BearerAuth
+func (g *GroupHandler) GetGroupInviteLink(c echo.Context) error {
+	return g.inviteLink(c, false)
+}
+
+// ResetGroupInviteLink revokes the invite link of a group and returns a new one
+// @Summary Reset group invite link
+// @Description Revoke the current invite link of a group so it can no longer be used, and return a new one
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {object} GroupInviteLinkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/invite-link/reset [post]
+// @Security BearerAuth
+func (g *GroupHandler) ResetGroupInviteLink(c echo.Context) error {
+	return g.inviteLink(c, true)
+}
+
+func (g *GroupHandler) inviteLink(c echo.Context, reset bool) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	link, err := g.waClient.GetGroupInviteLink(clientID, group, reset)
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GroupInviteLinkResponse{
+		Link: link,
+		Code: strings.TrimPrefix(link, whatsmeow.InviteLinkPrefix),
+	})
+}
+
+// JoinGroup joins a group with an invite link
+// @Summary Join group
+// @Description Join a group with an invite code or link. When the group requires admin approval,
+// @Description a join request is sent instead and joined is false.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body JoinGroupRequest true "Invite code"
+// @Success 200 {object} JoinGroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/join [post]
+// @Security BearerAuth
+func (g *GroupHandler) JoinGroup(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, g.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req JoinGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	group, joined, err := g.waClient.JoinGroupWithLink(clientID, strings.TrimSpace(req.Code))
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, JoinGroupResponse{GroupJID: group.String(), Joined: joined})
+}
+
+// GetGroupJoinRequests lists the pending requests to join a group
+// @Summary List group join requests
+// @Description List who asked to join a group that requires admin approval
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {array} GroupJoinRequestResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/join-requests [get]
+// @Security BearerAuth
+func (g *GroupHandler) GetGroupJoinRequests(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	requests, err := g.waClient.GetGroupJoinRequests(clientID, group)
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	resp := make([]GroupJoinRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		resp = append(resp, GroupJoinRequestResponse{JID: r.JID.String(), RequestedAt: r.RequestedAt})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateGroupJoinRequests approves or rejects requests to join a group
+// @Summary Approve or reject group join requests
+// @Description Approve or reject the join requests of the listed participants, or of everyone pending with all.
+// @Description Returns the result per participant.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param request body GroupJoinRequestsRequest true "Join request decision"
+// @Success 200 {array} GroupParticipantResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/join-requests [post]
+// @Security BearerAuth
+func (g *GroupHandler) UpdateGroupJoinRequests(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req GroupJoinRequestsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.All == (len(req.Participants) > 0) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Set either participants or all"})
+	}
+
+	var participants []types.JID
+	if req.All {
+		requests, err := g.waClient.GetGroupJoinRequests(clientID, group)
+		if err != nil {
+			return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+		}
+		for _, r := range requests {
+			participants = append(participants, r.JID)
+		}
+		if len(participants) == 0 {
+			return c.JSON(http.StatusOK, []GroupParticipantResult{})
+		}
+	} else {
+		participants, err = parseParticipants(req.Participants)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	result, err := g.waClient.UpdateGroupJoinRequests(clientID, group, participants, whatsmeow.ParticipantRequestChange(req.Action))
+	if err != nil {
+		return c.JSON(groupErrorStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, participantResults(participants, result))
+}
+
+// GetGroupMembers returns the stored participants of a group
+// @Summary List group members
+// @Description List the participants of a group with their role, join time and contact name, as stored from syncs and
+// @Description group events. Unlike getting the group, this does not ask WhatsApp. joined_at is only known for
+// @Description participants that joined while the device was watching.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Success 200 {array} db.ListGroupParticipantsRow
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/members [get]
+// @Security BearerAuth
+func (g *GroupHandler) GetGroupMembers(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	members, err := g.db.ListGroupParticipants(c.Request().Context(), db.ListGroupParticipantsParams{
+		DeviceID: clientID,
+		GroupJid: group.String(),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// GetGroupHistory returns the membership history of a group
+// @Summary Get group history
+// @Description List who joined, left, was promoted or demoted, and name and description changes, newest first.
+// @Description For example action=leave&since=2024-06-03T00:00:00Z lists who left since that time.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param group_jid path string true "Group JID"
+// @Param action query string false "join, leave, promote, demote, name or description"
+// @Param since query string false "Only changes at or after this time (RFC3339)"
+// @Param until query string false "Only changes before this time (RFC3339)"
+// @Param limit query int false "Limit (default 50, max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} db.ListGroupEventsRow
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/groups/{client_id}/{group_jid}/history [get]
+// @Security BearerAuth
+func (g *GroupHandler) GetGroupHistory(c echo.Context) error {
+	clientID, group, status, err := g.authorizeGroup(c)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	action := c.QueryParam("action")
+	if action != "" && !wa.IsValidGroupEvent(action) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid action"})
+	}
+	since, err := parseTimeParam(c, "since")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	until, err := parseTimeParam(c, "until")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, err := g.db.ListGroupEvents(c.Request().Context(), db.ListGroupEventsParams{
+		DeviceID:    clientID,
+		GroupJid:    group.String(),
+		Action:      optionalText(action),
+		Since:       since,
+		Until:       until,
+		QueryLimit:  int32(limit),
+		QueryOffset: int32(offset),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
@@ -1,6 +1,15 @@
 package http
 
-import "github.com/labstack/echo/v4"
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
 
 // Helper function to get user ID from context
 func getUserIDFromContext(c echo.Context) int32 {
@@ -11,3 +20,51 @@ func getUserIDFromContext(c echo.Context) int32 {
 	}
 	return 0
 }
+
+// authorizeDevice checks that the client_id path parameter belongs to the authenticated user
+// and returns the HTTP status to respond with when it does not.
+func authorizeDevice(c echo.Context, deviceStore *wa.DeviceStore) (string, int, error) {
+	return authorize(c, deviceStore.GetDeviceByIDAndUserID)
+}
+
+// authorizeMember is like authorizeDevice but also admits team members of the device.
+func authorizeMember(c echo.Context, deviceStore *wa.DeviceStore) (string, int, error) {
+	return authorize(c, deviceStore.GetDeviceForMember)
+}
+
+func authorize(c echo.Context, lookup func(ctx context.Context, id string, userID int32) (db.Client, error)) (string, int, error) {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return "", http.StatusUnauthorized, errors.New("Unauthorized")
+	}
+
+	clientID := c.Param("client_id")
+	if clientID == "" {
+		return "", http.StatusBadRequest, errors.New("Client ID is required")
+	}
+
+	_, err := lookup(c.Request().Context(), clientID, userID)
+	if err != nil && errors.Is(err, wa.ErrDeviceNotFound) {
+		return "", http.StatusNotFound, errors.New("Device not found")
+	}
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	return clientID, http.StatusOK, nil
+}
+
+// findThread loads the thread of chatJID on the device, mapping a missing thread to 404.
+func findThread(c echo.Context, dbQuerier db.Querier, clientID, chatJID string) (db.MessageThread, int, error) {
+	thread, err := dbQuerier.GetThread(c.Request().Context(), db.GetThreadParams{
+		DeviceID: clientID,
+		ChatJid:  chatJID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.MessageThread{}, http.StatusNotFound, errors.New("Thread not found")
+	}
+	if err != nil {
+		return db.MessageThread{}, http.StatusInternalServerError, err
+	}
+	return thread, http.StatusOK, nil
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// HistorySyncHandler exposes the import of WhatsApp chat history into the inbox
+type HistorySyncHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewHistorySyncHandler creates a new HistorySyncHandler
+func NewHistorySyncHandler(db db.Querier, deviceStore *wa.DeviceStore) *HistorySyncHandler {
+	return &HistorySyncHandler{db: db, deviceStore: deviceStore}
+}
+
+type HistorySyncSettingsRequest struct {
+	IsEnabled          bool  `json:"is_enabled"`
+	MaxDays            int32 `json:"max_days"`
+	MaxMessagesPerChat int32 `json:"max_messages_per_chat"`
+}
+
+// GetHistorySyncProgress returns how far the history import of a device has come
+// @Summary Get history sync progress
+// @Description Get the progress of the latest WhatsApp history sync, with the number of chats and messages imported so far
+// @Tags history-sync
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/history-sync/{client_id} [get]
+// @Security BearerAuth
+func (h *HistorySyncHandler) GetHistorySyncProgress(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	progress, err := h.db.GetHistorySyncProgress(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "No history sync received yet"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, progress)
+}
+
+// GetHistorySyncSettings returns the history import limits of a device
+// @Summary Get history sync settings
+// @Description Get whether chat history is imported after pairing, and how many days and messages per chat are kept
+// @Tags history-sync
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/history-sync/{client_id}/settings [get]
+// @Security BearerAuth
+func (h *HistorySyncHandler) GetHistorySyncSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetHistorySyncSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = wa.DefaultHistorySyncSettings(clientID)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateHistorySyncSettings changes the history import limits of a device
+// @Summary Update history sync settings
+// @Description Turn history import on or off and limit it by age in days and by messages per chat; applies to chunks received from now on
+// @Tags history-sync
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body HistorySyncSettingsRequest true "History sync settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/history-sync/{client_id}/settings [put]
+// @Security BearerAuth
+func (h *HistorySyncHandler) UpdateHistorySyncSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req HistorySyncSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.MaxDays < 1 || req.MaxMessagesPerChat < 1 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "max_days and max_messages_per_chat must be at least 1"})
+	}
+
+	settings, err := h.db.UpsertHistorySyncSettings(c.Request().Context(), db.UpsertHistorySyncSettingsParams{
+		DeviceID:           clientID,
+		IsEnabled:          req.IsEnabled,
+		MaxDays:            req.MaxDays,
+		MaxMessagesPerChat: req.MaxMessagesPerChat,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
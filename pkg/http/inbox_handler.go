@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,8 +14,10 @@ import (
 	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow/types"
 )
 
 type InboxHandler struct {
@@ -48,6 +51,13 @@ func recipientTypeFromJID(jid string) string {
 	return "individual"
 }
 
+// Response headers carrying the keyset cursors of a page. Bodies stay plain arrays so
+// existing clients keep working.
+const (
+	headerCursorBefore = "X-Cursor-Before"
+	headerCursorAfter  = "X-Cursor-After"
+)
+
 type SendInboxMessageRequest struct {
 	Text string `json:"text" validate:"required"`
 }
@@ -59,14 +69,23 @@ type SendNewMessageRequest struct {
 
 // GetThreads returns the list of message threads for a device.
 // @Summary List threads
-// @Description Get all message threads for a device
+// @Description Get the message threads of a device, pinned first and then by latest message. Page forwards with `after` and backwards with `before`.
 // @Tags inbox
 // @Accept json
 // @Produce json
 // @Param client_id path string true "Device ID"
+// @Param status query string false "Thread status (open, pending, resolved)"
+// @Param assignee query string false "Assignee filter: me, unassigned or a user ID"
+// @Param archived query bool false "Only archived (true) or unarchived (false) threads"
+// @Param label_id query string false "Only threads with this label"
+// @Param before query string false "Cursor from the X-Cursor-Before header; returns the threads listed before it"
+// @Param after query string false "Cursor from the X-Cursor-After header; returns the threads listed after it"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
 // @Success 200 {array} map[string]interface{}
+// @Header 200 {string} X-Cursor-Before "Cursor of the first thread in the page"
+// @Header 200 {string} X-Cursor-After "Cursor of the last thread in the page"
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /admin/inbox/{client_id}/threads [get]
 // @Security BearerAuth
@@ -82,10 +101,65 @@ func (h *InboxHandler) GetThreads(c echo.Context) error {
 	userID := getUserIDFromContext(c)
 	logger.Info("GetThreads: clientID=%s userID=%d", clientID, userID)
 
+	params := db.GetThreadsParams{
+		DeviceID:    clientID,
+		QueryLimit:  int32(limit),
+		QueryOffset: int32(offset),
+	}
+	if status := c.QueryParam("status"); status != "" {
+		if !wa.IsValidThreadStatus(status) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid status"})
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+	switch assignee := c.QueryParam("assignee"); assignee {
+	case "":
+	case "me":
+		params.AssignedUserID = pgtype.Int4{Int32: userID, Valid: true}
+	case "unassigned":
+		params.UnassignedOnly = true
+	default:
+		assigneeID, err := strconv.ParseInt(assignee, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid assignee"})
+		}
+		params.AssignedUserID = pgtype.Int4{Int32: int32(assigneeID), Valid: true}
+	}
+	if archived := c.QueryParam("archived"); archived != "" {
+		isArchived, err := strconv.ParseBool(archived)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid archived"})
+		}
+		params.Archived = pgtype.Bool{Bool: isArchived, Valid: true}
+	}
+	if labelID := c.QueryParam("label_id"); labelID != "" {
+		if err := params.LabelID.Scan(labelID); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid label_id"})
+		}
+	}
+	before, after := c.QueryParam("before"), c.QueryParam("after")
+	if before != "" && after != "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Use either before or after, not both"})
+	}
+	if after != "" {
+		var err error
+		params.CursorPinned, params.CursorAt, params.CursorID, err = decodeThreadCursor(after)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+	}
+	if before != "" {
+		var err error
+		params.BeforePinned, params.BeforeAt, params.BeforeID, err = decodeThreadCursor(before)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
-	device, err := h.deviceStore.GetDeviceByIDAndUserID(ctx, clientID, userID)
+	device, err := h.deviceStore.GetDeviceForMember(ctx, clientID, userID)
 	if err != nil && errors.Is(err, wa.ErrDeviceNotFound) {
 		logger.Error("Device not found: clientID=%s userID=%d error=%v", clientID, userID, err)
 		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Device not found"})
@@ -100,11 +174,7 @@ func (h *InboxHandler) GetThreads(c echo.Context) error {
 	}
 	logger.Info("Device found: id=%s user_id=%v", device.ID, device.UserID)
 
-	threads, err := h.db.GetThreads(ctx, db.GetThreadsParams{
-		DeviceID:    clientID,
-		QueryLimit:  int32(limit),
-		QueryOffset: int32(offset),
-	})
+	threads, err := h.db.GetThreads(ctx, params)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			logger.Error("Context deadline exceeded fetching threads: clientID=%s", clientID)
@@ -117,21 +187,38 @@ func (h *InboxHandler) GetThreads(c echo.Context) error {
 	if threads == nil {
 		threads = []db.GetThreadsRow{}
 	}
+	// Paging backwards walks the threads in reverse; flip the page into display order
+	if before != "" {
+		slices.Reverse(threads)
+	}
+
+	if len(threads) > 0 {
+		first, last := threads[0], threads[len(threads)-1]
+		c.Response().Header().Set(headerCursorBefore, encodeThreadCursor(first.IsPinned, first.LastMessageAt, first.ID))
+		c.Response().Header().Set(headerCursorAfter, encodeThreadCursor(last.IsPinned, last.LastMessageAt, last.ID))
+	}
 
 	return c.JSON(http.StatusOK, threads)
 }
 
 // GetThreadMessages returns the messages for a specific thread (identified by chat_jid).
 // @Summary List thread messages
-// @Description Get messages for a specific thread
+// @Description Get messages for a specific thread in chronological order. Without a cursor the newest
+// @Description page is returned; page backwards with `before` and forwards with `after`. Passing
+// @Description `offset` selects the legacy offset paging from the oldest message instead.
 // @Tags inbox
 // @Accept json
 // @Produce json
 // @Param client_id path string true "Device ID"
 // @Param chat_jid path string true "Chat JID"
+// @Param before query string false "Cursor from the X-Cursor-Before header; returns older messages"
+// @Param after query string false "Cursor from the X-Cursor-After header; returns newer messages"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
 // @Success 200 {array} map[string]interface{}
+// @Header 200 {string} X-Cursor-Before "Cursor of the oldest message in the page"
+// @Header 200 {string} X-Cursor-After "Cursor of the newest message in the page"
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /admin/inbox/{client_id}/threads/{chat_jid}/messages [get]
 // @Security BearerAuth
@@ -140,16 +227,20 @@ func (h *InboxHandler) GetThreadMessages(c echo.Context) error {
 	chatJID := decodeChatJID(c)
 	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
 	offset, _ := strconv.ParseInt(c.QueryParam("offset"), 10, 64)
+	before, after := c.QueryParam("before"), c.QueryParam("after")
 
 	if limit <= 0 {
 		limit = 50
 	}
+	if before != "" && after != "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Use either before or after, not both"})
+	}
 
 	userID := getUserIDFromContext(c)
 	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
 	defer cancel()
 
-	_, err := h.deviceStore.GetDeviceByIDAndUserID(ctx, clientID, userID)
+	_, err := h.deviceStore.GetDeviceForMember(ctx, clientID, userID)
 	if err != nil && errors.Is(err, wa.ErrDeviceNotFound) {
 		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Device not found"})
 	}
@@ -160,12 +251,20 @@ func (h *InboxHandler) GetThreadMessages(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
-	messages, err := h.db.GetThreadMessages(ctx, db.GetThreadMessagesParams{
-		DeviceID:  pgtype.Text{String: clientID, Valid: true},
-		Recipient: chatJID,
-		Limit:     int32(limit),
-		Offset:    int32(offset),
-	})
+	var messages []db.GetThreadMessagesRow
+	if c.QueryParams().Has("offset") && before == "" && after == "" {
+		messages, err = h.db.GetThreadMessages(ctx, db.GetThreadMessagesParams{
+			DeviceID:  pgtype.Text{String: clientID, Valid: true},
+			Recipient: chatJID,
+			Limit:     int32(limit),
+			Offset:    int32(offset),
+		})
+	} else {
+		messages, err = h.pageThreadMessages(ctx, clientID, chatJID, before, after, int32(limit))
+	}
+	if errors.Is(err, errInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return c.JSON(http.StatusRequestTimeout, ErrorResponse{Error: "Request timeout"})
@@ -177,9 +276,177 @@ func (h *InboxHandler) GetThreadMessages(c echo.Context) error {
 		messages = []db.GetThreadMessagesRow{}
 	}
 
+	if len(messages) > 0 {
+		first, last := messages[0], messages[len(messages)-1]
+		c.Response().Header().Set(headerCursorBefore, encodeMessageCursor(first.SentAt, first.ID))
+		c.Response().Header().Set(headerCursorAfter, encodeMessageCursor(last.SentAt, last.ID))
+	}
+
 	return c.JSON(http.StatusOK, messages)
 }
 
+// pageThreadMessages returns a keyset page of a thread in chronological order: the messages
+// right after the `after` cursor, right before the `before` cursor, or the newest ones.
+func (h *InboxHandler) pageThreadMessages(ctx context.Context, clientID, chatJID, before, after string, limit int32) ([]db.GetThreadMessagesRow, error) {
+	if after != "" {
+		sentAt, id, err := decodeMessageCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := h.db.GetThreadMessagesAfter(ctx, db.GetThreadMessagesAfterParams{
+			DeviceID:   pgtype.Text{String: clientID, Valid: true},
+			Recipient:  chatJID,
+			SentAt:     sentAt,
+			ID:         id,
+			QueryLimit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages := make([]db.GetThreadMessagesRow, len(rows))
+		for i, row := range rows {
+			messages[i] = db.GetThreadMessagesRow(row)
+		}
+		return messages, nil
+	}
+
+	// Without a cursor, page back from the end of the thread
+	sentAt := pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	var id pgtype.UUID
+	if before != "" {
+		var err error
+		if sentAt, id, err = decodeMessageCursor(before); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := h.db.GetThreadMessagesBefore(ctx, db.GetThreadMessagesBeforeParams{
+		DeviceID:   pgtype.Text{String: clientID, Valid: true},
+		Recipient:  chatJID,
+		SentAt:     sentAt,
+		ID:         id,
+		QueryLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The query walks backwards; flip the page into chronological order
+	messages := make([]db.GetThreadMessagesRow, len(rows))
+	for i, row := range rows {
+		messages[len(rows)-1-i] = db.GetThreadMessagesRow(row)
+	}
+	return messages, nil
+}
+
+// MessageChange is a change in the sync feed. Message fields are empty for deleted messages.
+type MessageChange struct {
+	db.GetMessageChangesRow
+	Reactions []db.MessageReaction `json:"reactions"`
+}
+
+type MessageChangesResponse struct {
+	Changes   []MessageChange `json:"changes"`
+	SyncToken string          `json:"sync_token"`
+	HasMore   bool            `json:"has_more"`
+}
+
+// GetChanges returns the messages created, updated or deleted since a sync token.
+// @Summary Sync message changes
+// @Description Get the messages that were created, updated (status, edits, reactions) or deleted since
+// @Description `since`, oldest change first. Without `since` no changes are returned, only the current
+// @Description token: take it before the initial load, then poll with the returned `sync_token`
+// @Description until `has_more` is false.
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param since query string false "Sync token from a previous response"
+// @Param limit query int false "Maximum number of changes (default 100, max 500)"
+// @Success 200 {object} MessageChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/changes [get]
+// @Security BearerAuth
+func (h *InboxHandler) GetChanges(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	ctx := c.Request().Context()
+	resp := MessageChangesResponse{Changes: []MessageChange{}}
+
+	since := c.QueryParam("since")
+	if since == "" {
+		latest, err := h.db.GetLatestMessageChangePosition(ctx, clientID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		resp.SyncToken = encodeSyncToken(latest.Xid, latest.Seq)
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	xid, seq, err := decodeSyncToken(since)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	// Fetch one extra row to tell whether another page follows
+	rows, err := h.db.GetMessageChanges(ctx, db.GetMessageChangesParams{
+		SinceXid:   xid,
+		SinceSeq:   seq,
+		DeviceID:   clientID,
+		QueryLimit: int32(limit + 1),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		resp.HasMore = true
+	}
+
+	var messageIDs []pgtype.UUID
+	for _, row := range rows {
+		if row.Change != "deleted" {
+			messageIDs = append(messageIDs, row.MessageID)
+		}
+	}
+
+	reactions := make(map[pgtype.UUID][]db.MessageReaction)
+	if len(messageIDs) > 0 {
+		all, err := h.db.GetMessageReactions(ctx, messageIDs)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		for _, reaction := range all {
+			reactions[reaction.MessageID] = append(reactions[reaction.MessageID], reaction)
+		}
+	}
+
+	for _, row := range rows {
+		change := MessageChange{GetMessageChangesRow: row, Reactions: reactions[row.MessageID]}
+		if change.Reactions == nil {
+			change.Reactions = []db.MessageReaction{}
+		}
+		resp.Changes = append(resp.Changes, change)
+		xid, seq = row.Xid, row.Seq
+	}
+	resp.SyncToken = encodeSyncToken(xid, seq)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // SendMessage sends a text message within an existing thread.
 // @Summary Send message to thread
 // @Description Send a text message to an existing thread
@@ -265,16 +532,21 @@ func (h *InboxHandler) SendNewMessage(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 	}
 
-	waMessageID, err := h.waClient.SendMessage(clientID, req.To, req.Text)
+	to, err := wa.NormalizeRecipient(req.To)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	waMessageID, err := h.waClient.SendMessage(clientID, to, req.Text)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 	}
 
-	recipientType := recipientTypeFromJID(req.To)
+	recipientType := recipientTypeFromJID(to)
 
 	msg, err := h.db.LogOutgoingMessage(c.Request().Context(), db.LogOutgoingMessageParams{
 		DeviceID:      pgtype.Text{String: clientID, Valid: true},
-		Recipient:     req.To,
+		Recipient:     to,
 		RecipientType: pgtype.Text{String: recipientType, Valid: true},
 		MessageType:   pgtype.Text{String: "text", Valid: true},
 		Content:       req.Text,
@@ -287,7 +559,7 @@ func (h *InboxHandler) SendNewMessage(c echo.Context) error {
 	// Upsert thread for the new conversation
 	_ = h.db.UpsertThread(c.Request().Context(), db.UpsertThreadParams{
 		DeviceID:    clientID,
-		ChatJid:     req.To,
+		ChatJid:     to,
 		ChatType:    recipientType,
 		Content:     req.Text,
 		MessageType: "text",
@@ -300,7 +572,8 @@ func (h *InboxHandler) SendNewMessage(c echo.Context) error {
 
 // MarkRead marks all incoming messages in a thread as read and resets unread count.
 // @Summary Mark thread as read
-// @Description Mark all messages in a thread as read
+// @Description Mark all messages in a thread as read. Unless read receipts are turned off for the
+// @Description device, the senders get read receipts (blue ticks) on WhatsApp.
 // @Tags inbox
 // @Accept json
 // @Produce json
@@ -308,13 +581,27 @@ func (h *InboxHandler) SendNewMessage(c echo.Context) error {
 // @Param chat_jid path string true "Chat JID"
 // @Success 200 {object} GenericResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Router /admin/inbox/{client_id}/threads/{chat_jid}/read [post]
 // @Security BearerAuth
 func (h *InboxHandler) MarkRead(c echo.Context) error {
-	clientID := c.Param("client_id")
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
 	chatJID := decodeChatJID(c)
+	ctx := c.Request().Context()
+
+	// Collect the unread messages before they are marked, to send receipts for them
+	unread, err := h.db.GetUnreadIncomingMessages(ctx, db.GetUnreadIncomingMessagesParams{
+		DeviceID:  pgtype.Text{String: clientID, Valid: true},
+		Recipient: chatJID,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
 
-	err := h.db.MarkConversationRead(c.Request().Context(), db.MarkConversationReadParams{
+	err = h.db.MarkConversationRead(ctx, db.MarkConversationReadParams{
 		DeviceID:  pgtype.Text{String: clientID, Valid: true},
 		Recipient: chatJID,
 	})
@@ -323,14 +610,172 @@ func (h *InboxHandler) MarkRead(c echo.Context) error {
 	}
 
 	// Reset thread unread count
-	_ = h.db.ResetThreadUnread(c.Request().Context(), db.ResetThreadUnreadParams{
+	_ = h.db.ResetThreadUnread(ctx, db.ResetThreadUnreadParams{
 		DeviceID: clientID,
 		ChatJid:  chatJID,
 	})
 
+	if len(unread) > 0 && h.readReceiptsEnabled(ctx, clientID) {
+		h.sendReadReceipts(clientID, chatJID, unread)
+	}
+
 	return c.JSON(http.StatusOK, GenericResponse{Message: "Conversation marked as read"})
 }
 
+func (h *InboxHandler) readReceiptsEnabled(ctx context.Context, clientID string) bool {
+	settings, err := h.db.GetReadReceiptSettings(ctx, clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		logger.Error("Failed to load read receipt settings for device %s: %v", clientID, err)
+		return false
+	}
+	return settings.IsEnabled
+}
+
+// sendReadReceipts sends the read receipts of a chat, one per sender as WhatsApp requires.
+// The messages are already read in Kontak, so failures are only logged.
+func (h *InboxHandler) sendReadReceipts(clientID, chatJID string, unread []db.GetUnreadIncomingMessagesRow) {
+	chat, err := types.ParseJID(chatJID)
+	if err != nil {
+		logger.Warn("Not sending read receipts to invalid chat %s: %v", chatJID, err)
+		return
+	}
+
+	var senders []types.JID
+	bySender := make(map[types.JID][]types.MessageID)
+	for _, msg := range unread {
+		sender := chat
+		if parsed, err := types.ParseJID(msg.SenderJid.String); msg.SenderJid.Valid && err == nil {
+			sender = parsed.ToNonAD()
+		}
+		if _, ok := bySender[sender]; !ok {
+			senders = append(senders, sender)
+		}
+		bySender[sender] = append(bySender[sender], msg.WaMessageID.String)
+	}
+
+	for _, sender := range senders {
+		if err := h.waClient.MarkRead(clientID, chat, sender, bySender[sender]); err != nil {
+			logger.Warn("Failed to send read receipts to %s in chat %s for device %s: %v", sender, chatJID, clientID, err)
+		}
+	}
+}
+
+type ReadReceiptSettingsRequest struct {
+	IsEnabled bool `json:"is_enabled"`
+}
+
+// GetReadReceiptSettings returns whether marking chats as read sends read receipts
+// @Summary Get read receipt settings
+// @Description Get whether marking a chat as read sends read receipts to WhatsApp
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/read-receipts [get]
+// @Security BearerAuth
+func (h *InboxHandler) GetReadReceiptSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetReadReceiptSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = db.ReadReceiptSetting{DeviceID: clientID, IsEnabled: true}
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateReadReceiptSettings turns read receipts on or off for a device
+// @Summary Update read receipt settings
+// @Description Turn read receipts on or off; when off, marking a chat as read only updates Kontak
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body ReadReceiptSettingsRequest true "Read receipt settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/read-receipts [put]
+// @Security BearerAuth
+func (h *InboxHandler) UpdateReadReceiptSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ReadReceiptSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.UpsertReadReceiptSettings(c.Request().Context(), db.UpsertReadReceiptSettingsParams{
+		DeviceID:  clientID,
+		IsEnabled: req.IsEnabled,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+type ChatPresenceRequest struct {
+	State string `json:"state" validate:"required"`
+}
+
+// SendChatPresence shows the device as typing or recording in a thread
+// @Summary Send typing indicator
+// @Description Show the device as typing (composing), recording audio (recording) or idle (paused) in a chat
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body ChatPresenceRequest true "Chat presence"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/presence [post]
+// @Security BearerAuth
+func (h *InboxHandler) SendChatPresence(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ChatPresenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if !wa.IsValidChatState(req.State) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid state"})
+	}
+
+	chat, err := types.ParseJID(decodeChatJID(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid chat JID"})
+	}
+
+	if err := h.waClient.SendChatPresence(clientID, chat, req.State); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Chat presence sent"})
+}
+
 // SendMediaMessage sends a media file within an existing thread.
 // @Summary Send media message
 // @Description Send a media file (image, video, audio, document) to an existing thread
@@ -488,3 +933,160 @@ func (h *InboxHandler) ScheduleMessage(c echo.Context) error {
 
 	return c.JSON(http.StatusCreated, job)
 }
+
+type PinThreadRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+type ArchiveThreadRequest struct {
+	Archived bool `json:"archived"`
+}
+
+type MuteThreadRequest struct {
+	Muted bool `json:"muted"`
+	// DurationSeconds limits the mute; 0 mutes the thread indefinitely.
+	DurationSeconds int64 `json:"duration_seconds"`
+}
+
+// PinThread pins or unpins a thread in Kontak and on WhatsApp.
+// @Summary Pin thread
+// @Description Pin or unpin a thread; pinned threads are listed first
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body PinThreadRequest true "Pin state"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/pin [put]
+// @Security BearerAuth
+func (h *InboxHandler) PinThread(c echo.Context) error {
+	var req PinThreadRequest
+	thread, chat, status, err := h.bindThreadState(c, &req)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.SetThreadPinned(c.Request().Context(), db.SetThreadPinnedParams{
+		DeviceID: thread.DeviceID,
+		ChatJid:  thread.ChatJid,
+		IsPinned: req.Pinned,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(thread.DeviceID, "pin state of "+thread.ChatJid,
+		h.waClient.PinChat(thread.DeviceID, chat, req.Pinned))
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Thread pin state updated"})
+}
+
+// ArchiveThread archives or unarchives a thread in Kontak and on WhatsApp.
+// @Summary Archive thread
+// @Description Archive or unarchive a thread; archiving also unpins it
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body ArchiveThreadRequest true "Archive state"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/archive [put]
+// @Security BearerAuth
+func (h *InboxHandler) ArchiveThread(c echo.Context) error {
+	var req ArchiveThreadRequest
+	thread, chat, status, err := h.bindThreadState(c, &req)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.SetThreadArchived(c.Request().Context(), db.SetThreadArchivedParams{
+		DeviceID:   thread.DeviceID,
+		ChatJid:    thread.ChatJid,
+		IsArchived: req.Archived,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(thread.DeviceID, "archive state of "+thread.ChatJid,
+		h.waClient.ArchiveChat(thread.DeviceID, chat, req.Archived, thread.LastMessageAt.Time))
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Thread archive state updated"})
+}
+
+// MuteThread mutes or unmutes a thread in Kontak and on WhatsApp.
+// @Summary Mute thread
+// @Description Mute a thread for a duration (0 = indefinitely) or unmute it
+// @Tags inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body MuteThreadRequest true "Mute state"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/mute [put]
+// @Security BearerAuth
+func (h *InboxHandler) MuteThread(c echo.Context) error {
+	var req MuteThreadRequest
+	thread, chat, status, err := h.bindThreadState(c, &req)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	if req.DurationSeconds < 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "duration_seconds must not be negative"})
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	mutedUntil := pgtype.Timestamptz{}
+	if req.Muted && duration > 0 {
+		mutedUntil = pgtype.Timestamptz{Time: time.Now().Add(duration), Valid: true}
+	}
+
+	if err := h.db.SetThreadMuted(c.Request().Context(), db.SetThreadMutedParams{
+		DeviceID:   thread.DeviceID,
+		ChatJid:    thread.ChatJid,
+		IsMuted:    req.Muted,
+		MutedUntil: mutedUntil,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(thread.DeviceID, "mute state of "+thread.ChatJid,
+		h.waClient.MuteChat(thread.DeviceID, chat, req.Muted, duration))
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Thread mute state updated"})
+}
+
+// bindThreadState authorizes the caller, binds the request body and loads the thread it targets.
+func (h *InboxHandler) bindThreadState(c echo.Context, req interface{}) (db.MessageThread, types.JID, int, error) {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return db.MessageThread{}, types.JID{}, status, err
+	}
+
+	if err := c.Bind(req); err != nil {
+		return db.MessageThread{}, types.JID{}, http.StatusBadRequest, err
+	}
+
+	chatJID := decodeChatJID(c)
+	chat, err := types.ParseJID(chatJID)
+	if err != nil {
+		return db.MessageThread{}, types.JID{}, http.StatusBadRequest, errors.New("Invalid chat JID")
+	}
+
+	thread, status, err := findThread(c, h.db, clientID, chatJID)
+	if err != nil {
+		return db.MessageThread{}, types.JID{}, status, err
+	}
+
+	return thread, chat, http.StatusOK, nil
+}
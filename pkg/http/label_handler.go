@@ -0,0 +1,325 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// LabelHandler manages chat labels. Labels are mirrored to the WhatsApp Business labels of
+// the device when it is connected; accounts without label support keep them in Kontak only.
+type LabelHandler struct {
+	db          db.Querier
+	waClient    *wa.WhatsappClient
+	deviceStore *wa.DeviceStore
+}
+
+// NewLabelHandler creates a new LabelHandler
+func NewLabelHandler(db db.Querier, waClient *wa.WhatsappClient, deviceStore *wa.DeviceStore) *LabelHandler {
+	return &LabelHandler{db: db, waClient: waClient, deviceStore: deviceStore}
+}
+
+type LabelRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Color int32  `json:"color"`
+}
+
+type ThreadLabelsRequest struct {
+	LabelIDs []string `json:"label_ids"`
+}
+
+// GetLabels returns the labels of a device
+// @Summary List labels
+// @Description Get the chat labels of a device
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/labels/{client_id} [get]
+// @Security BearerAuth
+func (h *LabelHandler) GetLabels(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	labels, err := h.db.GetLabels(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if labels == nil {
+		labels = []db.Label{}
+	}
+
+	return c.JSON(http.StatusOK, labels)
+}
+
+// CreateLabel creates a label
+// @Summary Create label
+// @Description Create a chat label and add it to WhatsApp
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body LabelRequest true "Label"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/labels/{client_id} [post]
+// @Security BearerAuth
+func (h *LabelHandler) CreateLabel(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	req, err := bindLabelRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	label, err := h.db.CreateLabel(c.Request().Context(), db.CreateLabelParams{
+		DeviceID: clientID,
+		Name:     req.Name,
+		Color:    req.Color,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(clientID, "label "+label.WaLabelID,
+		h.waClient.EditLabel(clientID, label.WaLabelID, label.Name, label.Color, false))
+
+	return c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel renames or recolors a label
+// @Summary Update label
+// @Description Rename or recolor a chat label
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Label ID"
+// @Param request body LabelRequest true "Label"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/labels/{client_id}/{id} [put]
+// @Security BearerAuth
+func (h *LabelHandler) UpdateLabel(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var labelID pgtype.UUID
+	if err := labelID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid label ID"})
+	}
+
+	req, err := bindLabelRequest(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	label, err := h.db.UpdateLabel(c.Request().Context(), db.UpdateLabelParams{
+		ID:       labelID,
+		DeviceID: clientID,
+		Name:     req.Name,
+		Color:    req.Color,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Label not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(clientID, "label "+label.WaLabelID,
+		h.waClient.EditLabel(clientID, label.WaLabelID, label.Name, label.Color, false))
+
+	return c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel deletes a label and removes it from every thread
+// @Summary Delete label
+// @Description Delete a chat label from Kontak and WhatsApp
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param id path string true "Label ID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/labels/{client_id}/{id} [delete]
+// @Security BearerAuth
+func (h *LabelHandler) DeleteLabel(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var labelID pgtype.UUID
+	if err := labelID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid label ID"})
+	}
+
+	ctx := c.Request().Context()
+	label, err := h.db.GetLabel(ctx, db.GetLabelParams{ID: labelID, DeviceID: clientID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Label not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.DeleteLabel(ctx, db.DeleteLabelParams{ID: labelID, DeviceID: clientID}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	logAppStateError(clientID, "label "+label.WaLabelID,
+		h.waClient.EditLabel(clientID, label.WaLabelID, label.Name, label.Color, true))
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Label deleted successfully"})
+}
+
+// SetThreadLabels replaces the labels of a thread
+// @Summary Set thread labels
+// @Description Replace the labels of a thread; an empty list removes them all
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body ThreadLabelsRequest true "Labels"
+// @Success 200 {array} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/labels [put]
+// @Security BearerAuth
+func (h *LabelHandler) SetThreadLabels(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ThreadLabelsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	chatJID := decodeChatJID(c)
+	chat, err := types.ParseJID(chatJID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid chat JID"})
+	}
+
+	ctx := c.Request().Context()
+	if _, status, err := findThread(c, h.db, clientID, chatJID); err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	wanted := make(map[pgtype.UUID]db.Label, len(req.LabelIDs))
+	for _, id := range req.LabelIDs {
+		var labelID pgtype.UUID
+		if err := labelID.Scan(id); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid label ID: " + id})
+		}
+		label, err := h.db.GetLabel(ctx, db.GetLabelParams{ID: labelID, DeviceID: clientID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Label not found: " + id})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		wanted[labelID] = label
+	}
+
+	current, err := h.db.GetThreadLabels(ctx, db.GetThreadLabelsParams{DeviceID: clientID, ChatJid: chatJID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	for _, label := range current {
+		if _, keep := wanted[label.ID]; keep {
+			delete(wanted, label.ID)
+			continue
+		}
+		if err := h.db.RemoveThreadLabel(ctx, db.RemoveThreadLabelParams{
+			DeviceID: clientID,
+			ChatJid:  chatJID,
+			LabelID:  label.ID,
+		}); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		logAppStateError(clientID, "label "+label.WaLabelID+" of "+chatJID,
+			h.waClient.LabelChat(clientID, chat, label.WaLabelID, false))
+	}
+
+	for _, label := range wanted {
+		if err := h.db.AddThreadLabel(ctx, db.AddThreadLabelParams{
+			DeviceID: clientID,
+			ChatJid:  chatJID,
+			LabelID:  label.ID,
+		}); err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		logAppStateError(clientID, "label "+label.WaLabelID+" of "+chatJID,
+			h.waClient.LabelChat(clientID, chat, label.WaLabelID, true))
+	}
+
+	labels, err := h.db.GetThreadLabels(ctx, db.GetThreadLabelsParams{DeviceID: clientID, ChatJid: chatJID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if labels == nil {
+		labels = []db.Label{}
+	}
+
+	return c.JSON(http.StatusOK, labels)
+}
+
+func bindLabelRequest(c echo.Context) (LabelRequest, error) {
+	var req LabelRequest
+	if err := c.Bind(&req); err != nil {
+		return req, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := c.Validate(req); err != nil {
+		return req, err
+	}
+	if len(req.Name) > 100 {
+		return req, errors.New("name must be at most 100 characters")
+	}
+	if req.Color < 0 {
+		return req, errors.New("color must not be negative")
+	}
+	return req, nil
+}
+
+// logAppStateError reports a change that was saved in Kontak but could not be pushed to
+// WhatsApp, typically because the device is offline or the account has no label support.
+func logAppStateError(clientID, what string, err error) {
+	if err != nil {
+		logger.Warn("Failed to sync %s to WhatsApp for device %s: %v", what, clientID, err)
+	}
+}
@@ -0,0 +1,467 @@
+package http
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/storage"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// mediaTypes are the message types that carry downloadable media
+var mediaTypes = []string{"image", "video", "document", "audio"}
+
+// mediaRedirectExpiry is how long the storage URL a media request is redirected to stays valid.
+const mediaRedirectExpiry = 5 * time.Minute
+
+// MediaHandler serves the media of inbox messages and its download settings
+type MediaHandler struct {
+	db           db.Querier
+	waClient     *wa.WhatsappClient
+	deviceStore  *wa.DeviceStore
+	mediaStorage storage.Storage
+	urlSigner    *storage.URLSigner
+	userQuota    int64
+}
+
+// NewMediaHandler creates a new MediaHandler. userQuota is the media quota of a user in bytes, 0 for none.
+func NewMediaHandler(db db.Querier, waClient *wa.WhatsappClient, deviceStore *wa.DeviceStore, mediaStorage storage.Storage, urlSigner *storage.URLSigner, userQuota int64) *MediaHandler {
+	return &MediaHandler{db: db, waClient: waClient, deviceStore: deviceStore, mediaStorage: mediaStorage, urlSigner: urlSigner, userQuota: userQuota}
+}
+
+type MediaDownloadSettingsRequest struct {
+	AutoDownloadTypes []string `json:"auto_download_types"`
+}
+
+// MediaRetentionSettingsRequest sets how many days media of each type is kept and how many
+// bytes a device may store. Leave a field null to keep media forever or store without a limit.
+type MediaRetentionSettingsRequest struct {
+	ImageRetentionDays    *int32 `json:"image_retention_days"`
+	VideoRetentionDays    *int32 `json:"video_retention_days"`
+	DocumentRetentionDays *int32 `json:"document_retention_days"`
+	AudioRetentionDays    *int32 `json:"audio_retention_days"`
+	QuotaBytes            *int64 `json:"quota_bytes"`
+}
+
+type MediaUsageResponse struct {
+	DeviceID       string                      `json:"device_id"`
+	UsedBytes      int64                       `json:"used_bytes"`
+	QuotaBytes     *int64                      `json:"quota_bytes"`
+	ByType         []db.GetDeviceMediaUsageRow `json:"by_type"`
+	UserUsedBytes  int64                       `json:"user_used_bytes"`
+	UserQuotaBytes *int64                      `json:"user_quota_bytes"`
+}
+
+type MessageMediaResponse struct {
+	MediaURL string `json:"media_url,omitempty"`
+	Status   string `json:"status"`
+}
+
+// ServeMedia serves a stored media object. The request needs either a signed URL that has
+// not expired or a login of the owner or a team member of a device the media belongs to.
+// @Summary Get media file
+// @Description Download a stored media object. Pass a valid expires and signature pair from a signed media URL,
+// @Description or a bearer token of a user with access to a device that received the media.
+// @Tags media
+// @Produce octet-stream
+// @Param key path string true "Media key"
+// @Param expires query int false "Expiry of a signed URL (unix seconds)"
+// @Param signature query string false "Signature of a signed URL"
+// @Success 200 {file} file
+// @Success 302 {string} string "Redirect to a temporary storage URL"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/media/{key} [get]
+func (h *MediaHandler) ServeMedia(c echo.Context) error {
+	key := c.Param("key")
+	if !storage.ValidKey(key) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Media not found"})
+	}
+	ctx := c.Request().Context()
+
+	if signature := c.QueryParam("signature"); signature != "" {
+		if !h.urlSigner.Verify(wa.MediaURLPrefix+key, c.QueryParam("expires"), signature) {
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: "Invalid or expired media URL"})
+		}
+	} else {
+		status, err := h.authorizeMedia(c, key)
+		if err != nil {
+			return c.JSON(status, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	if presigner, ok := h.mediaStorage.(storage.Presigner); ok {
+		url, err := presigner.PresignGet(ctx, key, mediaRedirectExpiry)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return c.Redirect(http.StatusFound, url)
+	}
+
+	r, err := h.mediaStorage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Media not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	defer r.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, max-age=86400")
+	return c.Stream(http.StatusOK, contentType, r)
+}
+
+// authorizeMedia checks that the logged in user has access to a device the media belongs to.
+func (h *MediaHandler) authorizeMedia(c echo.Context, key string) (int, error) {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return http.StatusUnauthorized, errors.New("Unauthorized")
+	}
+
+	ctx := c.Request().Context()
+	devices, err := h.db.GetMediaObjectDevices(ctx, key)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, deviceID := range devices {
+		_, err := h.deviceStore.GetDeviceForMember(ctx, deviceID, userID)
+		if err == nil {
+			return http.StatusOK, nil
+		}
+		if !errors.Is(err, wa.ErrDeviceNotFound) {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return http.StatusNotFound, errors.New("Media not found")
+}
+
+// GetMessageMedia returns the media URL of a message, downloading the media on first access
+// @Summary Get message media
+// @Description Get the URL of the media of a message. Media that was not downloaded yet is downloaded now.
+// @Description Media that expired on the WhatsApp servers is re-requested from the phone; the response is then
+// @Description 202 with status retry_requested and the media shows up in the inbox change feed once downloaded.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param message_id path string true "Message ID"
+// @Success 200 {object} MessageMediaResponse
+// @Success 202 {object} MessageMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/media/{client_id}/messages/{message_id} [get]
+// @Security BearerAuth
+func (h *MediaHandler) GetMessageMedia(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var messageID pgtype.UUID
+	if err := messageID.Scan(c.Param("message_id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid message ID"})
+	}
+
+	ctx := c.Request().Context()
+	media, err := h.db.GetMessageMedia(ctx, messageID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && media.DeviceID.String != clientID) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Message has no downloadable media"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if media.Status == wa.MediaStatusFailed {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Media is no longer available"})
+	}
+	if media.Status == wa.MediaStatusDeleted {
+		return c.JSON(http.StatusGone, ErrorResponse{Error: wa.ErrMediaDeleted.Error()})
+	}
+
+	mediaURL, err := h.waClient.FetchMessageMedia(ctx, messageID)
+	if errors.Is(err, wa.ErrMediaRetryRequested) {
+		return c.JSON(http.StatusAccepted, MessageMediaResponse{Status: wa.MediaStatusRetryRequested})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, MessageMediaResponse{MediaURL: mediaURL, Status: wa.MediaStatusDownloaded})
+}
+
+// GetMediaDownloadSettings returns which media types a device downloads on arrival
+// @Summary Get media download settings
+// @Description Get the media types downloaded as soon as a message arrives; other media is downloaded when first opened
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/media/{client_id}/settings [get]
+// @Security BearerAuth
+func (h *MediaHandler) GetMediaDownloadSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetMediaDownloadSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = db.MediaDownloadSetting{DeviceID: clientID, AutoDownloadTypes: wa.DefaultAutoDownloadTypes}
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMediaDownloadSettings changes which media types a device downloads on arrival
+// @Summary Update media download settings
+// @Description Set the media types (image, video, document, audio) downloaded as soon as a message arrives.
+// @Description Media of other types is downloaded when first opened. Chat history media is always downloaded when first opened.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body MediaDownloadSettingsRequest true "Media download settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/media/{client_id}/settings [put]
+// @Security BearerAuth
+func (h *MediaHandler) UpdateMediaDownloadSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req MediaDownloadSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	autoTypes := []string{}
+	for _, t := range req.AutoDownloadTypes {
+		if !slices.Contains(mediaTypes, t) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown media type: " + t})
+		}
+		if !slices.Contains(autoTypes, t) {
+			autoTypes = append(autoTypes, t)
+		}
+	}
+
+	settings, err := h.db.UpsertMediaDownloadSettings(c.Request().Context(), db.UpsertMediaDownloadSettingsParams{
+		DeviceID:          clientID,
+		AutoDownloadTypes: autoTypes,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// GetMediaRetentionSettings returns the media retention rules and storage quota of a device
+// @Summary Get media retention settings
+// @Description Get how many days media of each type is kept and how many bytes the device may store. Null means no limit.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/media/{client_id}/retention [get]
+// @Security BearerAuth
+func (h *MediaHandler) GetMediaRetentionSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetMediaRetentionSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = db.MediaRetentionSetting{DeviceID: clientID}
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMediaRetentionSettings changes the media retention rules and storage quota of a device
+// @Summary Update media retention settings
+// @Description Set how many days media of each type is kept and how many bytes the device may store.
+// @Description A background job removes older media, and the oldest media once the device is over its quota.
+// @Description Messages of removed media stay in the inbox without their media and with media_deleted_at set.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body MediaRetentionSettingsRequest true "Media retention settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/media/{client_id}/retention [put]
+// @Security BearerAuth
+func (h *MediaHandler) UpdateMediaRetentionSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req MediaRetentionSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	for _, days := range []*int32{req.ImageRetentionDays, req.VideoRetentionDays, req.DocumentRetentionDays, req.AudioRetentionDays} {
+		if days != nil && *days < 1 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Retention must be at least 1 day"})
+		}
+	}
+	params := db.UpsertMediaRetentionSettingsParams{
+		DeviceID:              clientID,
+		ImageRetentionDays:    retentionDays(req.ImageRetentionDays),
+		VideoRetentionDays:    retentionDays(req.VideoRetentionDays),
+		DocumentRetentionDays: retentionDays(req.DocumentRetentionDays),
+		AudioRetentionDays:    retentionDays(req.AudioRetentionDays),
+	}
+	if req.QuotaBytes != nil {
+		if *req.QuotaBytes < 1 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Quota must be at least 1 byte"})
+		}
+		params.QuotaBytes = pgtype.Int8{Int64: *req.QuotaBytes, Valid: true}
+	}
+
+	settings, err := h.db.UpsertMediaRetentionSettings(c.Request().Context(), params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+func retentionDays(days *int32) pgtype.Int4 {
+	if days == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *days, Valid: true}
+}
+
+// GetMediaUsage returns how much media a device and its owner store
+// @Summary Get media storage usage
+// @Description Get the bytes of media stored by the device, per media type, and by all devices of the user,
+// @Description together with the quotas that apply. Null quotas mean no limit.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} MediaUsageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/media/{client_id}/usage [get]
+// @Security BearerAuth
+func (h *MediaHandler) GetMediaUsage(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	ctx := c.Request().Context()
+
+	byType, err := h.db.GetDeviceMediaUsage(ctx, clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	userUsed, err := h.db.GetUserMediaUsage(ctx, pgtype.Int4{Int32: getUserIDFromContext(c), Valid: true})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	resp := MediaUsageResponse{
+		DeviceID:      clientID,
+		ByType:        byType,
+		UserUsedBytes: userUsed,
+	}
+	if resp.ByType == nil {
+		resp.ByType = []db.GetDeviceMediaUsageRow{}
+	}
+	for _, t := range byType {
+		resp.UsedBytes += t.SizeBytes
+	}
+
+	settings, err := h.db.GetMediaRetentionSettings(ctx, clientID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if settings.QuotaBytes.Valid {
+		resp.QuotaBytes = &settings.QuotaBytes.Int64
+	}
+	if h.userQuota > 0 {
+		resp.UserQuotaBytes = &h.userQuota
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetMediaCleanups lists what the media janitor removed from a device
+// @Summary List media cleanups
+// @Description List the most recent cleanups of the device's media by the retention rules and storage quotas, newest first
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param limit query int false "Maximum number of cleanups (default 20, max 100)"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/media/{client_id}/cleanups [get]
+// @Security BearerAuth
+func (h *MediaHandler) GetMediaCleanups(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	runs, err := h.db.ListMediaCleanupRuns(c.Request().Context(), db.ListMediaCleanupRunsParams{
+		DeviceID: clientID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if runs == nil {
+		runs = []db.MediaCleanupRun{}
+	}
+
+	return c.JSON(http.StatusOK, runs)
+}
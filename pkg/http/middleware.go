@@ -105,3 +105,18 @@ func Jwt() echo.MiddlewareFunc {
 		},
 	})
 }
+
+// SignedOrJwt lets requests with a signed URL through, leaving the signature check to the
+// handler, and requires a JWT for all other requests.
+func SignedOrJwt() echo.MiddlewareFunc {
+	jwtAuth := Jwt()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJwt := jwtAuth(JwtUserIDMiddleware()(next))
+		return func(c echo.Context) error {
+			if c.QueryParam("signature") != "" {
+				return next(c)
+			}
+			return withJwt(c)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/labstack/echo/v4"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// NewsletterHandler publishes to WhatsApp Channels (newsletters) and reports how posts perform.
+type NewsletterHandler struct {
+	device   *wa.DeviceStore
+	waClient *wa.WhatsappClient
+}
+
+func NewNewsletterHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient) *NewsletterHandler {
+	return &NewsletterHandler{
+		device:   device,
+		waClient: waClient,
+	}
+}
+
+type CreateNewsletterRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=2048"`
+}
+
+type NewsletterPostRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+type NewsletterPostResponse struct {
+	MessageID string `json:"message_id"`
+	// ServerID identifies the post in the view and reaction counts
+	ServerID int `json:"server_id"`
+}
+
+type NewsletterLiveUpdatesResponse struct {
+	ExpiresIn int `json:"expires_in"`
+}
+
+// newsletterJIDParam parses the newsletter_jid path parameter.
+func newsletterJIDParam(c echo.Context) (types.JID, error) {
+	raw, err := url.PathUnescape(c.Param("newsletter_jid"))
+	if err != nil {
+		return types.JID{}, errors.New("Invalid channel JID")
+	}
+	jid, err := types.ParseJID(raw)
+	if err != nil || jid.Server != types.NewsletterServer {
+		return types.JID{}, errors.New("Invalid channel JID")
+	}
+	return jid, nil
+}
+
+// CreateNewsletter creates a channel
+// @Summary Create channel
+// @Description Create a WhatsApp Channel owned by the device
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body CreateNewsletterRequest true "Channel"
+// @Success 201 {object} wa.Newsletter
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id} [post]
+// @Security BearerAuth
+func (h *NewsletterHandler) CreateNewsletter(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req CreateNewsletterRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	newsletter, err := h.waClient.CreateNewsletter(clientID, req.Name, req.Description)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, newsletter)
+}
+
+// GetNewsletters lists the channels of a device
+// @Summary List channels
+// @Description List the channels the device owns, administers or follows. Filter with role (owner, admin or subscriber).
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param role query string false "Only channels where the device has this role"
+// @Success 200 {array} wa.Newsletter
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id} [get]
+// @Security BearerAuth
+func (h *NewsletterHandler) GetNewsletters(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	role := c.QueryParam("role")
+	switch types.NewsletterRole(role) {
+	case "", types.NewsletterRoleOwner, types.NewsletterRoleAdmin, types.NewsletterRoleSubscriber:
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid role"})
+	}
+
+	newsletters, err := h.waClient.GetNewsletters(clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if role != "" {
+		filtered := make([]wa.Newsletter, 0, len(newsletters))
+		for _, n := range newsletters {
+			if n.Role == role {
+				filtered = append(filtered, n)
+			}
+		}
+		newsletters = filtered
+	}
+
+	return c.JSON(http.StatusOK, newsletters)
+}
+
+// GetNewsletter returns a channel
+// @Summary Get channel
+// @Description Get a channel with its current follower count
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param newsletter_jid path string true "Channel JID"
+// @Success 200 {object} wa.Newsletter
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id}/{newsletter_jid} [get]
+// @Security BearerAuth
+func (h *NewsletterHandler) GetNewsletter(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	jid, err := newsletterJIDParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	newsletter, err := h.waClient.GetNewsletter(clientID, jid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newsletter)
+}
+
+// GetNewsletterPosts lists the latest posts of a channel with their counts
+// @Summary List channel posts
+// @Description List the latest posts of a channel, oldest first, with their view and reaction counts.
+// @Description Page back with before, the server_id of the oldest post seen.
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param newsletter_jid path string true "Channel JID"
+// @Param count query int false "Number of posts (default 20, max 100)"
+// @Param before query int false "Only posts before this server ID"
+// @Success 200 {array} wa.NewsletterPost
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id}/{newsletter_jid}/posts [get]
+// @Security BearerAuth
+func (h *NewsletterHandler) GetNewsletterPosts(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	jid, err := newsletterJIDParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	count, _ := strconv.Atoi(c.QueryParam("count"))
+	before, _ := strconv.Atoi(c.QueryParam("before"))
+	if count <= 0 {
+		count = 20
+	}
+	if count > 100 {
+		count = 100
+	}
+	if before < 0 {
+		before = 0
+	}
+
+	posts, err := h.waClient.GetNewsletterPosts(clientID, jid, count, before)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, posts)
+}
+
+// PostNewsletterText posts a text update to a channel
+// @Summary Post to channel
+// @Description Post a text update to a channel the device owns or administers
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param newsletter_jid path string true "Channel JID"
+// @Param request body NewsletterPostRequest true "Post"
+// @Success 200 {object} NewsletterPostResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id}/{newsletter_jid}/posts [post]
+// @Security BearerAuth
+func (h *NewsletterHandler) PostNewsletterText(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	jid, err := newsletterJIDParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	var req NewsletterPostRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	messageID, serverID, err := h.waClient.PostNewsletterText(clientID, jid, req.Text)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, NewsletterPostResponse{MessageID: messageID, ServerID: serverID})
+}
+
+// PostNewsletterMedia posts a media update to a channel
+// @Summary Post media to channel
+// @Description Post an image, video, audio or document with an optional caption to a channel the device owns or administers
+// @Tags newsletters
+// @Accept multipart/form-data
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param newsletter_jid path string true "Channel JID"
+// @Param file formData file true "Media file"
+// @Param caption formData string false "Caption"
+// @Success 200 {object} NewsletterPostResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id}/{newsletter_jid}/posts/media [post]
+// @Security BearerAuth
+func (h *NewsletterHandler) PostNewsletterMedia(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	jid, err := newsletterJIDParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := c.Request().ParseMultipartForm(16 << 20); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to parse multipart form"})
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	messageID, serverID, err := h.waClient.PostNewsletterMedia(clientID, jid, data, fileHeader.Filename, contentType, c.FormValue("caption"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, NewsletterPostResponse{MessageID: messageID, ServerID: serverID})
+}
+
+// SubscribeNewsletterUpdates subscribes to live view and reaction counts of a channel
+// @Summary Subscribe to channel updates
+// @Description Receive view and reaction count changes of the channel's posts on the event stream as newsletter_live_update events.
+// @Description The subscription expires after expires_in seconds; subscribe again before then to keep receiving updates.
+// @Tags newsletters
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param newsletter_jid path string true "Channel JID"
+// @Success 200 {object} NewsletterLiveUpdatesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/newsletters/{client_id}/{newsletter_jid}/live-updates [post]
+// @Security BearerAuth
+func (h *NewsletterHandler) SubscribeNewsletterUpdates(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	jid, err := newsletterJIDParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	duration, err := h.waClient.SubscribeNewsletterUpdates(clientID, jid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, NewsletterLiveUpdatesResponse{ExpiresIn: int(duration.Seconds())})
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/labstack/echo/v4"
+)
+
+// OptOutHandler handles API requests for the per-user suppression list
+type OptOutHandler struct {
+	optOutStore *wa.OptOutStore
+}
+
+// NewOptOutHandler creates a new OptOutHandler
+func NewOptOutHandler(optOutStore *wa.OptOutStore) *OptOutHandler {
+	return &OptOutHandler{optOutStore: optOutStore}
+}
+
+type OptOutRequest struct {
+	Recipient string `json:"recipient" validate:"required"`
+	Reason    string `json:"reason"`
+}
+
+type OptOutSettingsRequest struct {
+	Keywords            []string `json:"keywords" validate:"required"`
+	SendConfirmation    bool     `json:"send_confirmation"`
+	ConfirmationMessage string   `json:"confirmation_message"`
+}
+
+// GetOptOuts returns the suppression list of the authenticated user
+// @Summary List opt-outs
+// @Description Get all recipients that opted out of receiving messages
+// @Tags opt-outs
+// @Accept json
+// @Produce json
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/opt-outs [get]
+// @Security BearerAuth
+func (h *OptOutHandler) GetOptOuts(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	optOuts, err := h.optOutStore.List(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, optOuts)
+}
+
+// AddOptOut puts a recipient on the suppression list
+// @Summary Add opt-out
+// @Description Add a recipient to the suppression list
+// @Tags opt-outs
+// @Accept json
+// @Produce json
+// @Param request body OptOutRequest true "Opt-out data"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/opt-outs [post]
+// @Security BearerAuth
+func (h *OptOutHandler) AddOptOut(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	var req OptOutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	// API-key requests are recorded as "api", dashboard requests as "manual"
+	source := wa.OptOutSourceManual
+	if c.Request().Header.Get("X-API-Key") != "" {
+		source = wa.OptOutSourceAPI
+	}
+
+	optOut, err := h.optOutStore.Add(c.Request().Context(), userID, req.Recipient, "", source, "", req.Reason)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, optOut)
+}
+
+// RemoveOptOut takes a recipient off the suppression list
+// @Summary Remove opt-out
+// @Description Remove a recipient from the suppression list
+// @Tags opt-outs
+// @Accept json
+// @Produce json
+// @Param recipient path string true "Recipient phone number or JID"
+// @Success 200 {object} GenericResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/opt-outs/{recipient} [delete]
+// @Security BearerAuth
+func (h *OptOutHandler) RemoveOptOut(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	recipient, err := url.PathUnescape(c.Param("recipient"))
+	if err != nil || recipient == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Recipient is required"})
+	}
+
+	if err := h.optOutStore.Remove(c.Request().Context(), userID, recipient); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Opt-out removed successfully"})
+}
+
+// GetOptOutSettings returns the opt-out keywords and confirmation settings
+// @Summary Get opt-out settings
+// @Description Get the opt-out keywords and confirmation reply settings
+// @Tags opt-outs
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/opt-outs/settings [get]
+// @Security BearerAuth
+func (h *OptOutHandler) GetOptOutSettings(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	settings, err := h.optOutStore.GetSettings(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateOptOutSettings updates the opt-out keywords and confirmation settings
+// @Summary Update opt-out settings
+// @Description Update the opt-out keywords and confirmation reply settings
+// @Tags opt-outs
+// @Accept json
+// @Produce json
+// @Param request body OptOutSettingsRequest true "Opt-out settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/opt-outs/settings [put]
+// @Security BearerAuth
+func (h *OptOutHandler) UpdateOptOutSettings(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == 0 {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+	}
+
+	var req OptOutSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.optOutStore.SaveSettings(c.Request().Context(), userID, req.Keywords, req.SendConfirmation, req.ConfirmationMessage)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
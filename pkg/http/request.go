@@ -13,11 +13,13 @@ const (
 // MobileNumber is the recipient's phone number.
 // Text is the content of the message.
 // Type signifies the message type (e.g., text, media).
+// Transactional bypasses the opt-out list for messages such as OTPs and receipts.
 type SendMessageRequest struct {
-	ClientID     string      `json:"client_id" validate:"required"`
-	MobileNumber string      `json:"mobile_number" validate:"required"`
-	Text         string      `json:"text" validate:"required"`
-	Type         MessageType `json:"type" validate:"required"`
+	ClientID      string      `json:"client_id" validate:"required"`
+	MobileNumber  string      `json:"mobile_number" validate:"required"`
+	Text          string      `json:"text" validate:"required"`
+	Type          MessageType `json:"type" validate:"required"`
+	Transactional bool        `json:"transactional"`
 }
 
 type SendMessageResponse struct {
@@ -25,10 +27,11 @@ type SendMessageResponse struct {
 }
 
 type SendMediaMessageRequest struct {
-	ClientID     string                `json:"client_id" form:"client_id" validate:"required"`
-	MobileNumber string                `json:"mobile_number" form:"mobile_number" validate:"required"`
-	MediaURL     *multipart.FileHeader `json:"media_url" form:"media_url" validate:"required"`
-	Caption      string                `json:"caption,omitempty" form:"caption"`
+	ClientID      string                `json:"client_id" form:"client_id" validate:"required"`
+	MobileNumber  string                `json:"mobile_number" form:"mobile_number" validate:"required"`
+	MediaURL      *multipart.FileHeader `json:"media_url" form:"media_url" validate:"required"`
+	Caption       string                `json:"caption,omitempty" form:"caption"`
+	Transactional bool                  `json:"transactional" form:"transactional"`
 }
 
 type LoginRequest struct {
@@ -47,10 +50,11 @@ type RegisterRequest struct {
 
 // SendTemplateMessageRequest represents a request to send a message using a template
 type SendTemplateMessageRequest struct {
-	DeviceID   string                 `json:"deviceId" validate:"required"`
-	To         string                 `json:"to" validate:"required"`
-	TemplateID string                 `json:"templateId" validate:"required"`
-	Variables  map[string]interface{} `json:"variables"`
+	DeviceID      string                 `json:"deviceId" validate:"required"`
+	To            string                 `json:"to" validate:"required"`
+	TemplateID    string                 `json:"templateId" validate:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	Transactional bool                   `json:"transactional"`
 }
 
 // MessageTemplateRequest represents a request to create or update a message template
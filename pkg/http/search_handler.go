@@ -0,0 +1,345 @@
+package http
+
+import (
+	"errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultSearchLanguage = "simple"
+	searchThreadLimit     = 10
+)
+
+// Markers SearchMessages puts around matches in snippets. ts_headline does not escape the
+// message text, so snippets are escaped first and the markers are turned into <mark> tags
+// afterwards.
+const (
+	snippetStartMarker = "\x02"
+	snippetStopMarker  = "\x03"
+)
+
+var snippetMarkReplacer = strings.NewReplacer(snippetStartMarker, "<mark>", snippetStopMarker, "</mark>")
+
+// highlightSnippet HTML-escapes a snippet from SearchMessages and marks its matches with <mark>.
+func highlightSnippet(snippet string) string {
+	return snippetMarkReplacer.Replace(html.EscapeString(snippet))
+}
+
+// SearchHandler serves full-text search over the messages and threads of a device
+type SearchHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(db db.Querier, deviceStore *wa.DeviceStore) *SearchHandler {
+	return &SearchHandler{db: db, deviceStore: deviceStore}
+}
+
+// SearchMessageResult is a matching message with a cursor that opens the thread around it.
+type SearchMessageResult struct {
+	db.SearchMessagesRow
+	Cursor string `json:"cursor"`
+}
+
+type SearchResponse struct {
+	Threads  []db.SearchThreadsRow `json:"threads"`
+	Messages []SearchMessageResult `json:"messages"`
+}
+
+type SearchSettingsRequest struct {
+	Language string `json:"language" validate:"required"`
+}
+
+// Search finds messages and threads of a device
+// @Summary Search messages
+// @Description Full-text search over message text, captions and document filenames, plus threads whose contact or group name matches. Snippets are HTML-escaped and mark matches with <mark>; pass a result's cursor to the message context endpoint to open the thread around it.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param q query string true "Search query (supports quoted phrases, OR and -exclusions)"
+// @Param chat_jid query string false "Only messages in this chat"
+// @Param direction query string false "incoming or outgoing"
+// @Param type query string false "Message type"
+// @Param since query string false "Only messages sent at or after this time (RFC3339)"
+// @Param until query string false "Only messages sent before this time (RFC3339)"
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/search/{client_id} [get]
+// @Security BearerAuth
+func (h *SearchHandler) Search(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "q is required"})
+	}
+
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	offset, _ := strconv.ParseInt(c.QueryParam("offset"), 10, 64)
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	params := db.SearchMessagesParams{
+		Query:       query,
+		DeviceID:    clientID,
+		ChatJid:     optionalText(c.QueryParam("chat_jid")),
+		MessageType: optionalText(c.QueryParam("type")),
+		QueryOffset: int32(offset),
+		QueryLimit:  int32(limit),
+	}
+	if direction := c.QueryParam("direction"); direction != "" {
+		if direction != "incoming" && direction != "outgoing" {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "direction must be incoming or outgoing"})
+		}
+		params.Direction = pgtype.Text{String: direction, Valid: true}
+	}
+	if params.Since, err = parseTimeParam(c, "since"); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if params.Until, err = parseTimeParam(c, "until"); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	rows, err := h.db.SearchMessages(ctx, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	response := SearchResponse{
+		Threads:  []db.SearchThreadsRow{},
+		Messages: make([]SearchMessageResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		row.Snippet = highlightSnippet(row.Snippet)
+		response.Messages = append(response.Messages, SearchMessageResult{
+			SearchMessagesRow: row,
+			Cursor:            encodeMessageCursor(row.SentAt, row.ID),
+		})
+	}
+
+	// Name matches are not ranked against message matches, so they are only returned with
+	// the first page of a search that is not already narrowed to one chat.
+	if offset == 0 && !params.ChatJid.Valid {
+		threads, err := h.db.SearchThreads(ctx, db.SearchThreadsParams{
+			DeviceID:   clientID,
+			Pattern:    pgtype.Text{String: "%" + escapeLike(query) + "%", Valid: true},
+			QueryLimit: searchThreadLimit,
+		})
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		if threads != nil {
+			response.Threads = threads
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetMessageContext returns the messages surrounding a message, e.g. a search result
+// @Summary Get message context
+// @Description Get a message together with the messages before and after it in its thread, in chronological order
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param cursor query string true "Message cursor from a search result"
+// @Param limit query int false "Messages on each side (default 10, max 50)"
+// @Success 200 {array} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/messages/context [get]
+// @Security BearerAuth
+func (h *SearchHandler) GetMessageContext(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	sentAt, messageID, err := decodeMessageCursor(c.QueryParam("cursor"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	ctx := c.Request().Context()
+	deviceID := pgtype.Text{String: clientID, Valid: true}
+	chatJID := decodeChatJID(c)
+
+	before, err := h.db.GetThreadMessagesBefore(ctx, db.GetThreadMessagesBeforeParams{
+		DeviceID:   deviceID,
+		Recipient:  chatJID,
+		SentAt:     sentAt,
+		ID:         messageID,
+		QueryLimit: int32(limit),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	// Read forward from the message preceding the target so that the target itself is
+	// the first row of the second page.
+	anchorAt := pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+	anchorID := pgtype.UUID{Valid: true}
+	if len(before) > 0 {
+		anchorAt, anchorID = before[0].SentAt, before[0].ID
+	}
+	after, err := h.db.GetThreadMessagesAfter(ctx, db.GetThreadMessagesAfterParams{
+		DeviceID:   deviceID,
+		Recipient:  chatJID,
+		SentAt:     anchorAt,
+		ID:         anchorID,
+		QueryLimit: int32(limit) + 1,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if len(after) == 0 || after[0].ID != messageID {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Message not found"})
+	}
+
+	messages := make([]db.GetThreadMessagesAfterRow, 0, len(before)+len(after))
+	for i := len(before) - 1; i >= 0; i-- {
+		messages = append(messages, db.GetThreadMessagesAfterRow(before[i]))
+	}
+	messages = append(messages, after...)
+
+	return c.JSON(http.StatusOK, messages)
+}
+
+// GetSearchSettings returns the text search language of a device
+// @Summary Get search settings
+// @Description Get the text search configuration used to index the messages of a device
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/search/{client_id}/settings [get]
+// @Security BearerAuth
+func (h *SearchHandler) GetSearchSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	settings, err := h.db.GetSearchSettings(c.Request().Context(), clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = db.SearchSetting{DeviceID: clientID, Language: defaultSearchLanguage}
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSearchSettings changes the text search language of a device and reindexes its messages
+// @Summary Update search settings
+// @Description Set the Postgres text search configuration (e.g. english, indonesian, simple) used for stemming; existing messages are reindexed
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body SearchSettingsRequest true "Search settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/search/{client_id}/settings [put]
+// @Security BearerAuth
+func (h *SearchHandler) UpdateSearchSettings(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req SearchSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	exists, err := h.db.IsTextSearchConfig(ctx, req.Language)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if !exists {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown text search language: " + req.Language})
+	}
+
+	settings, err := h.db.UpsertSearchSettings(ctx, db.UpsertSearchSettingsParams{
+		DeviceID: clientID,
+		Language: req.Language,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := h.db.ReindexMessageSearch(ctx, pgtype.Text{String: clientID, Valid: true}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+func parseTimeParam(c echo.Context, name string) (pgtype.Timestamptz, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return pgtype.Timestamptz{}, errors.New("Invalid " + name + " format, must be RFC3339")
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+// escapeLike escapes the LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -26,17 +26,31 @@ type Server struct {
 	contactHandler         *ContactHandler
 	inboxHandler           *InboxHandler
 	broadcastHandler       *BroadcastHandler
+	optOutHandler          *OptOutHandler
+	autoReplyHandler       *AutoReplyHandler
+	awayMessageHandler     *AwayMessageHandler
+	flowHandler            *FlowHandler
+	botWebhookHandler      *BotWebhookHandler
+	teamInboxHandler       *TeamInboxHandler
+	labelHandler           *LabelHandler
+	searchHandler          *SearchHandler
+	eventStreamHandler     *EventStreamHandler
+	historySyncHandler     *HistorySyncHandler
+	mediaHandler           *MediaHandler
+	communityHandler       *CommunityHandler
+	newsletterHandler      *NewsletterHandler
+	statusHandler          *StatusHandler
 	db                     db.Querier
 	subscriptionStore      *wa.SubscriptionStore
 }
 
 // NewServer initializes a new Server instance.
-func NewServer(addr string, webhook *DeviceHandler, authHandler *AuthHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) *Server {
+func NewServer(addr string, webhook *DeviceHandler, authHandler *AuthHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, optOutHandler *OptOutHandler, autoReplyHandler *AutoReplyHandler, awayMessageHandler *AwayMessageHandler, flowHandler *FlowHandler, botWebhookHandler *BotWebhookHandler, teamInboxHandler *TeamInboxHandler, labelHandler *LabelHandler, searchHandler *SearchHandler, eventStreamHandler *EventStreamHandler, historySyncHandler *HistorySyncHandler, mediaHandler *MediaHandler, communityHandler *CommunityHandler, newsletterHandler *NewsletterHandler, statusHandler *StatusHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) *Server {
 	messageTemplateHandler := NewMessageTemplateHandler(db)
 	return &Server{
 		httpServer: &http.Server{
 			Addr:    addr,
-			Handler: createEchoServer(webhook, authHandler, messageTemplateHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, db, subscriptionStore),
+			Handler: createEchoServer(webhook, authHandler, messageTemplateHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, optOutHandler, autoReplyHandler, awayMessageHandler, flowHandler, botWebhookHandler, teamInboxHandler, labelHandler, searchHandler, eventStreamHandler, historySyncHandler, mediaHandler, communityHandler, newsletterHandler, statusHandler, db, subscriptionStore),
 		},
 		webhookHandler:         webhook,
 		authHandler:            authHandler,
@@ -45,30 +59,45 @@ func NewServer(addr string, webhook *DeviceHandler, authHandler *AuthHandler, gr
 		contactHandler:         contactHandler,
 		inboxHandler:           inboxHandler,
 		broadcastHandler:       broadcastHandler,
+		optOutHandler:          optOutHandler,
+		autoReplyHandler:       autoReplyHandler,
+		awayMessageHandler:     awayMessageHandler,
+		flowHandler:            flowHandler,
+		botWebhookHandler:      botWebhookHandler,
+		teamInboxHandler:       teamInboxHandler,
+		labelHandler:           labelHandler,
+		searchHandler:          searchHandler,
+		eventStreamHandler:     eventStreamHandler,
+		historySyncHandler:     historySyncHandler,
+		mediaHandler:           mediaHandler,
+		communityHandler:       communityHandler,
+		newsletterHandler:      newsletterHandler,
+		statusHandler:          statusHandler,
 		db:                     db,
 		subscriptionStore:      subscriptionStore,
 	}
 }
 
 // createEchoServer sets up the Echo server with middleware.
-func createEchoServer(webhook *DeviceHandler, authHandler *AuthHandler, messageTemplateHandler *MessageTemplateHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) *echo.Echo {
+func createEchoServer(webhook *DeviceHandler, authHandler *AuthHandler, messageTemplateHandler *MessageTemplateHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, optOutHandler *OptOutHandler, autoReplyHandler *AutoReplyHandler, awayMessageHandler *AwayMessageHandler, flowHandler *FlowHandler, botWebhookHandler *BotWebhookHandler, teamInboxHandler *TeamInboxHandler, labelHandler *LabelHandler, searchHandler *SearchHandler, eventStreamHandler *EventStreamHandler, historySyncHandler *HistorySyncHandler, mediaHandler *MediaHandler, communityHandler *CommunityHandler, newsletterHandler *NewsletterHandler, statusHandler *StatusHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) *echo.Echo {
 	e := echo.New()
 
 	e.Validator = &CustomValidator{validator: validator.New()}
 
 	// Middleware configuration
 	e.Use(middleware.Logger())
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		// Let browser clients read the inbox paging cursors
+		ExposeHeaders: []string{headerCursorBefore, headerCursorAfter},
+	}))
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(middleware.Recover())
 	e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(
 		rate.Limit(20),
 	)))
 
-	e.Static("/api/media", "uploads")
-
 	// Separate function for routes configuration
-	registerRoutes(e, webhook, authHandler, messageTemplateHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, db, subscriptionStore)
+	registerRoutes(e, webhook, authHandler, messageTemplateHandler, groupHandler, contactHandler, inboxHandler, broadcastHandler, optOutHandler, autoReplyHandler, awayMessageHandler, flowHandler, botWebhookHandler, teamInboxHandler, labelHandler, searchHandler, eventStreamHandler, historySyncHandler, mediaHandler, communityHandler, newsletterHandler, statusHandler, db, subscriptionStore)
 
 	return e
 }
@@ -78,9 +107,10 @@ func createEchoServer(webhook *DeviceHandler, authHandler *AuthHandler, messageT
 // - GET /client/qr: Handles requests to retrieve a QR code using the SendQrHandler method of the ConnectionHandler.
 // - GET /: Handles requests to the root path using the Index method of the ConnectionHandler.
 // - POST /http: Handles http events using the SendMessage method of the DeviceHandler.
-func registerRoutes(e *echo.Echo, webhook *DeviceHandler, authHandler *AuthHandler, messageTemplateHandler *MessageTemplateHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) {
+func registerRoutes(e *echo.Echo, webhook *DeviceHandler, authHandler *AuthHandler, messageTemplateHandler *MessageTemplateHandler, groupHandler *GroupHandler, contactHandler *ContactHandler, inboxHandler *InboxHandler, broadcastHandler *BroadcastHandler, optOutHandler *OptOutHandler, autoReplyHandler *AutoReplyHandler, awayMessageHandler *AwayMessageHandler, flowHandler *FlowHandler, botWebhookHandler *BotWebhookHandler, teamInboxHandler *TeamInboxHandler, labelHandler *LabelHandler, searchHandler *SearchHandler, eventStreamHandler *EventStreamHandler, historySyncHandler *HistorySyncHandler, mediaHandler *MediaHandler, communityHandler *CommunityHandler, newsletterHandler *NewsletterHandler, statusHandler *StatusHandler, db db.Querier, subscriptionStore *wa.SubscriptionStore) {
 
 	e.POST("/login", authHandler.Login)
+	e.GET("/api/media/:key", mediaHandler.ServeMedia, SignedOrJwt())
 
 	admin := e.Group("/admin", Jwt())
 
@@ -105,6 +135,11 @@ func registerRoutes(e *echo.Echo, webhook *DeviceHandler, authHandler *AuthHandl
 	admin.GET("/clients/:client_id/subscriptions", webhook.GetDeviceSubscriptions, JwtUserIDMiddleware())
 	admin.PUT("/clients/:client_id/subscriptions", webhook.UpdateDeviceSubscriptions, JwtUserIDMiddleware())
 
+	// Admin Device Members (JWT-protected)
+	admin.GET("/clients/:client_id/members", teamInboxHandler.GetDeviceMembers, JwtUserIDMiddleware())
+	admin.POST("/clients/:client_id/members", teamInboxHandler.AddDeviceMember, JwtUserIDMiddleware())
+	admin.DELETE("/clients/:client_id/members/:user_id", teamInboxHandler.RemoveDeviceMember, JwtUserIDMiddleware())
+
 	// Admin Message Templates (JWT-protected)
 	admin.GET("/templates", messageTemplateHandler.GetUserTemplates, JwtUserIDMiddleware())
 	admin.POST("/templates", messageTemplateHandler.CreateTemplate, JwtUserIDMiddleware())
@@ -114,30 +149,164 @@ func registerRoutes(e *echo.Echo, webhook *DeviceHandler, authHandler *AuthHandl
 	// Admin Contacts (JWT-protected)
 	admin.GET("/contacts/:client_id", contactHandler.GetContacts, JwtUserIDMiddleware())
 	admin.PUT("/contacts/:client_id/sync", contactHandler.SyncContacts, JwtUserIDMiddleware())
+	admin.POST("/contacts/:client_id/presence", contactHandler.SubscribePresence, JwtUserIDMiddleware())
+	admin.POST("/contacts/:client_id/check", contactHandler.CheckNumbers, JwtUserIDMiddleware())
+	admin.POST("/contacts/:client_id/profiles/refresh", contactHandler.RefreshProfiles, JwtUserIDMiddleware())
+	admin.GET("/contacts/:client_id/:jid/profile", contactHandler.GetProfile, JwtUserIDMiddleware())
+	admin.GET("/contacts/:client_id/search", contactHandler.SearchContacts, JwtUserIDMiddleware())
+	admin.GET("/contacts/:client_id/tags", contactHandler.GetContactTags, JwtUserIDMiddleware())
+	admin.GET("/contacts/:client_id/attributes", contactHandler.GetContactAttributes, JwtUserIDMiddleware())
+	admin.POST("/contacts/:client_id/attributes", contactHandler.CreateContactAttribute, JwtUserIDMiddleware())
+	admin.PUT("/contacts/:client_id/attributes/:key", contactHandler.UpdateContactAttribute, JwtUserIDMiddleware())
+	admin.DELETE("/contacts/:client_id/attributes/:key", contactHandler.DeleteContactAttribute, JwtUserIDMiddleware())
+	admin.GET("/contacts/:client_id/:jid/details", contactHandler.GetContactDetails, JwtUserIDMiddleware())
+	admin.PUT("/contacts/:client_id/:jid/details", contactHandler.UpdateContactDetails, JwtUserIDMiddleware())
+	admin.DELETE("/contacts/:client_id/:jid/details", contactHandler.DeleteContactDetails, JwtUserIDMiddleware())
 
 	// Admin Groups (JWT-protected)
 	admin.GET("/groups/:client_id", groupHandler.GetJoinedGroups, JwtUserIDMiddleware())
 	admin.PUT("/groups/:client_id/sync", groupHandler.SyncJoinedGroup, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id", groupHandler.CreateGroup, JwtUserIDMiddleware())
+	admin.GET("/groups/:client_id/:group_jid", groupHandler.GetGroup, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id/:group_jid/participants", groupHandler.UpdateGroupParticipants, JwtUserIDMiddleware())
+	admin.PUT("/groups/:client_id/:group_jid/name", groupHandler.SetGroupName, JwtUserIDMiddleware())
+	admin.PUT("/groups/:client_id/:group_jid/description", groupHandler.SetGroupDescription, JwtUserIDMiddleware())
+	admin.PUT("/groups/:client_id/:group_jid/picture", groupHandler.SetGroupPicture, JwtUserIDMiddleware())
+	admin.DELETE("/groups/:client_id/:group_jid/picture", groupHandler.RemoveGroupPicture, JwtUserIDMiddleware())
+	admin.PUT("/groups/:client_id/:group_jid/settings", groupHandler.UpdateGroupSettings, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id/:group_jid/leave", groupHandler.LeaveGroup, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id/join", groupHandler.JoinGroup, JwtUserIDMiddleware())
+	admin.GET("/groups/:client_id/:group_jid/invite-link", groupHandler.GetGroupInviteLink, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id/:group_jid/invite-link/reset", groupHandler.ResetGroupInviteLink, JwtUserIDMiddleware())
+	admin.GET("/groups/:client_id/:group_jid/join-requests", groupHandler.GetGroupJoinRequests, JwtUserIDMiddleware())
+	admin.POST("/groups/:client_id/:group_jid/join-requests", groupHandler.UpdateGroupJoinRequests, JwtUserIDMiddleware())
+	admin.GET("/groups/:client_id/:group_jid/members", groupHandler.GetGroupMembers, JwtUserIDMiddleware())
+	admin.GET("/groups/:client_id/:group_jid/history", groupHandler.GetGroupHistory, JwtUserIDMiddleware())
+
+	// Communities
+	admin.POST("/communities/:client_id", communityHandler.CreateCommunity, JwtUserIDMiddleware())
+	admin.GET("/communities/:client_id/:community_jid/subgroups", communityHandler.GetSubgroups, JwtUserIDMiddleware())
+	admin.POST("/communities/:client_id/:community_jid/subgroups", communityHandler.LinkSubgroup, JwtUserIDMiddleware())
+	admin.DELETE("/communities/:client_id/:community_jid/subgroups/:group_jid", communityHandler.UnlinkSubgroup, JwtUserIDMiddleware())
+	admin.POST("/communities/:client_id/:community_jid/announcements", communityHandler.PostAnnouncement, JwtUserIDMiddleware())
+
+	// Channels
+	admin.POST("/newsletters/:client_id", newsletterHandler.CreateNewsletter, JwtUserIDMiddleware())
+	admin.GET("/newsletters/:client_id", newsletterHandler.GetNewsletters, JwtUserIDMiddleware())
+	admin.GET("/newsletters/:client_id/:newsletter_jid", newsletterHandler.GetNewsletter, JwtUserIDMiddleware())
+	admin.GET("/newsletters/:client_id/:newsletter_jid/posts", newsletterHandler.GetNewsletterPosts, JwtUserIDMiddleware())
+	admin.POST("/newsletters/:client_id/:newsletter_jid/posts", newsletterHandler.PostNewsletterText, JwtUserIDMiddleware())
+	admin.POST("/newsletters/:client_id/:newsletter_jid/posts/media", newsletterHandler.PostNewsletterMedia, JwtUserIDMiddleware())
+	admin.POST("/newsletters/:client_id/:newsletter_jid/live-updates", newsletterHandler.SubscribeNewsletterUpdates, JwtUserIDMiddleware())
+
+	// Status
+	admin.GET("/status/:client_id", statusHandler.GetStatusPosts, JwtUserIDMiddleware())
+	admin.POST("/status/:client_id/text", statusHandler.PostTextStatus, JwtUserIDMiddleware())
+	admin.POST("/status/:client_id/media", statusHandler.PostMediaStatus, JwtUserIDMiddleware())
+	admin.GET("/status/:client_id/audience", statusHandler.GetStatusAudience, JwtUserIDMiddleware())
+	admin.GET("/status/:client_id/:status_id/views", statusHandler.GetStatusViews, JwtUserIDMiddleware())
 
 	// Admin Inbox (JWT-protected)
 	admin.GET("/inbox/:client_id/threads", inboxHandler.GetThreads, JwtUserIDMiddleware())
+	admin.GET("/inbox/:client_id/changes", inboxHandler.GetChanges, JwtUserIDMiddleware())
 	admin.GET("/inbox/:client_id/threads/:chat_jid/messages", inboxHandler.GetThreadMessages, JwtUserIDMiddleware())
+	admin.GET("/inbox/:client_id/threads/:chat_jid/messages/context", searchHandler.GetMessageContext, JwtUserIDMiddleware())
 	admin.POST("/inbox/:client_id/threads/:chat_jid/send", inboxHandler.SendMessage, JwtUserIDMiddleware())
 	admin.POST("/inbox/:client_id/threads/:chat_jid/send-media", inboxHandler.SendMediaMessage, JwtUserIDMiddleware())
 	admin.POST("/inbox/:client_id/threads/send", inboxHandler.SendNewMessage, JwtUserIDMiddleware())
 	admin.POST("/inbox/:client_id/threads/schedule", inboxHandler.ScheduleMessage, JwtUserIDMiddleware())
 	admin.POST("/inbox/:client_id/threads/:chat_jid/read", inboxHandler.MarkRead, JwtUserIDMiddleware())
+	admin.POST("/inbox/:client_id/threads/:chat_jid/presence", inboxHandler.SendChatPresence, JwtUserIDMiddleware())
+	admin.GET("/inbox/:client_id/read-receipts", inboxHandler.GetReadReceiptSettings, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/read-receipts", inboxHandler.UpdateReadReceiptSettings, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/assign", teamInboxHandler.AssignThread, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/status", teamInboxHandler.SetThreadStatus, JwtUserIDMiddleware())
+	admin.GET("/inbox/:client_id/threads/:chat_jid/notes", teamInboxHandler.GetThreadNotes, JwtUserIDMiddleware())
+	admin.POST("/inbox/:client_id/threads/:chat_jid/notes", teamInboxHandler.CreateThreadNote, JwtUserIDMiddleware())
+	admin.DELETE("/inbox/:client_id/threads/:chat_jid/notes/:id", teamInboxHandler.DeleteThreadNote, JwtUserIDMiddleware())
+	admin.GET("/inbox/:client_id/threads/:chat_jid/audit", teamInboxHandler.GetThreadAuditLogs, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/labels", labelHandler.SetThreadLabels, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/pin", inboxHandler.PinThread, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/archive", inboxHandler.ArchiveThread, JwtUserIDMiddleware())
+	admin.PUT("/inbox/:client_id/threads/:chat_jid/mute", inboxHandler.MuteThread, JwtUserIDMiddleware())
+
+	// Admin Live events (JWT-protected)
+	admin.GET("/events/:client_id", eventStreamHandler.Stream, JwtUserIDMiddleware())
+
+	// Admin Search (JWT-protected)
+	admin.GET("/search/:client_id", searchHandler.Search, JwtUserIDMiddleware())
+	admin.GET("/search/:client_id/settings", searchHandler.GetSearchSettings, JwtUserIDMiddleware())
+	admin.PUT("/search/:client_id/settings", searchHandler.UpdateSearchSettings, JwtUserIDMiddleware())
+
+	// Admin History sync (JWT-protected)
+	admin.GET("/history-sync/:client_id", historySyncHandler.GetHistorySyncProgress, JwtUserIDMiddleware())
+	admin.GET("/history-sync/:client_id/settings", historySyncHandler.GetHistorySyncSettings, JwtUserIDMiddleware())
+	admin.PUT("/history-sync/:client_id/settings", historySyncHandler.UpdateHistorySyncSettings, JwtUserIDMiddleware())
+
+	// Admin Media (JWT-protected)
+	admin.GET("/media/:client_id/messages/:message_id", mediaHandler.GetMessageMedia, JwtUserIDMiddleware())
+	admin.GET("/media/:client_id/settings", mediaHandler.GetMediaDownloadSettings, JwtUserIDMiddleware())
+	admin.PUT("/media/:client_id/settings", mediaHandler.UpdateMediaDownloadSettings, JwtUserIDMiddleware())
+	admin.GET("/media/:client_id/retention", mediaHandler.GetMediaRetentionSettings, JwtUserIDMiddleware())
+	admin.PUT("/media/:client_id/retention", mediaHandler.UpdateMediaRetentionSettings, JwtUserIDMiddleware())
+	admin.GET("/media/:client_id/usage", mediaHandler.GetMediaUsage, JwtUserIDMiddleware())
+	admin.GET("/media/:client_id/cleanups", mediaHandler.GetMediaCleanups, JwtUserIDMiddleware())
+
+	// Admin Labels (JWT-protected)
+	admin.GET("/labels/:client_id", labelHandler.GetLabels, JwtUserIDMiddleware())
+	admin.POST("/labels/:client_id", labelHandler.CreateLabel, JwtUserIDMiddleware())
+	admin.PUT("/labels/:client_id/:id", labelHandler.UpdateLabel, JwtUserIDMiddleware())
+	admin.DELETE("/labels/:client_id/:id", labelHandler.DeleteLabel, JwtUserIDMiddleware())
 
 	// Admin Broadcasts (JWT-protected)
 	admin.GET("/broadcasts", broadcastHandler.GetBroadcastJobs, JwtUserIDMiddleware())
 	admin.POST("/broadcasts", broadcastHandler.CreateBroadcast, JwtUserIDMiddleware())
 	admin.GET("/broadcasts/:id", broadcastHandler.GetBroadcastJob, JwtUserIDMiddleware())
 
+	// Admin Opt-outs (JWT-protected)
+	admin.GET("/opt-outs", optOutHandler.GetOptOuts, JwtUserIDMiddleware())
+	admin.POST("/opt-outs", optOutHandler.AddOptOut, JwtUserIDMiddleware())
+	admin.DELETE("/opt-outs/:recipient", optOutHandler.RemoveOptOut, JwtUserIDMiddleware())
+	admin.GET("/opt-outs/settings", optOutHandler.GetOptOutSettings, JwtUserIDMiddleware())
+	admin.PUT("/opt-outs/settings", optOutHandler.UpdateOptOutSettings, JwtUserIDMiddleware())
+
+	// Admin Auto-replies (JWT-protected)
+	admin.GET("/auto-replies/:client_id", autoReplyHandler.GetAutoReplyRules, JwtUserIDMiddleware())
+	admin.POST("/auto-replies/:client_id", autoReplyHandler.CreateAutoReplyRule, JwtUserIDMiddleware())
+	admin.GET("/auto-replies/:client_id/:id", autoReplyHandler.GetAutoReplyRule, JwtUserIDMiddleware())
+	admin.PUT("/auto-replies/:client_id/:id", autoReplyHandler.UpdateAutoReplyRule, JwtUserIDMiddleware())
+	admin.DELETE("/auto-replies/:client_id/:id", autoReplyHandler.DeleteAutoReplyRule, JwtUserIDMiddleware())
+
+	// Admin Away message (JWT-protected)
+	admin.GET("/away-message/:client_id", awayMessageHandler.GetAwayMessageSettings, JwtUserIDMiddleware())
+	admin.PUT("/away-message/:client_id", awayMessageHandler.UpdateAwayMessageSettings, JwtUserIDMiddleware())
+
+	// Admin Flows (JWT-protected)
+	admin.GET("/flows/:client_id", flowHandler.GetFlows, JwtUserIDMiddleware())
+	admin.POST("/flows/:client_id", flowHandler.CreateFlow, JwtUserIDMiddleware())
+	admin.GET("/flows/:client_id/:id", flowHandler.GetFlow, JwtUserIDMiddleware())
+	admin.PUT("/flows/:client_id/:id", flowHandler.UpdateFlow, JwtUserIDMiddleware())
+	admin.DELETE("/flows/:client_id/:id", flowHandler.DeleteFlow, JwtUserIDMiddleware())
+	admin.GET("/flows/:client_id/:id/versions", flowHandler.GetFlowVersions, JwtUserIDMiddleware())
+	admin.PUT("/flows/:client_id/:id/version", flowHandler.SetFlowVersion, JwtUserIDMiddleware())
+	admin.DELETE("/flows/:client_id/sessions/:chat_jid", flowHandler.CloseFlowSession, JwtUserIDMiddleware())
+
+	// Admin Bot webhook (JWT-protected)
+	admin.GET("/bot-webhook/:client_id", botWebhookHandler.GetBotWebhook, JwtUserIDMiddleware())
+	admin.PUT("/bot-webhook/:client_id", botWebhookHandler.UpdateBotWebhook, JwtUserIDMiddleware())
+	admin.DELETE("/bot-webhook/:client_id", botWebhookHandler.DeleteBotWebhook, JwtUserIDMiddleware())
+
 	// API Key
 	v1 := e.Group("/v1", AppKeyAuthMiddleware(db))
 	v1.POST("/chats", webhook.SendMessage)
 	v1.POST("/chats/template", webhook.SendTemplateMessage)
 	v1.POST("/chats/media", webhook.SendMediaMessage)
+	v1.POST("/contacts/:client_id/check", contactHandler.CheckNumbers)
+	v1.GET("/contacts/:client_id/:jid/profile", contactHandler.GetProfile)
+	v1.GET("/contacts/:client_id/search", contactHandler.SearchContacts)
+	v1.GET("/contacts/:client_id/attributes", contactHandler.GetContactAttributes)
+	v1.GET("/contacts/:client_id/:jid/details", contactHandler.GetContactDetails)
+	v1.PUT("/contacts/:client_id/:jid/details", contactHandler.UpdateContactDetails)
 
 	// Message Templates
 	v1.POST("/templates", messageTemplateHandler.CreateTemplate)
@@ -148,6 +317,70 @@ func registerRoutes(e *echo.Echo, webhook *DeviceHandler, authHandler *AuthHandl
 	// Groups
 	v1.PUT("/groups/:client_id/sync", groupHandler.SyncJoinedGroup)
 	v1.GET("/groups/:client_id", groupHandler.GetJoinedGroups)
+	v1.POST("/groups/:client_id", groupHandler.CreateGroup)
+	v1.GET("/groups/:client_id/:group_jid", groupHandler.GetGroup)
+	v1.POST("/groups/:client_id/:group_jid/participants", groupHandler.UpdateGroupParticipants)
+	v1.PUT("/groups/:client_id/:group_jid/name", groupHandler.SetGroupName)
+	v1.PUT("/groups/:client_id/:group_jid/description", groupHandler.SetGroupDescription)
+	v1.PUT("/groups/:client_id/:group_jid/picture", groupHandler.SetGroupPicture)
+	v1.DELETE("/groups/:client_id/:group_jid/picture", groupHandler.RemoveGroupPicture)
+	v1.PUT("/groups/:client_id/:group_jid/settings", groupHandler.UpdateGroupSettings)
+	v1.POST("/groups/:client_id/:group_jid/leave", groupHandler.LeaveGroup)
+	v1.POST("/groups/:client_id/join", groupHandler.JoinGroup)
+	v1.GET("/groups/:client_id/:group_jid/invite-link", groupHandler.GetGroupInviteLink)
+	v1.POST("/groups/:client_id/:group_jid/invite-link/reset", groupHandler.ResetGroupInviteLink)
+	v1.GET("/groups/:client_id/:group_jid/join-requests", groupHandler.GetGroupJoinRequests)
+	v1.POST("/groups/:client_id/:group_jid/join-requests", groupHandler.UpdateGroupJoinRequests)
+	v1.GET("/groups/:client_id/:group_jid/members", groupHandler.GetGroupMembers)
+	v1.GET("/groups/:client_id/:group_jid/history", groupHandler.GetGroupHistory)
+	v1.POST("/communities/:client_id", communityHandler.CreateCommunity)
+	v1.GET("/communities/:client_id/:community_jid/subgroups", communityHandler.GetSubgroups)
+	v1.POST("/communities/:client_id/:community_jid/subgroups", communityHandler.LinkSubgroup)
+	v1.DELETE("/communities/:client_id/:community_jid/subgroups/:group_jid", communityHandler.UnlinkSubgroup)
+	v1.POST("/communities/:client_id/:community_jid/announcements", communityHandler.PostAnnouncement)
+	v1.POST("/newsletters/:client_id", newsletterHandler.CreateNewsletter)
+	v1.GET("/newsletters/:client_id", newsletterHandler.GetNewsletters)
+	v1.GET("/newsletters/:client_id/:newsletter_jid", newsletterHandler.GetNewsletter)
+	v1.GET("/newsletters/:client_id/:newsletter_jid/posts", newsletterHandler.GetNewsletterPosts)
+	v1.POST("/newsletters/:client_id/:newsletter_jid/posts", newsletterHandler.PostNewsletterText)
+	v1.POST("/newsletters/:client_id/:newsletter_jid/posts/media", newsletterHandler.PostNewsletterMedia)
+	v1.POST("/newsletters/:client_id/:newsletter_jid/live-updates", newsletterHandler.SubscribeNewsletterUpdates)
+	v1.GET("/status/:client_id", statusHandler.GetStatusPosts)
+	v1.POST("/status/:client_id/text", statusHandler.PostTextStatus)
+	v1.POST("/status/:client_id/media", statusHandler.PostMediaStatus)
+	v1.GET("/status/:client_id/audience", statusHandler.GetStatusAudience)
+	v1.GET("/status/:client_id/:status_id/views", statusHandler.GetStatusViews)
+
+	// Opt-outs
+	v1.GET("/opt-outs", optOutHandler.GetOptOuts)
+	v1.POST("/opt-outs", optOutHandler.AddOptOut)
+	v1.DELETE("/opt-outs/:recipient", optOutHandler.RemoveOptOut)
+
+	// Auto-replies
+	v1.GET("/auto-replies/:client_id", autoReplyHandler.GetAutoReplyRules)
+	v1.POST("/auto-replies/:client_id", autoReplyHandler.CreateAutoReplyRule)
+	v1.GET("/auto-replies/:client_id/:id", autoReplyHandler.GetAutoReplyRule)
+	v1.PUT("/auto-replies/:client_id/:id", autoReplyHandler.UpdateAutoReplyRule)
+	v1.DELETE("/auto-replies/:client_id/:id", autoReplyHandler.DeleteAutoReplyRule)
+
+	// Away message
+	v1.GET("/away-message/:client_id", awayMessageHandler.GetAwayMessageSettings)
+	v1.PUT("/away-message/:client_id", awayMessageHandler.UpdateAwayMessageSettings)
+
+	// Flows
+	v1.GET("/flows/:client_id", flowHandler.GetFlows)
+	v1.POST("/flows/:client_id", flowHandler.CreateFlow)
+	v1.GET("/flows/:client_id/:id", flowHandler.GetFlow)
+	v1.PUT("/flows/:client_id/:id", flowHandler.UpdateFlow)
+	v1.DELETE("/flows/:client_id/:id", flowHandler.DeleteFlow)
+	v1.GET("/flows/:client_id/:id/versions", flowHandler.GetFlowVersions)
+	v1.PUT("/flows/:client_id/:id/version", flowHandler.SetFlowVersion)
+	v1.DELETE("/flows/:client_id/sessions/:chat_jid", flowHandler.CloseFlowSession)
+
+	// Bot webhook
+	v1.GET("/bot-webhook/:client_id", botWebhookHandler.GetBotWebhook)
+	v1.PUT("/bot-webhook/:client_id", botWebhookHandler.UpdateBotWebhook)
+	v1.DELETE("/bot-webhook/:client_id", botWebhookHandler.DeleteBotWebhook)
 
 }
 
@@ -0,0 +1,373 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// StatusHandler posts Status (stories) updates from a device and reports who viewed them.
+type StatusHandler struct {
+	device   *wa.DeviceStore
+	waClient *wa.WhatsappClient
+	db       db.Querier
+}
+
+func NewStatusHandler(device *wa.DeviceStore, waClient *wa.WhatsappClient, db db.Querier) *StatusHandler {
+	return &StatusHandler{
+		device:   device,
+		waClient: waClient,
+		db:       db,
+	}
+}
+
+type TextStatusRequest struct {
+	Text string `json:"text" validate:"required,max=700"`
+	// BackgroundColor and TextColor are #RRGGBB or #AARRGGBB
+	BackgroundColor string `json:"background_color" validate:"required"`
+	TextColor       string `json:"text_color"`
+	Font            string `json:"font"`
+	// Viewers restricts the update to these phone numbers or JIDs; see PostTextStatus
+	Viewers []string `json:"viewers"`
+}
+
+type StatusPostResponse struct {
+	ID        string `json:"id"`
+	MessageID string `json:"message_id"`
+}
+
+// StatusAudienceConflictResponse lists the recipients outside the requested viewers that
+// the status privacy setting would share an update with.
+type StatusAudienceConflictResponse struct {
+	Error      string   `json:"error"`
+	Recipients []string `json:"recipients"`
+}
+
+// parseColor parses a #RRGGBB or #AARRGGBB color into ARGB. Colors without alpha are opaque.
+func parseColor(color string) (uint32, error) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return 0, fmt.Errorf("Invalid color %s", color)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid color %s", color)
+	}
+	if len(hex) == 6 {
+		value |= 0xFF000000
+	}
+	return uint32(value), nil
+}
+
+// restrictViewers parses the viewers an update is restricted to and returns the recipients
+// the status privacy setting would share it with beyond them.
+func (h *StatusHandler) restrictViewers(clientID string, viewers []string) ([]string, []string, int, error) {
+	if len(viewers) == 0 {
+		return nil, nil, http.StatusOK, nil
+	}
+	jids, err := parseParticipants(viewers)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+	outside, err := h.waClient.StatusRecipientsOutside(clientID, jids)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	restricted := make([]string, 0, len(jids))
+	for _, jid := range jids {
+		restricted = append(restricted, jid.String())
+	}
+	return restricted, outside, http.StatusOK, nil
+}
+
+// PostTextStatus posts a text status update
+// @Summary Post text status
+// @Description Post a text Status update on a colored background, optionally with a font (system, system_text, fb_script,
+// @Description system_bold, morningbreeze_regular, calistoga_regular, exo2_extrabold or courierprime_bold).
+// @Description WhatsApp sends a status to the audience of the status privacy setting on the phone. With viewers set, the
+// @Description update is only posted when that audience is limited to the given contacts ("Only share with..."); otherwise
+// @Description 409 lists the contacts it would also reach.
+// @Tags status
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body TextStatusRequest true "Text status"
+// @Success 201 {object} StatusPostResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} StatusAudienceConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/status/{client_id}/text [post]
+// @Security BearerAuth
+func (h *StatusHandler) PostTextStatus(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req TextStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.Font != "" && !wa.IsValidStatusFont(req.Font) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid font"})
+	}
+	textStatus := wa.TextStatus{Text: req.Text, Font: strings.ToLower(req.Font)}
+	if textStatus.BackgroundColor, err = parseColor(req.BackgroundColor); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if req.TextColor != "" {
+		if textStatus.TextColor, err = parseColor(req.TextColor); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	viewers, outside, status, err := h.restrictViewers(clientID, req.Viewers)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	if len(outside) > 0 {
+		return c.JSON(http.StatusConflict, StatusAudienceConflictResponse{
+			Error:      "The status privacy setting shares updates with contacts outside viewers",
+			Recipients: outside,
+		})
+	}
+
+	messageID, err := h.waClient.PostTextStatus(clientID, textStatus)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	post, err := h.db.CreateStatusPost(c.Request().Context(), db.CreateStatusPostParams{
+		DeviceID:        clientID,
+		WaMessageID:     messageID,
+		Type:            "text",
+		Content:         req.Text,
+		BackgroundColor: pgtype.Text{String: req.BackgroundColor, Valid: true},
+		Font:            optionalText(textStatus.Font),
+		Viewers:         viewers,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, StatusPostResponse{ID: post.ID.String(), MessageID: messageID})
+}
+
+// PostMediaStatus posts an image or video status update
+// @Summary Post image or video status
+// @Description Post an image or video Status update with an optional caption. viewers restricts the update the same way as
+// @Description for text status updates; repeat the field for each contact.
+// @Tags status
+// @Accept multipart/form-data
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param file formData file true "Image or video"
+// @Param caption formData string false "Caption"
+// @Param viewers formData []string false "Phone numbers or JIDs of the contacts allowed to see the update" collectionFormat(multi)
+// @Success 201 {object} StatusPostResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} StatusAudienceConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/status/{client_id}/media [post]
+// @Security BearerAuth
+func (h *StatusHandler) PostMediaStatus(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := c.Request().ParseMultipartForm(64 << 20); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to parse multipart form"})
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	var statusType string
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		statusType = "image"
+	case strings.HasPrefix(contentType, "video/"):
+		statusType = "video"
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File must be an image or a video"})
+	}
+
+	viewers, outside, status, err := h.restrictViewers(clientID, c.Request().MultipartForm.Value["viewers"])
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	if len(outside) > 0 {
+		return c.JSON(http.StatusConflict, StatusAudienceConflictResponse{
+			Error:      "The status privacy setting shares updates with contacts outside viewers",
+			Recipients: outside,
+		})
+	}
+
+	caption := c.FormValue("caption")
+	messageID, err := h.waClient.PostMediaStatus(clientID, data, contentType, caption)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	post, err := h.db.CreateStatusPost(c.Request().Context(), db.CreateStatusPostParams{
+		DeviceID:    clientID,
+		WaMessageID: messageID,
+		Type:        statusType,
+		Content:     caption,
+		Viewers:     viewers,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, StatusPostResponse{ID: post.ID.String(), MessageID: messageID})
+}
+
+// GetStatusPosts lists the status updates posted from a device
+// @Summary List status updates
+// @Description List the Status updates posted through the API, newest first, with how many contacts viewed each.
+// @Description Views come from read receipts, so contacts that turned them off are not counted.
+// @Tags status
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param limit query int false "Maximum number of updates (default 50, max 500)"
+// @Param offset query int false "Number of updates to skip"
+// @Success 200 {array} db.ListStatusPostsRow
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/status/{client_id} [get]
+// @Security BearerAuth
+func (h *StatusHandler) GetStatusPosts(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	posts, err := h.db.ListStatusPosts(c.Request().Context(), db.ListStatusPostsParams{
+		DeviceID:    clientID,
+		QueryLimit:  int32(limit),
+		QueryOffset: int32(offset),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, posts)
+}
+
+// GetStatusViews lists who viewed a status update
+// @Summary List status viewers
+// @Description List the contacts that viewed a Status update, most recent first
+// @Tags status
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param status_id path string true "Status update ID"
+// @Success 200 {array} db.ListStatusViewsRow
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/status/{client_id}/{status_id}/views [get]
+// @Security BearerAuth
+func (h *StatusHandler) GetStatusViews(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var statusID pgtype.UUID
+	if err := statusID.Scan(c.Param("status_id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid status ID"})
+	}
+
+	ctx := c.Request().Context()
+	post, err := h.db.GetStatusPost(ctx, db.GetStatusPostParams{DeviceID: clientID, ID: statusID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Status update not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	views, err := h.db.ListStatusViews(ctx, post.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, views)
+}
+
+// GetStatusAudience returns who status updates are sent to
+// @Summary Get status audience
+// @Description Get the status privacy setting of the device and the contacts status updates are sent to.
+// @Description The setting is changed on the phone under Status privacy.
+// @Tags status
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {object} wa.StatusAudience
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/status/{client_id}/audience [get]
+// @Security BearerAuth
+func (h *StatusHandler) GetStatusAudience(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.device)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	audience, err := h.waClient.GetStatusAudience(clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, audience)
+}
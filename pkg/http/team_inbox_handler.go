@@ -0,0 +1,441 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/wa"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// TeamInboxHandler manages device team members and the shared inbox workflow:
+// thread assignment, status, internal notes and the audit trail.
+type TeamInboxHandler struct {
+	db          db.Querier
+	deviceStore *wa.DeviceStore
+}
+
+// NewTeamInboxHandler creates a new TeamInboxHandler
+func NewTeamInboxHandler(db db.Querier, deviceStore *wa.DeviceStore) *TeamInboxHandler {
+	return &TeamInboxHandler{db: db, deviceStore: deviceStore}
+}
+
+type AddDeviceMemberRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type AssignThreadRequest struct {
+	// UserID is the member to assign the thread to; null unassigns it.
+	UserID *int32 `json:"user_id"`
+}
+
+type ThreadStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+}
+
+type ThreadNoteRequest struct {
+	Content string `json:"content" validate:"required"`
+}
+
+// GetDeviceMembers returns the team members of a device
+// @Summary List device members
+// @Description Get the users that share the inbox of a device
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/clients/{client_id}/members [get]
+// @Security BearerAuth
+func (h *TeamInboxHandler) GetDeviceMembers(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	members, err := h.db.GetDeviceMembers(c.Request().Context(), clientID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if members == nil {
+		members = []db.GetDeviceMembersRow{}
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// AddDeviceMember gives an existing user access to the inbox of a device
+// @Summary Add device member
+// @Description Share the inbox of a device with another user, identified by email
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param request body AddDeviceMemberRequest true "Member"
+// @Success 201 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/clients/{client_id}/members [post]
+// @Security BearerAuth
+func (h *TeamInboxHandler) AddDeviceMember(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req AddDeviceMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.db.GetUserByUsername(ctx, strings.TrimSpace(req.Email))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.AddDeviceMember(ctx, db.AddDeviceMemberParams{
+		DeviceID: clientID,
+		UserID:   user.ID,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, GenericResponse{Message: "Member added successfully"})
+}
+
+// RemoveDeviceMember revokes a user's access to the inbox of a device
+// @Summary Remove device member
+// @Description Stop sharing the inbox of a device with a user
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/clients/{client_id}/members/{user_id} [delete]
+// @Security BearerAuth
+func (h *TeamInboxHandler) RemoveDeviceMember(c echo.Context) error {
+	clientID, status, err := authorizeDevice(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+	}
+
+	removed, err := h.db.RemoveDeviceMember(c.Request().Context(), db.RemoveDeviceMemberParams{
+		DeviceID: clientID,
+		UserID:   int32(memberID),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if removed == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Member not found"})
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Member removed successfully"})
+}
+
+// AssignThread assigns a thread to a team member or unassigns it
+// @Summary Assign thread
+// @Description Assign a thread to the device owner or one of its members; a null user_id unassigns it
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body AssignThreadRequest true "Assignee"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/assign [put]
+// @Security BearerAuth
+func (h *TeamInboxHandler) AssignThread(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req AssignThreadRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	chatJID := decodeChatJID(c)
+	thread, status, err := findThread(c, h.db, clientID, chatJID)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	assignee := pgtype.Int4{}
+	if req.UserID != nil {
+		if _, err := h.deviceStore.GetDeviceForMember(ctx, clientID, *req.UserID); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Assignee is not a member of this device"})
+		}
+		assignee = pgtype.Int4{Int32: *req.UserID, Valid: true}
+	}
+
+	if err := h.db.AssignThread(ctx, db.AssignThreadParams{
+		DeviceID:       clientID,
+		ChatJid:        chatJID,
+		AssignedUserID: assignee,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if thread.AssignedUserID != assignee {
+		wa.RecordThreadAudit(ctx, h.db, clientID, chatJID, getUserIDFromContext(c), wa.ThreadActionAssigned,
+			formatUserID(thread.AssignedUserID), formatUserID(assignee))
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Thread assigned successfully"})
+}
+
+// SetThreadStatus moves a thread between open, pending and resolved
+// @Summary Set thread status
+// @Description Set the status of a thread; resolved and pending threads reopen when a new message arrives
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body ThreadStatusRequest true "Status"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/status [put]
+// @Security BearerAuth
+func (h *TeamInboxHandler) SetThreadStatus(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ThreadStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if !wa.IsValidThreadStatus(req.Status) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Status must be one of open, pending or resolved"})
+	}
+
+	ctx := c.Request().Context()
+	chatJID := decodeChatJID(c)
+	thread, status, err := findThread(c, h.db, clientID, chatJID)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	if err := h.db.SetThreadStatus(ctx, db.SetThreadStatusParams{
+		DeviceID: clientID,
+		ChatJid:  chatJID,
+		Status:   req.Status,
+	}); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if thread.Status != req.Status {
+		wa.RecordThreadAudit(ctx, h.db, clientID, chatJID, getUserIDFromContext(c), wa.ThreadActionStatusChanged,
+			thread.Status, req.Status)
+	}
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Thread status updated successfully"})
+}
+
+// GetThreadNotes returns the internal notes of a thread
+// @Summary List thread notes
+// @Description Get the internal notes of a thread; notes are never sent to WhatsApp
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/notes [get]
+// @Security BearerAuth
+func (h *TeamInboxHandler) GetThreadNotes(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	notes, err := h.db.GetThreadNotes(c.Request().Context(), db.GetThreadNotesParams{
+		DeviceID: clientID,
+		ChatJid:  decodeChatJID(c),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if notes == nil {
+		notes = []db.GetThreadNotesRow{}
+	}
+
+	return c.JSON(http.StatusOK, notes)
+}
+
+// CreateThreadNote adds an internal note to a thread
+// @Summary Add thread note
+// @Description Add an internal note to a thread; notes are only visible to the team
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param request body ThreadNoteRequest true "Note"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/notes [post]
+// @Security BearerAuth
+func (h *TeamInboxHandler) CreateThreadNote(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var req ThreadNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	chatJID := decodeChatJID(c)
+	if _, status, err := findThread(c, h.db, clientID, chatJID); err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	userID := getUserIDFromContext(c)
+	note, err := h.db.CreateThreadNote(ctx, db.CreateThreadNoteParams{
+		DeviceID: clientID,
+		ChatJid:  chatJID,
+		UserID:   userID,
+		Content:  req.Content,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	wa.RecordThreadAudit(ctx, h.db, clientID, chatJID, userID, wa.ThreadActionNoteAdded, "", note.ID.String())
+
+	return c.JSON(http.StatusCreated, note)
+}
+
+// DeleteThreadNote deletes one of the caller's notes on a thread
+// @Summary Delete thread note
+// @Description Delete an internal note; only its author can delete it
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Param id path string true "Note ID"
+// @Success 200 {object} GenericResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/notes/{id} [delete]
+// @Security BearerAuth
+func (h *TeamInboxHandler) DeleteThreadNote(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	var noteID pgtype.UUID
+	if err := noteID.Scan(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid note ID"})
+	}
+
+	ctx := c.Request().Context()
+	userID := getUserIDFromContext(c)
+	chatJID := decodeChatJID(c)
+	deleted, err := h.db.DeleteThreadNote(ctx, db.DeleteThreadNoteParams{
+		ID:       noteID,
+		DeviceID: clientID,
+		UserID:   userID,
+		ChatJid:  chatJID,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "Note not found"})
+	}
+
+	wa.RecordThreadAudit(ctx, h.db, clientID, chatJID, userID, wa.ThreadActionNoteDeleted, noteID.String(), "")
+
+	return c.JSON(http.StatusOK, GenericResponse{Message: "Note deleted successfully"})
+}
+
+// GetThreadAuditLogs returns the audit trail of a thread
+// @Summary List thread audit trail
+// @Description Get the assignment, status and note changes made on a thread
+// @Tags team-inbox
+// @Accept json
+// @Produce json
+// @Param client_id path string true "Device ID"
+// @Param chat_jid path string true "Chat JID"
+// @Success 200 {array} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/inbox/{client_id}/threads/{chat_jid}/audit [get]
+// @Security BearerAuth
+func (h *TeamInboxHandler) GetThreadAuditLogs(c echo.Context) error {
+	clientID, status, err := authorizeMember(c, h.deviceStore)
+	if err != nil {
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
+	logs, err := h.db.GetThreadAuditLogs(c.Request().Context(), db.GetThreadAuditLogsParams{
+		DeviceID: clientID,
+		ChatJid:  decodeChatJID(c),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+
+	if logs == nil {
+		logs = []db.GetThreadAuditLogsRow{}
+	}
+
+	return c.JSON(http.StatusOK, logs)
+}
+
+func formatUserID(id pgtype.Int4) string {
+	if !id.Valid {
+		return ""
+	}
+	return strconv.Itoa(int(id.Int32))
+}
@@ -0,0 +1,11 @@
+UPDATE broadcast_recipients SET status = 'failed' WHERE status = 'skipped';
+ALTER TABLE broadcast_recipients DROP CONSTRAINT IF EXISTS broadcast_recipients_status_check;
+ALTER TABLE broadcast_recipients
+    ADD CONSTRAINT broadcast_recipients_status_check CHECK (status IN ('pending', 'sent', 'failed'));
+
+ALTER TABLE broadcast_jobs DROP COLUMN IF EXISTS is_transactional;
+
+DROP TRIGGER IF EXISTS update_opt_out_settings_updated_at ON opt_out_settings;
+DROP TABLE IF EXISTS opt_out_settings;
+DROP INDEX IF EXISTS idx_opt_outs_user_id;
+DROP TABLE IF EXISTS opt_outs;
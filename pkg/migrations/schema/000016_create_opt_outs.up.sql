@@ -0,0 +1,37 @@
+-- Per-user suppression list of recipients that asked not to be messaged
+CREATE TABLE IF NOT EXISTS opt_outs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    jid VARCHAR(255) NOT NULL,
+    device_id VARCHAR(255) REFERENCES clients(id) ON DELETE SET NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('keyword', 'manual', 'api')),
+    keyword VARCHAR(100),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, jid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_opt_outs_user_id ON opt_outs(user_id);
+
+-- Per-user keyword and confirmation settings
+CREATE TABLE IF NOT EXISTS opt_out_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    keywords TEXT[] NOT NULL DEFAULT '{STOP,UNSUBSCRIBE,BERHENTI}',
+    send_confirmation BOOLEAN NOT NULL DEFAULT FALSE,
+    confirmation_message TEXT NOT NULL DEFAULT 'You have been unsubscribed and will no longer receive messages from us.',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_opt_out_settings_updated_at
+    BEFORE UPDATE ON opt_out_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Broadcasts flagged as transactional bypass the suppression list
+ALTER TABLE broadcast_jobs
+    ADD COLUMN is_transactional BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE broadcast_recipients DROP CONSTRAINT IF EXISTS broadcast_recipients_status_check;
+ALTER TABLE broadcast_recipients
+    ADD CONSTRAINT broadcast_recipients_status_check CHECK (status IN ('pending', 'sent', 'failed', 'skipped'));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS auto_reply_cooldowns;
+DROP TRIGGER IF EXISTS update_auto_reply_rules_updated_at ON auto_reply_rules;
+DROP INDEX IF EXISTS idx_auto_reply_rules_device_priority;
+DROP TABLE IF EXISTS auto_reply_rules;
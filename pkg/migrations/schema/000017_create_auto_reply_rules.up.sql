@@ -0,0 +1,40 @@
+-- Per-device keyword and rule-based auto-replies
+CREATE TABLE IF NOT EXISTS auto_reply_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    match_type VARCHAR(20) NOT NULL DEFAULT 'contains' CHECK (match_type IN ('any', 'exact', 'contains', 'regex')),
+    match_value TEXT NOT NULL DEFAULT '',
+    case_sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    message_types TEXT[] NOT NULL DEFAULT '{}',
+    chat_scope VARCHAR(20) NOT NULL DEFAULT 'individual' CHECK (chat_scope IN ('individual', 'group', 'all')),
+    sender_jids TEXT[] NOT NULL DEFAULT '{}',
+    group_jids TEXT[] NOT NULL DEFAULT '{}',
+    hours_condition VARCHAR(20) NOT NULL DEFAULT 'always' CHECK (hours_condition IN ('always', 'inside', 'outside')),
+    business_hours JSONB,
+    reply_type VARCHAR(20) NOT NULL DEFAULT 'text' CHECK (reply_type IN ('text', 'template', 'media', 'reaction')),
+    reply_text TEXT NOT NULL DEFAULT '',
+    template_id UUID REFERENCES message_templates(id) ON DELETE SET NULL,
+    media_url TEXT,
+    media_filename VARCHAR(255),
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_reply_rules_device_priority ON auto_reply_rules(device_id, priority DESC);
+
+CREATE TRIGGER update_auto_reply_rules_updated_at
+    BEFORE UPDATE ON auto_reply_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Last time each rule replied in a chat, used to enforce per-chat cooldowns
+CREATE TABLE IF NOT EXISTS auto_reply_cooldowns (
+    rule_id UUID NOT NULL REFERENCES auto_reply_rules(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    last_replied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rule_id, chat_jid)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE message_threads DROP COLUMN IF EXISTS away_sent_at;
+DROP TRIGGER IF EXISTS update_away_message_settings_updated_at ON away_message_settings;
+DROP TABLE IF EXISTS away_message_settings;
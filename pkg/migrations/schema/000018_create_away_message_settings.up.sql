@@ -0,0 +1,19 @@
+-- Per-device away message sent outside business hours
+CREATE TABLE IF NOT EXISTS away_message_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    message TEXT NOT NULL DEFAULT '',
+    business_hours JSONB NOT NULL DEFAULT '{}',
+    resend_interval_minutes INTEGER NOT NULL DEFAULT 1440,
+    include_groups BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_away_message_settings_updated_at
+    BEFORE UPDATE ON away_message_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- When the last away message went out in a thread; cleared when an agent replies
+ALTER TABLE message_threads ADD COLUMN IF NOT EXISTS away_sent_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_flow_sessions_updated_at ON flow_sessions;
+DROP INDEX IF EXISTS idx_flow_sessions_status_activity;
+DROP INDEX IF EXISTS idx_flow_sessions_open_chat;
+DROP TABLE IF EXISTS flow_sessions;
+DROP TABLE IF EXISTS flow_versions;
+DROP TRIGGER IF EXISTS update_flows_updated_at ON flows;
+DROP INDEX IF EXISTS idx_flows_device_id;
+DROP TABLE IF EXISTS flows;
@@ -0,0 +1,54 @@
+-- Multi-step conversational flows; the definition of each flow is versioned
+CREATE TABLE IF NOT EXISTS flows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL DEFAULT 'keyword' CHECK (trigger_type IN ('keyword', 'any')),
+    trigger_keywords TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    current_version INTEGER NOT NULL DEFAULT 0,
+    webhook_url TEXT,
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flows_device_id ON flows(device_id);
+
+CREATE TRIGGER update_flows_updated_at
+    BEFORE UPDATE ON flows
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS flow_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    flow_id UUID NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    definition JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(flow_id, version)
+);
+
+-- Per-chat progress through a flow, pinned to the version it started on
+CREATE TABLE IF NOT EXISTS flow_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    flow_id UUID NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+    flow_version INTEGER NOT NULL,
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    current_step VARCHAR(255) NOT NULL,
+    answers JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'handoff', 'expired', 'closed')),
+    last_activity_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A chat can only be in one open (active or handed off) session at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_flow_sessions_open_chat ON flow_sessions(device_id, chat_jid) WHERE status IN ('active', 'handoff');
+CREATE INDEX IF NOT EXISTS idx_flow_sessions_status_activity ON flow_sessions(status, last_activity_at);
+
+CREATE TRIGGER update_flow_sessions_updated_at
+    BEFORE UPDATE ON flow_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_bot_webhooks_updated_at ON bot_webhooks;
+DROP TABLE IF EXISTS bot_webhooks;
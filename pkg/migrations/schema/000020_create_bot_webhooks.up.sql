@@ -0,0 +1,17 @@
+-- Per-device bot endpoint that receives incoming messages and answers with replies
+CREATE TABLE IF NOT EXISTS bot_webhooks (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    url TEXT NOT NULL,
+    secret VARCHAR(255),
+    timeout_seconds INTEGER NOT NULL DEFAULT 10,
+    fallback_message TEXT,
+    include_groups BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_bot_webhooks_updated_at
+    BEFORE UPDATE ON bot_webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
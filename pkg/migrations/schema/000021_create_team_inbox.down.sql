@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_thread_audit_logs_chat;
+DROP TABLE IF EXISTS thread_audit_logs;
+DROP INDEX IF EXISTS idx_thread_notes_chat;
+DROP TABLE IF EXISTS thread_notes;
+DROP INDEX IF EXISTS idx_message_threads_device_assignee;
+ALTER TABLE message_threads
+    DROP COLUMN IF EXISTS assigned_user_id,
+    DROP COLUMN IF EXISTS status;
+DROP INDEX IF EXISTS idx_device_members_user_id;
+DROP TABLE IF EXISTS device_members;
@@ -0,0 +1,41 @@
+-- Team members that may work a device's inbox besides its owner
+CREATE TABLE IF NOT EXISTS device_members (
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_members_user_id ON device_members(user_id);
+
+ALTER TABLE message_threads
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'pending', 'resolved')),
+    ADD COLUMN IF NOT EXISTS assigned_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_message_threads_device_assignee ON message_threads(device_id, assigned_user_id);
+
+-- Internal notes on a thread; never sent to WhatsApp
+CREATE TABLE IF NOT EXISTS thread_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_notes_chat ON thread_notes(device_id, chat_jid, created_at);
+
+-- Who changed what on a thread; user_id is NULL for changes made by the system
+CREATE TABLE IF NOT EXISTS thread_audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_audit_logs_chat ON thread_audit_logs(device_id, chat_jid, created_at);
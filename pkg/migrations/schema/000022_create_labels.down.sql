@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_thread_labels_label_id;
+DROP TABLE IF EXISTS thread_labels;
+DROP TRIGGER IF EXISTS update_labels_updated_at ON labels;
+DROP TABLE IF EXISTS labels;
+ALTER TABLE message_threads
+    DROP COLUMN IF EXISTS muted_until,
+    DROP COLUMN IF EXISTS is_muted,
+    DROP COLUMN IF EXISTS is_archived,
+    DROP COLUMN IF EXISTS is_pinned;
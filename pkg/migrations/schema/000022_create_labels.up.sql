@@ -0,0 +1,32 @@
+ALTER TABLE message_threads
+    ADD COLUMN IF NOT EXISTS is_pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS is_muted BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS muted_until TIMESTAMPTZ;
+
+-- Chat labels, mirrored with the WhatsApp Business labels of the device
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    wa_label_id VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    color INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, wa_label_id)
+);
+
+CREATE TRIGGER update_labels_updated_at
+    BEFORE UPDATE ON labels
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS thread_labels (
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    label_id UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, chat_jid, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_labels_label_id ON thread_labels(label_id);
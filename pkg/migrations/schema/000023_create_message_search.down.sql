@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS message_logs_search_index ON message_logs;
+DROP FUNCTION IF EXISTS index_message_search();
+DROP INDEX IF EXISTS idx_message_search_document;
+DROP TABLE IF EXISTS message_search;
+DROP TRIGGER IF EXISTS update_search_settings_updated_at ON search_settings;
+DROP TABLE IF EXISTS search_settings;
@@ -0,0 +1,52 @@
+-- Text search configuration used to index a device's messages (e.g. 'english', 'indonesian')
+CREATE TABLE IF NOT EXISTS search_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    language VARCHAR(64) NOT NULL DEFAULT 'simple',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_search_settings_updated_at
+    BEFORE UPDATE ON search_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Full-text document of each message: its text or caption (weight A) and media filename (weight B)
+CREATE TABLE IF NOT EXISTS message_search (
+    message_id UUID PRIMARY KEY REFERENCES message_logs(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_search_document ON message_search USING GIN (document);
+
+CREATE OR REPLACE FUNCTION index_message_search()
+RETURNS TRIGGER AS $$
+DECLARE
+    cfg REGCONFIG;
+BEGIN
+    SELECT language::regconfig INTO cfg FROM search_settings WHERE device_id = NEW.device_id;
+    cfg := COALESCE(cfg, 'simple'::regconfig);
+
+    INSERT INTO message_search (message_id, document)
+    VALUES (
+        NEW.id,
+        setweight(to_tsvector(cfg, COALESCE(NEW.content, '')), 'A') ||
+        setweight(to_tsvector(cfg, COALESCE(NEW.media_filename, '')), 'B')
+    )
+    ON CONFLICT (message_id) DO UPDATE SET document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER message_logs_search_index
+    AFTER INSERT OR UPDATE OF content, media_filename ON message_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION index_message_search();
+
+INSERT INTO message_search (message_id, document)
+SELECT id,
+       setweight(to_tsvector('simple', COALESCE(content, '')), 'A') ||
+       setweight(to_tsvector('simple', COALESCE(media_filename, '')), 'B')
+FROM message_logs
+ON CONFLICT (message_id) DO NOTHING;
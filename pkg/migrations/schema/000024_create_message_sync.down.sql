@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS message_reactions_track_change ON message_reactions;
+DROP FUNCTION IF EXISTS track_message_reaction_change();
+DROP TRIGGER IF EXISTS message_logs_track_delete ON message_logs;
+DROP TRIGGER IF EXISTS message_logs_track_update ON message_logs;
+DROP TRIGGER IF EXISTS message_logs_track_insert ON message_logs;
+DROP FUNCTION IF EXISTS track_message_change();
+DROP TABLE IF EXISTS message_reactions;
+DROP INDEX IF EXISTS idx_message_changes_device_seq;
+DROP TABLE IF EXISTS message_changes;
+DROP SEQUENCE IF EXISTS message_change_seq;
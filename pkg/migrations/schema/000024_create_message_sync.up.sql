@@ -0,0 +1,102 @@
+-- Sync tokens are positions in this sequence; every tracked change takes the next value
+CREATE SEQUENCE IF NOT EXISTS message_change_seq;
+
+-- Latest change of each message. Rows outlive deleted messages as tombstones so that
+-- clients syncing from an older token learn about the deletion.
+CREATE TABLE IF NOT EXISTS message_changes (
+    message_id UUID PRIMARY KEY,
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    chat_jid VARCHAR(255) NOT NULL,
+    created_seq BIGINT NOT NULL,
+    seq BIGINT NOT NULL,
+    edited_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_changes_device_seq ON message_changes (device_id, seq);
+
+-- One reaction per participant and message, as in WhatsApp
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id UUID NOT NULL REFERENCES message_logs(id) ON DELETE CASCADE,
+    sender_jid VARCHAR(255) NOT NULL,
+    emoji VARCHAR(64) NOT NULL,
+    reacted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (message_id, sender_jid)
+);
+
+CREATE OR REPLACE FUNCTION track_message_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    next_seq BIGINT;
+BEGIN
+    next_seq := nextval('message_change_seq');
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO message_changes (message_id, device_id, chat_jid, created_seq, seq)
+        VALUES (NEW.id, NEW.device_id, NEW.recipient, next_seq, next_seq);
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE message_changes
+        SET seq = next_seq,
+            edited_at = CASE WHEN OLD.content IS DISTINCT FROM NEW.content THEN NOW() ELSE edited_at END,
+            changed_at = NOW()
+        WHERE message_id = NEW.id;
+        RETURN NEW;
+    END IF;
+
+    -- A plain UPDATE never inserts, so a tombstone is not recreated while the
+    -- device itself is being deleted
+    UPDATE message_changes
+    SET seq = next_seq, deleted_at = NOW(), changed_at = NOW()
+    WHERE message_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER message_logs_track_insert
+    AFTER INSERT ON message_logs
+    FOR EACH ROW
+    WHEN (NEW.device_id IS NOT NULL)
+    EXECUTE FUNCTION track_message_change();
+
+CREATE TRIGGER message_logs_track_update
+    AFTER UPDATE ON message_logs
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status
+        OR OLD.content IS DISTINCT FROM NEW.content
+        OR OLD.media_url IS DISTINCT FROM NEW.media_url)
+    EXECUTE FUNCTION track_message_change();
+
+CREATE TRIGGER message_logs_track_delete
+    AFTER DELETE ON message_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION track_message_change();
+
+CREATE OR REPLACE FUNCTION track_message_reaction_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE message_changes
+    SET seq = nextval('message_change_seq'), changed_at = NOW()
+    WHERE message_id = COALESCE(NEW.message_id, OLD.message_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER message_reactions_track_change
+    AFTER INSERT OR UPDATE OR DELETE ON message_reactions
+    FOR EACH ROW
+    EXECUTE FUNCTION track_message_reaction_change();
+
+INSERT INTO message_changes (message_id, device_id, chat_jid, created_seq, seq)
+SELECT id, device_id, recipient, seq, seq
+FROM (
+    SELECT id, device_id, recipient, nextval('message_change_seq') AS seq
+    FROM (
+        SELECT id, device_id, recipient
+        FROM message_logs
+        WHERE device_id IS NOT NULL
+        ORDER BY sent_at, id
+    ) ordered
+) numbered
+ON CONFLICT (message_id) DO NOTHING;
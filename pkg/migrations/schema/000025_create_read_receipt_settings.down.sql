@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_read_receipt_settings_updated_at ON read_receipt_settings;
+DROP TABLE IF EXISTS read_receipt_settings;
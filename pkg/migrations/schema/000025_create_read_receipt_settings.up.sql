@@ -0,0 +1,12 @@
+-- Whether marking a chat as read in Kontak sends read receipts (blue ticks) to the sender
+CREATE TABLE IF NOT EXISTS read_receipt_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_read_receipt_settings_updated_at
+    BEFORE UPDATE ON read_receipt_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+ALTER TABLE whatsapp_contacts
+    DROP COLUMN IF EXISTS last_seen_at,
+    DROP COLUMN IF EXISTS is_online;
@@ -0,0 +1,4 @@
+-- Presence of contacts as last reported by WhatsApp
+ALTER TABLE whatsapp_contacts
+    ADD COLUMN IF NOT EXISTS is_online BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
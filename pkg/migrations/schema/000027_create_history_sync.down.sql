@@ -0,0 +1,8 @@
+ALTER TABLE message_logs DROP CONSTRAINT IF EXISTS message_logs_message_type_check;
+ALTER TABLE message_logs ADD CONSTRAINT message_logs_message_type_check
+    CHECK (message_type IN ('text', 'image', 'video', 'document', 'button')) NOT VALID;
+
+DROP TABLE IF EXISTS message_media;
+DROP TABLE IF EXISTS history_sync_progress;
+DROP TRIGGER IF EXISTS update_history_sync_settings_updated_at ON history_sync_settings;
+DROP TABLE IF EXISTS history_sync_settings;
@@ -0,0 +1,45 @@
+-- Per-device limits for importing chat history after pairing
+CREATE TABLE IF NOT EXISTS history_sync_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    max_days INT NOT NULL DEFAULT 90 CHECK (max_days > 0),
+    max_messages_per_chat INT NOT NULL DEFAULT 500 CHECK (max_messages_per_chat > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_history_sync_settings_updated_at
+    BEFORE UPDATE ON history_sync_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Import progress of the latest history sync of a device, updated per chunk
+CREATE TABLE IF NOT EXISTS history_sync_progress (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    sync_type VARCHAR(32) NOT NULL,
+    chunk_order INT NOT NULL DEFAULT 0,
+    progress INT NOT NULL DEFAULT 0,
+    conversations INT NOT NULL DEFAULT 0,
+    messages INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- Media keys of messages whose media has not been downloaded yet
+CREATE TABLE IF NOT EXISTS message_media (
+    message_id UUID PRIMARY KEY REFERENCES message_logs(id) ON DELETE CASCADE,
+    media_type VARCHAR(20) NOT NULL,
+    mimetype VARCHAR(255),
+    direct_path TEXT NOT NULL,
+    media_key BYTEA NOT NULL,
+    file_enc_sha256 BYTEA,
+    file_sha256 BYTEA,
+    file_length BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Voice notes are part of most chat histories
+ALTER TABLE message_logs DROP CONSTRAINT IF EXISTS message_logs_message_type_check;
+ALTER TABLE message_logs ADD CONSTRAINT message_logs_message_type_check
+    CHECK (message_type IN ('text', 'image', 'video', 'document', 'audio', 'button'));
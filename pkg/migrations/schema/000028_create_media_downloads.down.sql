@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS update_media_download_settings_updated_at ON media_download_settings;
+DROP TABLE IF EXISTS media_download_settings;
+
+DROP TRIGGER IF EXISTS update_message_media_updated_at ON message_media;
+DROP INDEX IF EXISTS idx_message_media_due;
+ALTER TABLE message_media
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS status;
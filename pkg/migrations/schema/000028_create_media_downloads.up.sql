@@ -0,0 +1,29 @@
+-- Media downloads run from a queue; rows stay after the download so the media can be fetched again
+ALTER TABLE message_media
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'on_demand'
+        CHECK (status IN ('pending', 'on_demand', 'retry_requested', 'downloaded', 'failed')),
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMPTZ,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_message_media_due ON message_media (next_attempt_at)
+    WHERE status IN ('pending', 'retry_requested');
+
+CREATE TRIGGER update_message_media_updated_at
+    BEFORE UPDATE ON message_media
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Media types downloaded as soon as a message arrives; other types are downloaded when first opened
+CREATE TABLE IF NOT EXISTS media_download_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    auto_download_types TEXT[] NOT NULL DEFAULT '{image,video,document,audio}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_media_download_settings_updated_at
+    BEFORE UPDATE ON media_download_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,21 @@
+-- Stored media objects per device. Objects are content addressed, so two devices receiving
+-- the same file share one object; a device may only read the objects listed for it.
+CREATE TABLE IF NOT EXISTS media_objects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) NOT NULL,
+    sha256 BYTEA,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    mimetype VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, storage_key)
+);
+
+CREATE INDEX idx_media_objects_storage_key ON media_objects (storage_key);
+
+-- Media saved before content addressing keeps its file name as key
+INSERT INTO media_objects (device_id, storage_key)
+SELECT DISTINCT device_id, substring(media_url FROM 12)
+FROM message_logs
+WHERE media_url LIKE '/api/media/%' AND device_id IS NOT NULL
+ON CONFLICT (device_id, storage_key) DO NOTHING;
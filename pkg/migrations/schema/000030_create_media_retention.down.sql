@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS media_cleanup_runs;
+DROP TABLE IF EXISTS media_retention_settings;
+
+UPDATE message_media SET status = 'failed' WHERE status = 'deleted';
+ALTER TABLE message_media DROP CONSTRAINT message_media_status_check;
+ALTER TABLE message_media ADD CONSTRAINT message_media_status_check
+    CHECK (status IN ('pending', 'on_demand', 'retry_requested', 'downloaded', 'failed'));
+
+DROP INDEX IF EXISTS idx_message_logs_media_url;
+ALTER TABLE message_logs DROP COLUMN IF EXISTS media_deleted_at;
@@ -0,0 +1,40 @@
+-- Media removed by retention rules or quotas leaves the message behind as a placeholder:
+-- media_url is cleared and media_deleted_at records when the media was removed
+ALTER TABLE message_logs ADD COLUMN media_deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_message_logs_media_url ON message_logs (device_id, media_url)
+    WHERE media_url IS NOT NULL;
+
+ALTER TABLE message_media DROP CONSTRAINT message_media_status_check;
+ALTER TABLE message_media ADD CONSTRAINT message_media_status_check
+    CHECK (status IN ('pending', 'on_demand', 'retry_requested', 'downloaded', 'failed', 'deleted'));
+
+-- Retention in days per media type and a storage quota per device; NULL keeps media forever
+CREATE TABLE IF NOT EXISTS media_retention_settings (
+    device_id VARCHAR(255) PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    image_retention_days INT CHECK (image_retention_days > 0),
+    video_retention_days INT CHECK (video_retention_days > 0),
+    document_retention_days INT CHECK (document_retention_days > 0),
+    audio_retention_days INT CHECK (audio_retention_days > 0),
+    quota_bytes BIGINT CHECK (quota_bytes > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_media_retention_settings_updated_at
+    BEFORE UPDATE ON media_retention_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- What the media janitor removed, per device and run
+CREATE TABLE IF NOT EXISTS media_cleanup_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('retention', 'device_quota', 'user_quota')),
+    messages_cleared INT NOT NULL DEFAULT 0,
+    objects_deleted INT NOT NULL DEFAULT 0,
+    bytes_freed BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_cleanup_runs_device ON media_cleanup_runs (device_id, created_at DESC);
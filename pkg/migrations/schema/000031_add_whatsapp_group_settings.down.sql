@@ -0,0 +1,3 @@
+ALTER TABLE whatsapp_groups
+    DROP COLUMN IF EXISTS is_locked,
+    DROP COLUMN IF EXISTS is_announce;
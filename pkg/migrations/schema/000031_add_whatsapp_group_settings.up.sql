@@ -0,0 +1,4 @@
+-- Group settings managed through the group API
+ALTER TABLE whatsapp_groups
+    ADD COLUMN is_announce BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN is_locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS group_events;
+DROP TABLE IF EXISTS group_participants;
@@ -0,0 +1,39 @@
+-- Current participants of the groups a device is in. participant_jid is the JID WhatsApp
+-- addresses the participant with in the group, which is a LID in LID groups.
+CREATE TABLE IF NOT EXISTS group_participants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    group_jid VARCHAR(255) NOT NULL,
+    participant_jid VARCHAR(255) NOT NULL,
+    phone_jid VARCHAR(255),
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'admin', 'superadmin')),
+    joined_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, group_jid, participant_jid)
+);
+
+CREATE INDEX idx_group_participants_phone ON group_participants (device_id, group_jid, phone_jid)
+    WHERE phone_jid IS NOT NULL;
+
+CREATE TRIGGER update_group_participants_updated_at
+    BEFORE UPDATE ON group_participants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Membership and info changes of groups as they happen; value holds the new name or
+-- description, or the join reason
+CREATE TABLE IF NOT EXISTS group_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    group_jid VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('join', 'leave', 'promote', 'demote', 'name', 'description')),
+    participant_jid VARCHAR(255),
+    phone_jid VARCHAR(255),
+    actor_jid VARCHAR(255),
+    value TEXT,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_group_events_group ON group_events (device_id, group_jid, occurred_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_whatsapp_groups_parent;
+
+ALTER TABLE whatsapp_groups
+    DROP COLUMN IF EXISTS is_announcement_group,
+    DROP COLUMN IF EXISTS parent_group_id,
+    DROP COLUMN IF EXISTS is_community;
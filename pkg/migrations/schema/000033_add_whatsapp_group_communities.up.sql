@@ -0,0 +1,9 @@
+-- Communities are groups with is_community set; their subgroups point at them with
+-- parent_group_id, and the announcement group is the community's default subgroup
+ALTER TABLE whatsapp_groups
+    ADD COLUMN is_community BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN parent_group_id VARCHAR(255),
+    ADD COLUMN is_announcement_group BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_whatsapp_groups_parent ON whatsapp_groups (device_id, parent_group_id)
+    WHERE parent_group_id IS NOT NULL;
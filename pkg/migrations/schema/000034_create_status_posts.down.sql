@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS status_views;
+DROP TABLE IF EXISTS status_posts;
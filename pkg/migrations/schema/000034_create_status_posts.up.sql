@@ -0,0 +1,26 @@
+-- Status (stories) updates posted through the API. viewers is the audience the post was
+-- restricted to, NULL when it went to everyone the status privacy setting shares with.
+CREATE TABLE IF NOT EXISTS status_posts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    wa_message_id VARCHAR(255) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('text', 'image', 'video')),
+    content TEXT NOT NULL DEFAULT '',
+    background_color VARCHAR(9),
+    font VARCHAR(30),
+    viewers TEXT[],
+    posted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, wa_message_id)
+);
+
+CREATE INDEX idx_status_posts_device ON status_posts (device_id, posted_at DESC);
+
+-- Who viewed a status update, from the read and played receipts of its recipients
+CREATE TABLE IF NOT EXISTS status_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status_post_id UUID NOT NULL REFERENCES status_posts(id) ON DELETE CASCADE,
+    viewer_jid VARCHAR(255) NOT NULL,
+    phone_jid VARCHAR(255),
+    viewed_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (status_post_id, viewer_jid)
+);
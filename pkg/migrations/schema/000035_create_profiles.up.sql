@@ -0,0 +1,28 @@
+-- Profiles of contacts and groups as WhatsApp shows them. picture_url points to the cached
+-- copy of the picture in the media storage; business columns are only set for businesses.
+CREATE TABLE IF NOT EXISTS profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    jid VARCHAR(255) NOT NULL,
+    picture_id VARCHAR(64),
+    picture_url TEXT,
+    picture_updated_at TIMESTAMPTZ,
+    about TEXT,
+    about_updated_at TIMESTAMPTZ,
+    is_business BOOLEAN NOT NULL DEFAULT FALSE,
+    business_category VARCHAR(255),
+    business_description TEXT,
+    business_address TEXT,
+    business_email VARCHAR(255),
+    business_websites TEXT[],
+    business_hours JSONB,
+    business_timezone VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, jid)
+);
+
+CREATE TRIGGER update_profiles_updated_at
+    BEFORE UPDATE ON profiles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
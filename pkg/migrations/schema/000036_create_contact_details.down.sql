@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contact_details;
+DROP TABLE IF EXISTS contact_attributes;
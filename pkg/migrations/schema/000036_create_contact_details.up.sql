@@ -0,0 +1,44 @@
+-- Custom attributes a device keeps on its contacts. Values in contact_details.attributes
+-- are checked against these definitions when they are written.
+CREATE TABLE IF NOT EXISTS contact_attributes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    key VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('text', 'number', 'boolean', 'date', 'select')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, key)
+);
+
+-- What the user knows about a contact. Kept apart from whatsapp_contacts, which is
+-- overwritten by every contact sync.
+CREATE TABLE IF NOT EXISTS contact_details (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id VARCHAR(255) NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    jid VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    external_id VARCHAR(255),
+    notes TEXT,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    attributes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (device_id, jid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_details_external_id ON contact_details(device_id, external_id);
+CREATE INDEX IF NOT EXISTS idx_contact_details_tags ON contact_details USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_contact_details_attributes ON contact_details USING GIN (attributes jsonb_path_ops);
+
+CREATE TRIGGER update_contact_attributes_updated_at
+    BEFORE UPDATE ON contact_attributes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_contact_details_updated_at
+    BEFORE UPDATE ON contact_details
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
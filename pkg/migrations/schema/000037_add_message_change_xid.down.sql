@@ -0,0 +1,39 @@
+CREATE OR REPLACE FUNCTION track_message_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    next_seq BIGINT;
+BEGIN
+    next_seq := nextval('message_change_seq');
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO message_changes (message_id, device_id, chat_jid, created_seq, seq)
+        VALUES (NEW.id, NEW.device_id, NEW.recipient, next_seq, next_seq);
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE message_changes
+        SET seq = next_seq,
+            edited_at = CASE WHEN OLD.content IS DISTINCT FROM NEW.content THEN NOW() ELSE edited_at END,
+            changed_at = NOW()
+        WHERE message_id = NEW.id;
+        RETURN NEW;
+    END IF;
+
+    UPDATE message_changes
+    SET seq = next_seq, deleted_at = NOW(), changed_at = NOW()
+    WHERE message_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION track_message_reaction_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE message_changes
+    SET seq = nextval('message_change_seq'), changed_at = NOW()
+    WHERE message_id = COALESCE(NEW.message_id, OLD.message_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_message_changes_device_xid_seq;
+ALTER TABLE message_changes DROP COLUMN IF EXISTS created_xid, DROP COLUMN IF EXISTS xid;
@@ -0,0 +1,53 @@
+-- Sequence values are taken before a transaction commits, so changes can become visible
+-- out of seq order. Changes are ordered by the transaction that wrote them instead, and
+-- only returned once every older transaction has finished. Rows from before this
+-- migration keep xid 0 and stay ordered by seq.
+ALTER TABLE message_changes
+    ADD COLUMN IF NOT EXISTS xid BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS created_xid BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_message_changes_device_xid_seq ON message_changes (device_id, xid, seq);
+
+CREATE OR REPLACE FUNCTION track_message_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    next_seq BIGINT;
+    current_xid BIGINT;
+BEGIN
+    next_seq := nextval('message_change_seq');
+    current_xid := pg_current_xact_id()::text::bigint;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO message_changes (message_id, device_id, chat_jid, created_seq, seq, created_xid, xid)
+        VALUES (NEW.id, NEW.device_id, NEW.recipient, next_seq, next_seq, current_xid, current_xid);
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE message_changes
+        SET seq = next_seq,
+            xid = current_xid,
+            edited_at = CASE WHEN OLD.content IS DISTINCT FROM NEW.content THEN NOW() ELSE edited_at END,
+            changed_at = NOW()
+        WHERE message_id = NEW.id;
+        RETURN NEW;
+    END IF;
+
+    -- A plain UPDATE never inserts, so a tombstone is not recreated while the
+    -- device itself is being deleted
+    UPDATE message_changes
+    SET seq = next_seq, xid = current_xid, deleted_at = NOW(), changed_at = NOW()
+    WHERE message_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION track_message_reaction_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE message_changes
+    SET seq = nextval('message_change_seq'),
+        xid = pg_current_xact_id()::text::bigint,
+        changed_at = NOW()
+    WHERE message_id = COALESCE(NEW.message_id, OLD.message_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
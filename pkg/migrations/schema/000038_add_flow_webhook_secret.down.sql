@@ -0,0 +1 @@
+ALTER TABLE flows DROP COLUMN IF EXISTS webhook_secret;
@@ -0,0 +1,2 @@
+-- Secret the flow result webhook signs its requests with, as bot webhooks do
+ALTER TABLE flows ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(255);
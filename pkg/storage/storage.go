@@ -0,0 +1,84 @@
+// Package storage keeps message media in a pluggable backend, either the local filesystem
+// or an S3-compatible object store, under content-addressed keys.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist in the storage backend.
+var ErrNotFound = errors.New("media object not found")
+
+// Storage stores media objects by key.
+type Storage interface {
+	// Put stores data under key, replacing any object with the same key.
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get opens the object stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner is implemented by backends that can hand out temporary URLs, so clients
+// download media straight from the backend instead of through Kontak.
+type Presigner interface {
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Config selects and configures the storage backend.
+type Config struct {
+	// Driver is "local" (default) or "s3".
+	Driver string
+	// LocalDir is the directory of the local driver.
+	LocalDir string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle addresses the bucket in the path instead of the host name, as MinIO expects.
+	S3PathStyle bool
+}
+
+// New creates the storage backend selected by cfg.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalDir)
+	case "s3":
+		return NewS3Storage(cfg)
+	}
+	return nil, fmt.Errorf("unknown media storage driver %q", cfg.Driver)
+}
+
+var extPattern = regexp.MustCompile(`^\.[A-Za-z0-9]{1,10}$`)
+
+// validKey matches the keys produced by Key as well as the file names of media stored
+// before content addressing, such as "3EB0C4F1A2-image.jpg".
+var validKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+// Key returns the content-addressed key of data: its SHA256 followed by the extension of
+// filename, which keeps the media type recognizable. It also returns the hash.
+func Key(data []byte, filename string) (string, []byte) {
+	sum := sha256.Sum256(data)
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !extPattern.MatchString(ext) {
+		ext = ""
+	}
+	return hex.EncodeToString(sum[:]) + ext, sum[:]
+}
+
+// ValidKey reports whether key is safe to use as an object name.
+func ValidKey(key string) bool {
+	return validKey.MatchString(key) && !strings.Contains(key, "..")
+}
@@ -0,0 +1,267 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Auto-reply match types.
+const (
+	AutoReplyMatchAny      = "any"
+	AutoReplyMatchExact    = "exact"
+	AutoReplyMatchContains = "contains"
+	AutoReplyMatchRegex    = "regex"
+)
+
+// Auto-reply chat scopes.
+const (
+	AutoReplyScopeIndividual = "individual"
+	AutoReplyScopeGroup      = "group"
+	AutoReplyScopeAll        = "all"
+)
+
+// Auto-reply business hours conditions.
+const (
+	AutoReplyHoursAlways  = "always"
+	AutoReplyHoursInside  = "inside"
+	AutoReplyHoursOutside = "outside"
+)
+
+// Auto-reply reply types.
+const (
+	AutoReplyTypeText     = "text"
+	AutoReplyTypeTemplate = "template"
+	AutoReplyTypeMedia    = "media"
+	AutoReplyTypeReaction = "reaction"
+)
+
+var ErrInvalidAutoReplyRule = errors.New("invalid auto-reply rule")
+
+// ValidateAutoReplyRule checks that a rule's match and reply settings are consistent
+// before it is stored.
+func ValidateAutoReplyRule(ctx context.Context, rule db.CreateAutoReplyRuleParams) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidAutoReplyRule, fmt.Sprintf(format, args...))
+	}
+
+	switch rule.MatchType {
+	case AutoReplyMatchAny:
+	case AutoReplyMatchExact, AutoReplyMatchContains:
+		if rule.MatchValue == "" {
+			return invalid("match_value is required for match_type %s", rule.MatchType)
+		}
+	case AutoReplyMatchRegex:
+		if _, err := compilePattern(autoReplyPattern(rule.MatchValue, rule.CaseSensitive)); err != nil {
+			return invalid("match_value is not a valid regex: %v", err)
+		}
+	default:
+		return invalid("unknown match_type %q", rule.MatchType)
+	}
+
+	switch rule.ChatScope {
+	case AutoReplyScopeIndividual, AutoReplyScopeGroup, AutoReplyScopeAll:
+	default:
+		return invalid("unknown chat_scope %q", rule.ChatScope)
+	}
+
+	switch rule.HoursCondition {
+	case AutoReplyHoursAlways:
+	case AutoReplyHoursInside, AutoReplyHoursOutside:
+		if len(rule.BusinessHours) == 0 {
+			return invalid("business_hours is required for hours_condition %s", rule.HoursCondition)
+		}
+		if _, err := ParseBusinessHours(rule.BusinessHours); err != nil {
+			return invalid("%v", err)
+		}
+	default:
+		return invalid("unknown hours_condition %q", rule.HoursCondition)
+	}
+
+	switch rule.ReplyType {
+	case AutoReplyTypeText:
+		if rule.ReplyText == "" {
+			return invalid("reply_text is required for reply_type text")
+		}
+	case AutoReplyTypeTemplate:
+		if !rule.TemplateID.Valid {
+			return invalid("template_id is required for reply_type template")
+		}
+	case AutoReplyTypeMedia:
+		if !rule.MediaUrl.Valid || rule.MediaUrl.String == "" {
+			return invalid("media_url is required for reply_type media")
+		}
+		if err := ValidateMediaURL(ctx, rule.MediaUrl.String); err != nil {
+			return invalid("%v", err)
+		}
+	case AutoReplyTypeReaction:
+		if rule.ReplyText == "" {
+			return invalid("reply_text must hold the reaction emoji for reply_type reaction")
+		}
+	default:
+		return invalid("unknown reply_type %q", rule.ReplyType)
+	}
+
+	if rule.CooldownSeconds < 0 {
+		return invalid("cooldown_seconds must not be negative")
+	}
+	return nil
+}
+
+// NormalizeJIDs turns phone numbers and JIDs into their canonical JID form.
+func NormalizeJIDs(recipients []string) ([]string, error) {
+	normalized := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+		jid, err := getJID(recipient)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, jid.ToNonAD().String())
+	}
+	return normalized, nil
+}
+
+// MatchAutoReplyRule reports whether an incoming message satisfies every condition of the rule.
+func MatchAutoReplyRule(rule db.AutoReplyRule, evt *events.Message, messageType, text string, now time.Time) bool {
+	switch rule.ChatScope {
+	case AutoReplyScopeIndividual:
+		if evt.Info.IsGroup {
+			return false
+		}
+	case AutoReplyScopeGroup:
+		if !evt.Info.IsGroup {
+			return false
+		}
+	}
+
+	if len(rule.MessageTypes) > 0 && !slices.Contains(rule.MessageTypes, messageType) {
+		return false
+	}
+	if len(rule.SenderJids) > 0 && !slices.Contains(rule.SenderJids, evt.Info.Sender.ToNonAD().String()) {
+		return false
+	}
+	if len(rule.GroupJids) > 0 && !slices.Contains(rule.GroupJids, evt.Info.Chat.String()) {
+		return false
+	}
+
+	if rule.HoursCondition != AutoReplyHoursAlways {
+		hours, err := ParseBusinessHours(rule.BusinessHours)
+		if err != nil {
+			return false
+		}
+		if hours.IsOpen(now) != (rule.HoursCondition == AutoReplyHoursInside) {
+			return false
+		}
+	}
+
+	return matchAutoReplyText(rule, text)
+}
+
+// autoReplyPattern returns the regex a regex rule matches with.
+func autoReplyPattern(value string, caseSensitive bool) string {
+	if !caseSensitive {
+		return "(?i)" + value
+	}
+	return value
+}
+
+func matchAutoReplyText(rule db.AutoReplyRule, text string) bool {
+	value := rule.MatchValue
+	text = strings.TrimSpace(text)
+
+	switch rule.MatchType {
+	case AutoReplyMatchAny:
+		return true
+	case AutoReplyMatchExact:
+		if rule.CaseSensitive {
+			return text == value
+		}
+		return strings.EqualFold(text, value)
+	case AutoReplyMatchContains:
+		if rule.CaseSensitive {
+			return strings.Contains(text, value)
+		}
+		return strings.Contains(strings.ToLower(text), strings.ToLower(value))
+	case AutoReplyMatchRegex:
+		re, err := compilePattern(autoReplyPattern(value, rule.CaseSensitive))
+		if err != nil {
+			return false
+		}
+		return re.MatchString(text)
+	}
+	return false
+}
+
+// handleAutoReply evaluates the device's active rules in priority order and answers the
+// message with the first one that matches. A matching rule that is still cooling down
+// for the chat stops the evaluation so lower priority rules don't fire in its place.
+func (w *EventHandler) handleAutoReply(evt *events.Message, messageType, text string) {
+	ctx := context.Background()
+
+	rules, err := w.db.GetActiveAutoReplyRules(ctx, w.clientID)
+	if err != nil {
+		logger.Error("Failed to load auto-reply rules for device %s: %v", w.clientID, err)
+		return
+	}
+
+	now := time.Now()
+	chatJID := evt.Info.Chat.String()
+	for _, rule := range rules {
+		if !MatchAutoReplyRule(rule, evt, messageType, text, now) {
+			continue
+		}
+
+		if rule.CooldownSeconds > 0 {
+			_, err := w.db.ClaimAutoReplyCooldown(ctx, db.ClaimAutoReplyCooldownParams{
+				RuleID:          rule.ID,
+				ChatJid:         chatJID,
+				CooldownSeconds: rule.CooldownSeconds,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				logger.Debug("Auto-reply rule %s is cooling down in chat %s", rule.Name, chatJID)
+				return
+			}
+			if err != nil {
+				logger.Error("Failed to claim auto-reply cooldown for rule %s: %v", rule.Name, err)
+				return
+			}
+		}
+
+		if err := w.sendAutoReply(ctx, rule, evt); err != nil {
+			logger.Error("Failed to send auto-reply %s to %s: %v", rule.Name, chatJID, err)
+		} else {
+			logger.Info("Auto-reply rule %s answered message %s in chat %s", rule.Name, evt.Info.ID, chatJID)
+		}
+		return
+	}
+}
+
+func (w *EventHandler) sendAutoReply(ctx context.Context, rule db.AutoReplyRule, evt *events.Message) error {
+	chatJID := evt.Info.Chat.String()
+	variables := w.replyVariables(ctx, evt)
+
+	switch rule.ReplyType {
+	case AutoReplyTypeText:
+		return w.sendTextReply(ctx, chatJID, RenderTemplate(rule.ReplyText, variables))
+	case AutoReplyTypeTemplate:
+		return w.sendTemplateReply(ctx, chatJID, rule.TemplateID, variables)
+	case AutoReplyTypeMedia:
+		return w.sendMediaReply(ctx, chatJID, rule.MediaUrl.String, rule.MediaFilename.String)
+	case AutoReplyTypeReaction:
+		_, err := w.client.SendReaction(w.clientID, evt.Info.Chat, evt.Info.Sender, evt.Info.ID, rule.ReplyText)
+		return err
+	}
+	return fmt.Errorf("unknown reply type %q", rule.ReplyType)
+}
@@ -0,0 +1,77 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// DefaultAwayResendInterval is how long, in minutes, a chat waits before it can receive another away message.
+const DefaultAwayResendInterval = 24 * 60
+
+// DefaultAwayMessageSettings returns the disabled settings used for devices that never configured an away message.
+func DefaultAwayMessageSettings(deviceID string) db.AwayMessageSetting {
+	return db.AwayMessageSetting{
+		DeviceID:              deviceID,
+		BusinessHours:         []byte("{}"),
+		ResendIntervalMinutes: DefaultAwayResendInterval,
+	}
+}
+
+// handleAwayMessage answers an incoming message with the device's away message when it
+// arrives outside business hours. ClaimAwayMessage only succeeds for a thread that has not
+// had an away message within the resend interval, or whose last one was followed by an
+// agent reply, so a burst of messages gets a single away reply.
+func (w *EventHandler) handleAwayMessage(evt *events.Message) {
+	ctx := context.Background()
+
+	settings, err := w.db.GetAwayMessageSettings(ctx, w.clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load away message settings for device %s: %v", w.clientID, err)
+		return
+	}
+	if !settings.IsEnabled || settings.Message == "" {
+		return
+	}
+	if evt.Info.IsGroup && !settings.IncludeGroups {
+		return
+	}
+
+	hours, err := ParseBusinessHours(settings.BusinessHours)
+	if err != nil {
+		logger.Error("Invalid business hours for device %s: %v", w.clientID, err)
+		return
+	}
+	if hours.IsOpen(time.Now()) {
+		return
+	}
+
+	chatJID := evt.Info.Chat.String()
+	_, err = w.db.ClaimAwayMessage(ctx, db.ClaimAwayMessageParams{
+		DeviceID:              w.clientID,
+		ChatJid:               chatJID,
+		ResendIntervalMinutes: settings.ResendIntervalMinutes,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to claim away message for chat %s: %v", chatJID, err)
+		return
+	}
+
+	message := RenderTemplate(settings.Message, w.replyVariables(ctx, evt))
+	if err := w.sendTextReply(ctx, chatJID, message); err != nil {
+		logger.Error("Failed to send away message to %s: %v", chatJID, err)
+		return
+	}
+	logger.Info("Sent away message to chat %s on device %s", chatJID, w.clientID)
+}
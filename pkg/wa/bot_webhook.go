@@ -0,0 +1,260 @@
+package wa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Bot reply types.
+const (
+	BotReplyText     = "text"
+	BotReplyMedia    = "media"
+	BotReplyTemplate = "template"
+	BotReplyReaction = "reaction"
+)
+
+const (
+	// botMaxReplies caps how many replies a single bot response may send.
+	botMaxReplies = 5
+	// botChatRateLimit is how many messages per chat are forwarded to the bot within botRateWindow.
+	// Two bots answering each other hit this limit instead of looping forever.
+	botChatRateLimit = 10
+	botRateWindow    = time.Minute
+	// botMediaURLExpiry is how long the signed media URL in a bot request stays valid.
+	botMediaURLExpiry = 24 * time.Hour
+)
+
+// BotWebhookRequest is posted to the bot endpoint for every incoming message.
+type BotWebhookRequest struct {
+	DeviceID    string    `json:"device_id"`
+	MessageID   string    `json:"message_id"`
+	ChatJID     string    `json:"chat_jid"`
+	SenderJID   string    `json:"sender_jid"`
+	SenderName  string    `json:"sender_name"`
+	IsGroup     bool      `json:"is_group"`
+	MessageType string    `json:"message_type"`
+	Text        string    `json:"text"`
+	MediaURL    string    `json:"media_url,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// BotWebhookResponse is the body the bot endpoint answers with.
+type BotWebhookResponse struct {
+	Replies []BotReply `json:"replies"`
+}
+
+// BotReply is one message the bot wants sent back into the chat.
+type BotReply struct {
+	Type       string                 `json:"type"`
+	Text       string                 `json:"text,omitempty"`
+	MediaURL   string                 `json:"media_url,omitempty"`
+	FileName   string                 `json:"file_name,omitempty"`
+	TemplateID string                 `json:"template_id,omitempty"`
+	Variables  map[string]interface{} `json:"variables,omitempty"`
+	Emoji      string                 `json:"emoji,omitempty"`
+}
+
+// handleBotWebhook forwards the message to the device's bot endpoint, if one is enabled,
+// and reports whether the bot took ownership of the message. The request runs in the
+// background so a slow bot never stalls the device's event processing.
+func (w *EventHandler) handleBotWebhook(evt *events.Message, messageType, text string, messageID pgtype.UUID) bool {
+	ctx := context.Background()
+
+	bot, err := w.db.GetBotWebhook(ctx, w.clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to load bot webhook for device %s: %v", w.clientID, err)
+		return false
+	}
+	if !bot.IsEnabled || (evt.Info.IsGroup && !bot.IncludeGroups) {
+		return false
+	}
+
+	chatJID := evt.Info.Chat.String()
+	if !w.botLimiter.allow(chatJID, time.Now()) {
+		logger.Warn("Bot webhook rate limit reached for chat %s on device %s, dropping message %s", chatJID, w.clientID, evt.Info.ID)
+		return true
+	}
+
+	request := BotWebhookRequest{
+		DeviceID:    w.clientID,
+		MessageID:   evt.Info.ID,
+		ChatJID:     chatJID,
+		SenderJID:   evt.Info.Sender.ToNonAD().String(),
+		SenderName:  evt.Info.PushName,
+		IsGroup:     evt.Info.IsGroup,
+		MessageType: messageType,
+		Text:        text,
+		Timestamp:   evt.Info.Timestamp,
+	}
+	go w.forwardToBot(bot, request, messageID, evt)
+	return true
+}
+
+func (w *EventHandler) forwardToBot(bot db.BotWebhook, request BotWebhookRequest, messageID pgtype.UUID, evt *events.Message) {
+	ctx := context.Background()
+	chatJID := request.ChatJID
+
+	// Bots get the media right away, whatever the device's download settings
+	if request.MessageType != "text" && messageID.Valid {
+		mediaURL, err := w.client.FetchMessageMedia(ctx, messageID)
+		if err != nil {
+			logger.Warn("Forwarding message %s to bot without media: %v", request.MessageID, err)
+		}
+		request.MediaURL = w.client.SignMediaURL(mediaURL, botMediaURLExpiry)
+	}
+
+	replies, err := w.callBot(ctx, bot, request)
+	if err != nil {
+		logger.Error("Bot webhook for device %s failed on message %s: %v", w.clientID, request.MessageID, err)
+		if bot.FallbackMessage.Valid && bot.FallbackMessage.String != "" {
+			if err := w.sendTextReply(ctx, chatJID, bot.FallbackMessage.String); err != nil {
+				logger.Error("Failed to send bot fallback message to %s: %v", chatJID, err)
+			}
+		}
+		return
+	}
+
+	if len(replies) > botMaxReplies {
+		logger.Warn("Bot webhook for device %s returned %d replies, sending the first %d", w.clientID, len(replies), botMaxReplies)
+		replies = replies[:botMaxReplies]
+	}
+	for _, reply := range replies {
+		if err := w.sendBotReply(ctx, reply, evt); err != nil {
+			logger.Error("Failed to send %s bot reply to %s: %v", reply.Type, chatJID, err)
+		}
+	}
+}
+
+// callBot posts the message to the bot and decodes its replies within the configured timeout.
+func (w *EventHandler) callBot(ctx context.Context, bot db.BotWebhook, request BotWebhookRequest) ([]BotReply, error) {
+	timeout := time.Duration(bot.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = webhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := postJSON(ctx, bot.Url, bot.Secret.String, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var response BotWebhookResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid bot response: %w", err)
+	}
+	return response.Replies, nil
+}
+
+func (w *EventHandler) sendBotReply(ctx context.Context, reply BotReply, evt *events.Message) error {
+	chatJID := evt.Info.Chat.String()
+
+	switch reply.Type {
+	case BotReplyText, "":
+		if reply.Text == "" {
+			return errors.New("text reply without text")
+		}
+		return w.sendTextReply(ctx, chatJID, reply.Text)
+	case BotReplyMedia:
+		if reply.MediaURL == "" {
+			return errors.New("media reply without media_url")
+		}
+		if err := ValidateMediaURL(ctx, reply.MediaURL); err != nil {
+			return err
+		}
+		return w.sendMediaReply(ctx, chatJID, reply.MediaURL, reply.FileName)
+	case BotReplyTemplate:
+		templateID, err := w.ownedTemplateID(ctx, reply.TemplateID)
+		if err != nil {
+			return err
+		}
+		variables := w.replyVariables(ctx, evt)
+		for key, value := range reply.Variables {
+			variables[key] = value
+		}
+		return w.sendTemplateReply(ctx, chatJID, templateID, variables)
+	case BotReplyReaction:
+		_, err := w.client.SendReaction(w.clientID, evt.Info.Chat, evt.Info.Sender, evt.Info.ID, reply.Emoji)
+		return err
+	}
+	return fmt.Errorf("unknown reply type %q", reply.Type)
+}
+
+// ownedTemplateID parses a template ID from a bot reply and checks that the template
+// belongs to the owner of this device.
+func (w *EventHandler) ownedTemplateID(ctx context.Context, rawID string) (pgtype.UUID, error) {
+	templateID, err := String2PgUUID(rawID)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("invalid template_id %q", rawID)
+	}
+
+	owner, err := w.store.GetClient(ctx, w.clientID)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	template, err := w.db.GetMessageTemplateByID(ctx, templateID)
+	if err != nil || template.UserID.Int32 != owner.UserID.Int32 {
+		return pgtype.UUID{}, fmt.Errorf("template %s not found", rawID)
+	}
+	return templateID, nil
+}
+
+// chatRateLimiter counts events per chat over a sliding window.
+type chatRateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+func newChatRateLimiter(limit int, window time.Duration) *chatRateLimiter {
+	return &chatRateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// allow records an event for the chat and reports whether it is within the limit.
+func (l *chatRateLimiter) allow(chat string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	if len(l.hits) > 1000 {
+		for key, hits := range l.hits {
+			if len(hits) == 0 || !hits[len(hits)-1].After(cutoff) {
+				delete(l.hits, key)
+			}
+		}
+	}
+
+	recent := l.hits[chat][:0]
+	for _, hit := range l.hits[chat] {
+		if hit.After(cutoff) {
+			recent = append(recent, hit)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.hits[chat] = recent
+		return false
+	}
+	l.hits[chat] = append(recent, now)
+	return true
+}
@@ -2,6 +2,7 @@ package wa
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,14 +12,16 @@ import (
 )
 
 type BroadcastService struct {
-	db       db.Querier
-	waClient *WhatsappClient
+	db          db.Querier
+	waClient    *WhatsappClient
+	optOutStore *OptOutStore
 }
 
-func NewBroadcastService(db db.Querier, waClient *WhatsappClient) *BroadcastService {
+func NewBroadcastService(db db.Querier, waClient *WhatsappClient, optOutStore *OptOutStore) *BroadcastService {
 	return &BroadcastService{
-		db:       db,
-		waClient: waClient,
+		db:          db,
+		waClient:    waClient,
+		optOutStore: optOutStore,
 	}
 }
 
@@ -92,9 +95,27 @@ func (s *BroadcastService) processJob(ctx context.Context, job db.BroadcastJob)
 		default:
 		}
 
+		err := s.checkSuppression(ctx, job, recipient.RecipientJid)
+		if err != nil {
+			status := "failed"
+			if errors.Is(err, ErrRecipientOptedOut) {
+				status = "skipped"
+			}
+			logger.Info("Not sending broadcast to %s: %v", recipient.RecipientJid, err)
+			if err := s.db.UpdateBroadcastRecipientStatus(ctx, db.UpdateBroadcastRecipientStatusParams{
+				JobID:        job.ID,
+				RecipientJid: recipient.RecipientJid,
+				Status:       pgtype.Text{String: status, Valid: true},
+				ErrorMessage: pgtype.Text{String: err.Error(), Valid: true},
+			}); err != nil {
+				logger.Error("Failed to update recipient status: %v", err)
+			}
+			continue
+		}
+
 		logger.Info("Sending broadcast to recipient: %s", recipient.RecipientJid)
 
-		err := s.sendBroadcastMessage(job, recipient.RecipientJid)
+		err = s.sendBroadcastMessage(job, recipient.RecipientJid)
 		status := "sent"
 		errMsg := ""
 		if err != nil {
@@ -134,6 +155,23 @@ func (s *BroadcastService) processJob(ctx context.Context, job db.BroadcastJob)
 	}
 }
 
+// checkSuppression returns ErrRecipientOptedOut when the recipient is on the job owner's
+// suppression list. Transactional jobs are never suppressed.
+func (s *BroadcastService) checkSuppression(ctx context.Context, job db.BroadcastJob, recipientJid string) error {
+	if job.IsTransactional || !job.UserID.Valid {
+		return nil
+	}
+
+	optedOut, err := s.optOutStore.IsOptedOut(ctx, job.UserID.Int32, recipientJid)
+	if err != nil {
+		return fmt.Errorf("failed to check opt-out status: %w", err)
+	}
+	if optedOut {
+		return ErrRecipientOptedOut
+	}
+	return nil
+}
+
 func (s *BroadcastService) sendBroadcastMessage(job db.BroadcastJob, recipientJid string) error {
 	logger.Debug("sendBroadcastMessage: deviceID=%s recipient=%s", job.DeviceID.String, recipientJid)
 
@@ -0,0 +1,124 @@
+package wa
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BusinessHours describes the weekly opening hours of a business in its own timezone.
+// Holidays are "2006-01-02" dates on which the business is closed all day.
+type BusinessHours struct {
+	Timezone string        `json:"timezone"`
+	Windows  []HoursWindow `json:"windows"`
+	Holidays []string      `json:"holidays,omitempty"`
+}
+
+// HoursWindow is a daily opening window. Days use time.Weekday numbering (0 = Sunday)
+// and Start/End are "15:04" clock times; an End before Start spans midnight.
+type HoursWindow struct {
+	Days  []int  `json:"days"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// ParseBusinessHours decodes a JSON business hours definition and validates it.
+func ParseBusinessHours(raw []byte) (BusinessHours, error) {
+	var hours BusinessHours
+	if err := json.Unmarshal(raw, &hours); err != nil {
+		return BusinessHours{}, fmt.Errorf("invalid business hours: %w", err)
+	}
+	if err := hours.Validate(); err != nil {
+		return BusinessHours{}, err
+	}
+	return hours, nil
+}
+
+// Validate checks the timezone, days and clock times of every window.
+func (b BusinessHours) Validate() error {
+	if _, err := b.location(); err != nil {
+		return err
+	}
+	for _, window := range b.Windows {
+		for _, day := range window.Days {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("invalid business hours day %d", day)
+			}
+		}
+		if _, err := parseClock(window.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return err
+		}
+	}
+	for _, holiday := range b.Holidays {
+		if _, err := time.Parse(time.DateOnly, holiday); err != nil {
+			return fmt.Errorf("invalid business hours holiday %q", holiday)
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether t falls inside one of the opening windows.
+func (b BusinessHours) IsOpen(t time.Time) bool {
+	loc, err := b.location()
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+	if slices.Contains(b.Holidays, local.Format(time.DateOnly)) {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	today := int(local.Weekday())
+	yesterday := (today + 6) % 7
+
+	for _, window := range b.Windows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			continue
+		}
+
+		if start < end {
+			if slices.Contains(window.Days, today) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+
+		// Overnight window, e.g. 22:00-06:00
+		if slices.Contains(window.Days, today) && minute >= start {
+			return true
+		}
+		if slices.Contains(window.Days, yesterday) && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+func (b BusinessHours) location() (*time.Location, error) {
+	if b.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business hours timezone %q: %w", b.Timezone, err)
+	}
+	return loc, nil
+}
+
+// parseClock converts a "15:04" clock time into minutes after midnight.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business hours time %q", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
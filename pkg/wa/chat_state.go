@@ -0,0 +1,120 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// The handlers below mirror chat state changed on the phone (or any other linked device)
+// into message_threads so the Kontak inbox and WhatsApp stay in agreement. Chats without a
+// thread are ignored; they get one with their first message.
+
+func (w *EventHandler) handlePin(evt *events.Pin) {
+	if err := w.db.SetThreadPinned(context.Background(), db.SetThreadPinnedParams{
+		DeviceID: w.clientID,
+		ChatJid:  evt.JID.String(),
+		IsPinned: evt.Action.GetPinned(),
+	}); err != nil {
+		logger.Error("Failed to sync pin state of chat %s: %v", evt.JID, err)
+	}
+}
+
+func (w *EventHandler) handleArchive(evt *events.Archive) {
+	if err := w.db.SetThreadArchived(context.Background(), db.SetThreadArchivedParams{
+		DeviceID:   w.clientID,
+		ChatJid:    evt.JID.String(),
+		IsArchived: evt.Action.GetArchived(),
+	}); err != nil {
+		logger.Error("Failed to sync archive state of chat %s: %v", evt.JID, err)
+	}
+}
+
+func (w *EventHandler) handleMute(evt *events.Mute) {
+	muted := evt.Action.GetMuted()
+	if err := w.db.SetThreadMuted(context.Background(), db.SetThreadMutedParams{
+		DeviceID:   w.clientID,
+		ChatJid:    evt.JID.String(),
+		IsMuted:    muted,
+		MutedUntil: muteEnd(muted, evt.Action.GetMuteEndTimestamp()),
+	}); err != nil {
+		logger.Error("Failed to sync mute state of chat %s: %v", evt.JID, err)
+	}
+}
+
+func (w *EventHandler) handleLabelEdit(evt *events.LabelEdit) {
+	ctx := context.Background()
+
+	if evt.Action.GetDeleted() {
+		if err := w.db.DeleteLabelByWaID(ctx, db.DeleteLabelByWaIDParams{
+			DeviceID:  w.clientID,
+			WaLabelID: evt.LabelID,
+		}); err != nil {
+			logger.Error("Failed to delete label %s: %v", evt.LabelID, err)
+		}
+		return
+	}
+
+	if _, err := w.db.UpsertLabelByWaID(ctx, db.UpsertLabelByWaIDParams{
+		DeviceID:  w.clientID,
+		WaLabelID: evt.LabelID,
+		Name:      evt.Action.GetName(),
+		Color:     evt.Action.GetColor(),
+	}); err != nil {
+		logger.Error("Failed to sync label %s: %v", evt.LabelID, err)
+	}
+}
+
+func (w *EventHandler) handleLabelAssociationChat(evt *events.LabelAssociationChat) {
+	ctx := context.Background()
+
+	label, err := w.db.GetLabelByWaID(ctx, db.GetLabelByWaIDParams{
+		DeviceID:  w.clientID,
+		WaLabelID: evt.LabelID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The association can arrive before the label itself; keep a placeholder
+		// that the LabelEdit event will rename.
+		label, err = w.db.UpsertLabelByWaID(ctx, db.UpsertLabelByWaIDParams{
+			DeviceID:  w.clientID,
+			WaLabelID: evt.LabelID,
+			Name:      "Label " + evt.LabelID,
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to resolve label %s: %v", evt.LabelID, err)
+		return
+	}
+
+	if evt.Action.GetLabeled() {
+		err = w.db.AddThreadLabel(ctx, db.AddThreadLabelParams{
+			DeviceID: w.clientID,
+			ChatJid:  evt.JID.String(),
+			LabelID:  label.ID,
+		})
+	} else {
+		err = w.db.RemoveThreadLabel(ctx, db.RemoveThreadLabelParams{
+			DeviceID: w.clientID,
+			ChatJid:  evt.JID.String(),
+			LabelID:  label.ID,
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to sync label %s of chat %s: %v", evt.LabelID, evt.JID, err)
+	}
+}
+
+// muteEnd converts a WhatsApp mute end timestamp (milliseconds, -1 for "always") into
+// the muted_until column, which is NULL both when unmuted and when muted indefinitely.
+func muteEnd(muted bool, endMillis int64) pgtype.Timestamptz {
+	if !muted || endMillis <= 0 {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: time.UnixMilli(endMillis), Valid: true}
+}
@@ -0,0 +1,183 @@
+package wa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Types of contact attributes. Dates are written as YYYY-MM-DD.
+const (
+	AttributeTypeText    = "text"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeDate    = "date"
+	AttributeTypeSelect  = "select"
+)
+
+// AttributeVariablePrefix is put in front of attribute keys to form their template
+// placeholders, e.g. {{attr.plan}}.
+const AttributeVariablePrefix = "attr."
+
+// ErrInvalidAttribute is returned for contact attributes that do not match their definition.
+var ErrInvalidAttribute = errors.New("invalid contact attribute")
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// IsValidAttributeKey reports whether key can name a contact attribute: lowercase letters,
+// digits and underscores, starting with a letter.
+func IsValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// NormalizeTags trims and lowercases tags, dropping empty and duplicate ones.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// ValidateAttributes checks attribute values against their definitions. Every key must be
+// defined, values must have the type of their definition and required attributes must be
+// set. A nil value removes an attribute.
+func ValidateAttributes(definitions []db.ContactAttribute, values map[string]interface{}) error {
+	defined := make(map[string]db.ContactAttribute, len(definitions))
+	for _, def := range definitions {
+		defined[def.Key] = def
+	}
+
+	for key, value := range values {
+		def, ok := defined[key]
+		if !ok {
+			return fmt.Errorf("%w: %s is not defined", ErrInvalidAttribute, key)
+		}
+		if value == nil {
+			continue
+		}
+		if err := validateAttributeValue(def, value); err != nil {
+			return err
+		}
+	}
+	for _, def := range definitions {
+		if def.Required && values[def.Key] == nil {
+			return fmt.Errorf("%w: %s is required", ErrInvalidAttribute, def.Key)
+		}
+	}
+	return nil
+}
+
+func validateAttributeValue(def db.ContactAttribute, value interface{}) error {
+	valid := false
+	switch def.Type {
+	case AttributeTypeText:
+		_, valid = value.(string)
+	case AttributeTypeNumber:
+		_, valid = value.(float64)
+	case AttributeTypeBoolean:
+		_, valid = value.(bool)
+	case AttributeTypeDate:
+		if s, ok := value.(string); ok {
+			_, err := time.Parse(time.DateOnly, s)
+			valid = err == nil
+		}
+	case AttributeTypeSelect:
+		if s, ok := value.(string); ok {
+			for _, option := range def.Options {
+				if s == option {
+					valid = true
+					break
+				}
+			}
+		}
+	}
+	if !valid && def.Type == AttributeTypeSelect {
+		return fmt.Errorf("%w: %s must be one of %s", ErrInvalidAttribute, def.Key, strings.Join(def.Options, ", "))
+	}
+	if !valid {
+		return fmt.Errorf("%w: %s must be a %s value", ErrInvalidAttribute, def.Key, def.Type)
+	}
+	return nil
+}
+
+// ParseAttributeValue reads an attribute value given as text, such as a query parameter,
+// into the JSON type of its definition.
+func ParseAttributeValue(def db.ContactAttribute, text string) (interface{}, error) {
+	var value interface{} = text
+	switch def.Type {
+	case AttributeTypeNumber:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a number value", ErrInvalidAttribute, def.Key)
+		}
+		value = n
+	case AttributeTypeBoolean:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a boolean value", ErrInvalidAttribute, def.Key)
+		}
+		value = b
+	}
+	if err := validateAttributeValue(def, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ContactVariables returns the template placeholders of a contact: name, phone, email,
+// external_id, tags (comma separated) and each attribute as attr.<key>. Fields the contact
+// does not have are left out, so they do not replace values from elsewhere with nothing.
+func ContactVariables(ctx context.Context, queries db.Querier, deviceID, jid string) (map[string]interface{}, error) {
+	contact, err := queries.GetContactWithDetails(ctx, db.GetContactWithDetailsParams{
+		DeviceID: pgtype.Text{String: deviceID, Valid: true},
+		Jid:      jid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string]interface{})
+	setText := func(key string, values ...pgtype.Text) {
+		for _, value := range values {
+			if value.Valid && value.String != "" {
+				variables[key] = value.String
+				return
+			}
+		}
+	}
+	setText("name", contact.FullName, contact.PushName, contact.BusinessName)
+	setText("phone", contact.PhoneNumber)
+	setText("email", contact.Email)
+	setText("external_id", contact.ExternalID)
+	if len(contact.Tags) > 0 {
+		variables["tags"] = strings.Join(contact.Tags, ", ")
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(contact.Attributes, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to read attributes: %w", err)
+	}
+	for key, value := range attributes {
+		if value != nil {
+			variables[AttributeVariablePrefix+key] = value
+		}
+	}
+	return variables, nil
+}
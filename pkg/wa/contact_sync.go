@@ -0,0 +1,172 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/appstate"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const contactReconcileInterval = 6 * time.Hour
+
+// ReconcileContacts copies the contact store of a device into whatsapp_contacts and removes
+// contacts that are no longer in it. Contacts with details kept by the user are never
+// removed. It returns how many contacts were synced and removed.
+func (w *WhatsappClient) ReconcileContacts(clientID string) (int, int64, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return 0, 0, fmt.Errorf("client %s not found", clientID)
+	}
+
+	ctx := context.Background()
+	contacts, err := client.Store.Contacts.GetAllContacts(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	// An empty store means the contacts were not synced from the phone yet, not that they
+	// were all deleted
+	if len(contacts) == 0 {
+		return 0, 0, nil
+	}
+	if err := w.store.SyncContacts(ctx, clientID, contacts); err != nil {
+		return 0, 0, fmt.Errorf("failed to sync contacts: %w", err)
+	}
+
+	jids := make([]string, 0, len(contacts))
+	for jid := range contacts {
+		jids = append(jids, jid.String())
+	}
+	removed, err := w.db.DeleteStaleContacts(ctx, db.DeleteStaleContactsParams{
+		DeviceID: pgtype.Text{String: clientID, Valid: true},
+		Jids:     jids,
+	})
+	if err != nil {
+		return len(contacts), 0, fmt.Errorf("failed to remove deleted contacts: %w", err)
+	}
+	return len(contacts), removed, nil
+}
+
+// ContactSyncService reconciles the contacts of all connected devices in the background,
+// catching changes the contact events missed, such as contacts deleted on the phone.
+type ContactSyncService struct {
+	db       db.Querier
+	waClient *WhatsappClient
+}
+
+func NewContactSyncService(db db.Querier, waClient *WhatsappClient) *ContactSyncService {
+	return &ContactSyncService{
+		db:       db,
+		waClient: waClient,
+	}
+}
+
+func (s *ContactSyncService) Start(ctx context.Context) {
+	ticker := time.NewTicker(contactReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx)
+		}
+	}
+}
+
+func (s *ContactSyncService) run(ctx context.Context) {
+	clients, err := s.db.GetClients(ctx)
+	if err != nil {
+		logger.Error("Failed to get devices for contact sync: %v", err)
+		return
+	}
+
+	for _, client := range clients {
+		if !s.waClient.IsConnected(client.ID) {
+			continue
+		}
+		synced, removed, err := s.waClient.ReconcileContacts(client.ID)
+		if err != nil {
+			logger.Error("Failed to reconcile contacts of device %s: %v", client.ID, err)
+			continue
+		}
+		logger.Info("Reconciled %d contacts of device %s, removed %d", synced, client.ID, removed)
+	}
+}
+
+// handleContactSyncComplete reconciles the contacts once the app state patch that holds
+// them is synced. Full syncs put the contacts straight into the store without a contact
+// event for each.
+func (w *EventHandler) handleContactSyncComplete(evt *events.AppStateSyncComplete) {
+	if evt.Name != appstate.WAPatchCriticalUnblockLow {
+		return
+	}
+	go func() {
+		synced, removed, err := w.client.ReconcileContacts(w.clientID)
+		if err != nil {
+			logger.Error("Failed to reconcile contacts of device %s: %v", w.clientID, err)
+			return
+		}
+		logger.Info("Reconciled %d contacts of device %s after app state sync, removed %d", synced, w.clientID, removed)
+	}()
+}
+
+// handleContact stores a contact that was added or renamed in the address book of the phone.
+func (w *EventHandler) handleContact(evt *events.Contact) {
+	if evt.FromFullSync {
+		return
+	}
+	client := w.client.RetrieveDevice(w.clientID)
+	if client == nil {
+		return
+	}
+
+	ctx := context.Background()
+	info, err := client.Store.Contacts.GetContact(ctx, evt.JID)
+	if err != nil {
+		logger.Error("Failed to get contact %s: %v", evt.JID, err)
+		return
+	}
+	if err := w.store.SyncContacts(ctx, w.clientID, map[types.JID]types.ContactInfo{evt.JID: info}); err != nil {
+		logger.Error("Failed to store contact %s: %v", evt.JID, err)
+	}
+}
+
+func (w *EventHandler) handlePushName(evt *events.PushName) {
+	w.storePushName(context.Background(), evt.JID, evt.JIDAlt, evt.NewPushName)
+}
+
+// storePushName keeps the push name of a user up to date, so new customers show up with a
+// name before anyone syncs the contacts. Users known only by their LID are stored under
+// their phone number when it is known.
+func (w *EventHandler) storePushName(ctx context.Context, jid, alt types.JID, pushName string) {
+	if pushName == "" {
+		return
+	}
+	jid = jid.ToNonAD()
+	if jid.Server == types.HiddenUserServer {
+		if alt.Server == types.DefaultUserServer {
+			jid = alt.ToNonAD()
+		} else if pn := w.phoneJID(ctx, jid); pn.Valid {
+			jid, _ = types.ParseJID(pn.String)
+		}
+	}
+	if jid.Server != types.DefaultUserServer {
+		return
+	}
+
+	if err := w.db.UpsertContactPushName(ctx, db.UpsertContactPushNameParams{
+		DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+		Jid:         jid.String(),
+		PushName:    pgtype.Text{String: pushName, Valid: true},
+		PhoneNumber: pgtype.Text{String: jid.User, Valid: true},
+	}); err != nil {
+		logger.Error("Failed to store push name of %s: %v", jid, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/fransfilastap/kontak/pkg/storage"
 	kontaktypes "github.com/fransfilastap/kontak/pkg/types"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.mau.fi/whatsmeow"
@@ -17,11 +18,15 @@ type WhatsappClient struct {
 	db                db.Querier                        // Database queries for message logging
 	qr                chan<- kontaktypes.WaConnectEvent // Channel to send WhatsApp connection events such as QR codes
 	subscriptionStore *SubscriptionStore
+	optOutStore       *OptOutStore
+	eventBroker       *EventBroker
+	mediaStorage      storage.Storage
+	urlSigner         *storage.URLSigner
 	runningClients    map[string]*whatsmeow.Client
 }
 
 // NewWhatsappClient creates a new instance of WhatsappClient.
-func NewWhatsappClient(ctx context.Context, database string, dbQueries db.Querier, qr chan<- kontaktypes.WaConnectEvent, subscriptionStore *SubscriptionStore) *WhatsappClient {
+func NewWhatsappClient(ctx context.Context, database string, dbQueries db.Querier, qr chan<- kontaktypes.WaConnectEvent, subscriptionStore *SubscriptionStore, optOutStore *OptOutStore, eventBroker *EventBroker, mediaStorage storage.Storage, urlSigner *storage.URLSigner) *WhatsappClient {
 	dbLog := waLog.Stdout("Database", "DEBUG", true)
 	pgStore, err := NewPostgresStore(ctx, database, dbQueries, dbLog)
 	if err != nil {
@@ -33,6 +38,10 @@ func NewWhatsappClient(ctx context.Context, database string, dbQueries db.Querie
 		db:                dbQueries,
 		qr:                qr,
 		subscriptionStore: subscriptionStore,
+		optOutStore:       optOutStore,
+		eventBroker:       eventBroker,
+		mediaStorage:      mediaStorage,
+		urlSigner:         urlSigner,
 		runningClients:    make(map[string]*whatsmeow.Client),
 	}
 
@@ -60,7 +69,7 @@ func (w *WhatsappClient) Start(ctx context.Context, client db.Client) {
 
 	clientLog := waLog.Stdout("Client", "DEBUG", true)
 	waClient := whatsmeow.NewClient(deviceStore, clientLog)
-	eventHandler := BuildEventHandler(client.ID, w, w.store, w.db, w.subscriptionStore)
+	eventHandler := BuildEventHandler(client.ID, w, w.store, w.db, w.subscriptionStore, w.optOutStore, w.eventBroker)
 	waClient.AddEventHandler(eventHandler.handle)
 
 	logger.Info("Connecting to Whatsapp %v", deviceStore)
@@ -0,0 +1,61 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow/appstate"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// PinChat pins or unpins a chat on the WhatsApp account.
+func (w *WhatsappClient) PinChat(clientID string, chat types.JID, pinned bool) error {
+	return w.sendAppState(clientID, appstate.BuildPin(chat, pinned))
+}
+
+// ArchiveChat archives or unarchives a chat on the WhatsApp account. Archiving also unpins the chat.
+func (w *WhatsappClient) ArchiveChat(clientID string, chat types.JID, archived bool, lastMessageAt time.Time) error {
+	return w.sendAppState(clientID, appstate.BuildArchive(chat, archived, lastMessageAt, nil))
+}
+
+// MuteChat mutes or unmutes a chat on the WhatsApp account. A zero duration mutes it indefinitely.
+func (w *WhatsappClient) MuteChat(clientID string, chat types.JID, muted bool, duration time.Duration) error {
+	return w.sendAppState(clientID, appstate.BuildMute(chat, muted, duration))
+}
+
+// LabelChat adds or removes a WhatsApp Business label on a chat.
+func (w *WhatsappClient) LabelChat(clientID string, chat types.JID, labelID string, labeled bool) error {
+	return w.sendAppState(clientID, appstate.BuildLabelChat(chat, labelID, labeled))
+}
+
+// EditLabel creates, renames or deletes a WhatsApp Business label.
+func (w *WhatsappClient) EditLabel(clientID string, labelID, name string, color int32, deleted bool) error {
+	return w.sendAppState(clientID, appstate.BuildLabelEdit(labelID, name, color, deleted))
+}
+
+// MarkRead sends read receipts for messages in a chat. Receipts for several messages can only
+// be combined when they share a sender, which must be set in group chats.
+func (w *WhatsappClient) MarkRead(clientID string, chat, sender types.JID, messageIDs []types.MessageID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.MarkRead(context.Background(), messageIDs, time.Now(), chat, sender); err != nil {
+		return fmt.Errorf("failed to send read receipt: %w", err)
+	}
+	return nil
+}
+
+func (w *WhatsappClient) sendAppState(clientID string, patch appstate.PatchInfo) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.SendAppState(context.Background(), patch); err != nil {
+		return fmt.Errorf("failed to send app state patch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// ErrNoAnnouncementGroup is returned when a community has no announcement group, which is
+// the case for groups that are not communities.
+var ErrNoAnnouncementGroup = errors.New("community has no announcement group")
+
+// CreateCommunity creates a community. WhatsApp creates its announcement group along with it.
+func (w *WhatsappClient) CreateCommunity(clientID, name string) (*types.GroupInfo, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	info, err := client.CreateGroup(context.Background(), whatsmeow.ReqCreateGroup{
+		Name:        name,
+		GroupParent: types.GroupParent{IsParent: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create community: %w", err)
+	}
+	if err := upsertGroup(context.Background(), w.db, clientID, info); err != nil {
+		logger.Error("Failed to store community %s: %v", info.JID, err)
+	}
+	return info, nil
+}
+
+// CreateSubgroup creates a new group inside a community.
+func (w *WhatsappClient) CreateSubgroup(clientID string, community types.JID, name string, participants []types.JID) (*types.GroupInfo, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	info, err := client.CreateGroup(context.Background(), whatsmeow.ReqCreateGroup{
+		Name:              name,
+		Participants:      participants,
+		GroupLinkedParent: types.GroupLinkedParent{LinkedParentJID: community},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subgroup: %w", err)
+	}
+	if err := upsertGroup(context.Background(), w.db, clientID, info); err != nil {
+		logger.Error("Failed to store group %s: %v", info.JID, err)
+	}
+	return info, nil
+}
+
+// GetSubgroups lists the groups of a community, including its announcement group.
+func (w *WhatsappClient) GetSubgroups(clientID string, community types.JID) ([]*types.GroupLinkTarget, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	groups, err := client.GetSubGroups(context.Background(), community)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subgroups: %w", err)
+	}
+	return groups, nil
+}
+
+// LinkSubgroup adds an existing group to a community.
+func (w *WhatsappClient) LinkSubgroup(clientID string, community, group types.JID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.LinkGroup(context.Background(), community, group); err != nil {
+		return fmt.Errorf("failed to link group: %w", err)
+	}
+	w.setGroupParent(clientID, group, community)
+	return nil
+}
+
+// UnlinkSubgroup removes a group from a community. The group itself keeps existing.
+func (w *WhatsappClient) UnlinkSubgroup(clientID string, community, group types.JID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.UnlinkGroup(context.Background(), community, group); err != nil {
+		return fmt.Errorf("failed to unlink group: %w", err)
+	}
+	w.setGroupParent(clientID, group, types.EmptyJID)
+	return nil
+}
+
+// GetAnnouncementGroup returns the announcement group of a community, where only admins
+// post and every member of the community reads along.
+func (w *WhatsappClient) GetAnnouncementGroup(clientID string, community types.JID) (types.JID, error) {
+	groups, err := w.GetSubgroups(clientID, community)
+	if err != nil {
+		return types.EmptyJID, err
+	}
+	for _, group := range groups {
+		if group.IsDefaultSubGroup {
+			return group.JID, nil
+		}
+	}
+	return types.EmptyJID, ErrNoAnnouncementGroup
+}
+
+// setGroupParent stores the community a group belongs to; an empty community unlinks it.
+func (w *WhatsappClient) setGroupParent(clientID string, group, community types.JID) {
+	if err := w.db.SetWhatsAppGroupParent(context.Background(), db.SetWhatsAppGroupParentParams{
+		DeviceID:      pgtype.Text{String: clientID, Valid: true},
+		GroupID:       group.String(),
+		ParentGroupID: pgtype.Text{String: community.String(), Valid: !community.IsEmpty()},
+	}); err != nil {
+		logger.Error("Failed to store community of group %s: %v", group, err)
+	}
+}
+
+// storeGroupLinks applies groups being linked to or unlinked from a community. The event
+// arrives for the community with the subgroup as target, or the other way around.
+func (w *EventHandler) storeGroupLinks(evt *events.GroupInfo) {
+	for _, change := range []*types.GroupLinkChange{evt.Link, evt.Unlink} {
+		if change == nil {
+			continue
+		}
+
+		var group, community types.JID
+		switch change.Type {
+		case types.GroupLinkChangeTypeSub:
+			group, community = change.Group.JID, evt.JID
+		case types.GroupLinkChangeTypeParent:
+			group, community = evt.JID, change.Group.JID
+		default:
+			continue
+		}
+		if change == evt.Unlink {
+			community = types.EmptyJID
+		}
+		w.client.setGroupParent(w.clientID, group, community)
+	}
+}
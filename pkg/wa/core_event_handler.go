@@ -2,16 +2,13 @@ package wa
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -22,15 +19,21 @@ type EventHandler struct {
 	db                db.Querier
 	clientID          string
 	subscriptionStore *SubscriptionStore
+	optOutStore       *OptOutStore
+	eventBroker       *EventBroker
+	botLimiter        *chatRateLimiter
 }
 
-func BuildEventHandler(clientID string, client *WhatsappClient, store Store, dbQuerier db.Querier, subscriptionStore *SubscriptionStore) *EventHandler {
+func BuildEventHandler(clientID string, client *WhatsappClient, store Store, dbQuerier db.Querier, subscriptionStore *SubscriptionStore, optOutStore *OptOutStore, eventBroker *EventBroker) *EventHandler {
 	return &EventHandler{
 		clientID:          clientID,
 		client:            client,
 		store:             store,
 		db:                dbQuerier,
 		subscriptionStore: subscriptionStore,
+		optOutStore:       optOutStore,
+		eventBroker:       eventBroker,
+		botLimiter:        newChatRateLimiter(botChatRateLimit, botRateWindow),
 	}
 }
 
@@ -68,6 +71,16 @@ func (w *EventHandler) handle(rawEvt interface{}) {
 		logger.Info("StreamError: %v", evt)
 	case *events.AppState:
 		logger.Debug("AppState: %v", evt)
+	case *events.Pin:
+		w.handlePin(evt)
+	case *events.Archive:
+		w.handleArchive(evt)
+	case *events.Mute:
+		w.handleMute(evt)
+	case *events.LabelEdit:
+		w.handleLabelEdit(evt)
+	case *events.LabelAssociationChat:
+		w.handleLabelAssociationChat(evt)
 	case *events.KeepAliveTimeout:
 		logger.Debug("KeepAliveTimeout: %v", evt)
 	case *events.KeepAliveRestored:
@@ -94,14 +107,18 @@ func (w *EventHandler) handle(rawEvt interface{}) {
 		w.handlePicture(evt)
 	case *events.UserAbout:
 		w.handleUserAbout(evt)
+	case *events.Contact:
+		w.handleContact(evt)
+	case *events.PushName:
+		w.handlePushName(evt)
 	case *events.IdentityChange:
 		w.handleIdentityChange(evt)
 	case *events.HistorySync:
-		logger.Info("HistorySync: %v", evt)
+		w.handleHistorySync(evt)
 	case *events.UndecryptableMessage:
 		logger.Debug("UndecryptableMessage: %v", evt)
 	case *events.MediaRetry:
-		logger.Debug("MediaRetry: %v", evt)
+		w.handleMediaRetry(evt)
 	case *events.OfflineSyncPreview:
 		logger.Debug("OfflineSyncPreview: %v", evt)
 	case *events.OfflineSyncCompleted:
@@ -111,11 +128,11 @@ func (w *EventHandler) handle(rawEvt interface{}) {
 	case *events.PrivacySettings:
 		logger.Debug("PrivacySettings: %v", evt)
 	case *events.NewsletterJoin:
-		logger.Debug("NewsletterJoin: %v", evt)
+		w.handleNewsletterJoin(evt)
 	case *events.NewsletterLeave:
-		logger.Debug("NewsletterLeave: %v", evt)
+		w.handleNewsletterLeave(evt)
 	case *events.NewsletterLiveUpdate:
-		logger.Debug("NewsletterLiveUpdate: %v", evt)
+		w.handleNewsletterLiveUpdate(evt)
 	default:
 		logger.Debug("Unknown event type: %T", rawEvt)
 	}
@@ -126,6 +143,7 @@ func (w *EventHandler) handleAppStateSyncComplete(evt *events.AppStateSyncComple
 		w.sendPresence()
 	}
 	w.setConnectionStatus(true)
+	w.handleContactSyncComplete(evt)
 }
 
 func (w *EventHandler) handleConnection(evt interface{}) {
@@ -153,70 +171,16 @@ func (w *EventHandler) handleLoggedOut(evt *events.LoggedOut) {
 }
 
 func (w *EventHandler) handleIncomingMessage(evt *events.Message) {
-	// Extract content and determine message type
-	var text string
-	var messageType string
-	var mediaURL string
-	var mediaFilename string
-
-	var downloadMessage whatsmeow.DownloadableMessage
-	switch {
-	case evt.Message.GetConversation() != "":
-		text = evt.Message.GetConversation()
-		messageType = "text"
-	case evt.Message.GetExtendedTextMessage() != nil:
-		text = evt.Message.GetExtendedTextMessage().GetText()
-		messageType = "text"
-	case evt.Message.GetImageMessage() != nil:
-		img := evt.Message.GetImageMessage()
-		text = img.GetCaption()
-		messageType = "image"
-		mediaFilename = "image.jpg"
-		downloadMessage = img
-	case evt.Message.GetVideoMessage() != nil:
-		vid := evt.Message.GetVideoMessage()
-		text = vid.GetCaption()
-		messageType = "video"
-		mediaFilename = "video.mp4"
-		downloadMessage = vid
-	case evt.Message.GetDocumentMessage() != nil:
-		doc := evt.Message.GetDocumentMessage()
-		text = doc.GetTitle()
-		messageType = "document"
-		mediaFilename = doc.GetFileName()
-		if mediaFilename == "" {
-			mediaFilename = "document"
-		}
-		downloadMessage = doc
-	case evt.Message.GetAudioMessage() != nil:
-		messageType = "audio"
-		mediaFilename = "audio.ogg"
-		downloadMessage = evt.Message.GetAudioMessage()
-	default:
+	if w.handleMessageChange(evt) {
 		return
 	}
+	if !evt.Info.IsFromMe {
+		w.storePushName(context.Background(), evt.Info.Sender, evt.Info.SenderAlt, evt.Info.PushName)
+	}
 
-	if downloadMessage != nil {
-		data, err := w.client.runningClients[w.clientID].Download(context.Background(), downloadMessage)
-		if err == nil && len(data) > 0 {
-			// Save media locally
-			if err := os.MkdirAll("uploads", os.ModePerm); err == nil {
-				// generate safe unique filename
-				safeBase := filepath.Base(strings.ReplaceAll(mediaFilename, " ", "_"))
-				uniqueFilename := fmt.Sprintf("%s-%s", evt.Info.ID, safeBase)
-				filePath := filepath.Join("uploads", uniqueFilename)
-				if err := os.WriteFile(filePath, data, 0644); err == nil { // Fixed: using io/ioutil instead of os for older go versions, but standard since go1.16 is os.WriteFile. Ensure imports
-					// Store the local API route
-					mediaURL = fmt.Sprintf("/api/media/%s", uniqueFilename)
-				} else {
-					logger.Error("Failed to save downloaded media %s: %v", uniqueFilename, err)
-				}
-			} else {
-				logger.Error("Failed to create uploads directory: %v", err)
-			}
-		} else {
-			logger.Error("Failed to download media for message %s: %v", evt.Info.ID, err)
-		}
+	text, messageType, mediaFilename, downloadMessage := messageContent(evt.Message)
+	if messageType == "" {
+		return
 	}
 
 	chatJID := evt.Info.Chat.String()
@@ -247,35 +211,42 @@ func (w *EventHandler) handleIncomingMessage(evt *events.Message) {
 		logger.Error("Failed to upsert thread for chat %s: %v", chatJID, err)
 	}
 
+	if !evt.Info.IsFromMe {
+		w.reopenThread(chatJID)
+	}
+
 	// Messages sent from the primary device (phone) — log as outgoing
 	if evt.Info.IsFromMe {
-		_, err := w.db.LogOutgoingMessage(context.Background(), db.LogOutgoingMessageParams{
+		logged, err := w.db.LogOutgoingMessage(context.Background(), db.LogOutgoingMessageParams{
 			DeviceID:      pgtype.Text{String: w.clientID, Valid: true},
 			Recipient:     chatJID,
 			RecipientType: pgtype.Text{String: recipientType, Valid: true},
 			MessageType:   pgtype.Text{String: messageType, Valid: true},
 			Content:       text,
-			Column6:       mediaURL,
+			Column6:       "",
 			Column7:       mediaFilename,
 			WaMessageID:   pgtype.Text{String: evt.Info.ID, Valid: true},
 		})
 		if err != nil {
 			logger.Error("Failed to log synced outgoing message: %v", err)
-		} else {
-			logger.Debug("Logged synced outgoing %s message in chat %s", messageType, chatJID)
+			return
+		}
+		logger.Debug("Logged synced outgoing %s message in chat %s", messageType, chatJID)
+		if downloadMessage != nil {
+			w.queueMedia(context.Background(), logged.ID, messageType, downloadMessage)
 		}
 		return
 	}
 
 	senderJID := evt.Info.Sender.String()
 
-	_, err := w.db.LogIncomingMessage(context.Background(), db.LogIncomingMessageParams{
+	logged, err := w.db.LogIncomingMessage(context.Background(), db.LogIncomingMessageParams{
 		DeviceID:      pgtype.Text{String: w.clientID, Valid: true},
 		Recipient:     chatJID,
 		RecipientType: pgtype.Text{String: recipientType, Valid: true},
 		MessageType:   pgtype.Text{String: messageType, Valid: true},
 		Content:       text,
-		Column6:       mediaURL,
+		Column6:       "",
 		Column7:       mediaFilename,
 		SenderJid:     pgtype.Text{String: senderJID, Valid: true},
 		WaMessageID:   pgtype.Text{String: evt.Info.ID, Valid: true},
@@ -284,15 +255,118 @@ func (w *EventHandler) handleIncomingMessage(evt *events.Message) {
 		logger.Error("Failed to log incoming message: %v", err)
 	} else {
 		logger.Debug("Logged incoming %s message from %s in chat %s", messageType, senderJID, chatJID)
+		if downloadMessage != nil {
+			w.queueMedia(context.Background(), logged.ID, messageType, downloadMessage)
+		}
+	}
+
+	if messageType == "text" && !evt.Info.IsGroup && w.handleOptOutKeyword(evt, text) {
+		return
+	}
+
+	if w.handleFlow(evt, text) {
+		return
+	}
+
+	if w.handleBotWebhook(evt, messageType, text, logged.ID) {
+		return
+	}
+
+	w.handleAwayMessage(evt)
+	w.handleAutoReply(evt, messageType, text)
+}
+
+// messageContent extracts the text, message type and media of a message. The message
+// type is empty for messages the inbox does not store, such as protocol messages.
+func messageContent(msg *waE2E.Message) (text, messageType, mediaFilename string, media whatsmeow.DownloadableMessage) {
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation(), "text", "", nil
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetText(), "text", "", nil
+	case msg.GetImageMessage() != nil:
+		img := msg.GetImageMessage()
+		return img.GetCaption(), "image", "image.jpg", img
+	case msg.GetVideoMessage() != nil:
+		vid := msg.GetVideoMessage()
+		return vid.GetCaption(), "video", "video.mp4", vid
+	case msg.GetDocumentMessage() != nil:
+		doc := msg.GetDocumentMessage()
+		mediaFilename = doc.GetFileName()
+		if mediaFilename == "" {
+			mediaFilename = "document"
+		}
+		return doc.GetTitle(), "document", mediaFilename, doc
+	case msg.GetAudioMessage() != nil:
+		return "", "audio", "audio.ogg", msg.GetAudioMessage()
 	}
+	return "", "", "", nil
+}
+
+// handleOptOutKeyword adds the sender to the device owner's suppression list when the
+// message matches one of the configured opt-out keywords, optionally confirming it.
+// It reports whether the message was handled as an opt-out.
+func (w *EventHandler) handleOptOutKeyword(evt *events.Message, text string) bool {
+	ctx := context.Background()
+
+	owner, err := w.store.GetClient(ctx, w.clientID)
+	if err != nil || !owner.UserID.Valid {
+		return false
+	}
+
+	settings, err := w.optOutStore.GetSettings(ctx, owner.UserID.Int32)
+	if err != nil {
+		logger.Error("Failed to load opt-out settings for user %d: %v", owner.UserID.Int32, err)
+		return false
+	}
+
+	keyword, ok := MatchKeyword(settings.Keywords, text)
+	if !ok {
+		return false
+	}
+
+	// The suppression list is checked by phone number, so senders known only by their LID
+	// are stored under their phone number
+	sender := evt.Info.Sender.ToNonAD()
+	if sender.Server == types.HiddenUserServer {
+		if evt.Info.SenderAlt.Server == types.DefaultUserServer {
+			sender = evt.Info.SenderAlt.ToNonAD()
+		} else if pn := w.phoneJID(ctx, sender); pn.Valid {
+			sender, _ = types.ParseJID(pn.String)
+		}
+	}
+	if sender.Server != types.DefaultUserServer {
+		logger.Error("Failed to opt out %s via keyword %q: phone number is unknown", sender, keyword)
+		return false
+	}
+
+	senderJID := sender.String()
+	if _, err := w.optOutStore.Add(ctx, owner.UserID.Int32, senderJID, w.clientID, OptOutSourceKeyword, keyword, ""); err != nil {
+		logger.Error("Failed to opt out %s: %v", senderJID, err)
+		return false
+	}
+	logger.Info("Recipient %s opted out via keyword %q on device %s", senderJID, keyword, w.clientID)
+
+	if settings.SendConfirmation {
+		if err := w.sendTextReply(ctx, evt.Info.Chat.String(), settings.ConfirmationMessage); err != nil {
+			logger.Error("Failed to send opt-out confirmation to %s: %v", senderJID, err)
+		}
+	}
+	return true
 }
 
 func (w *EventHandler) handleReceipt(evt *events.Receipt) {
 	var status string
-	switch evt.Type {
-	case types.ReceiptTypeDelivered:
+	switch {
+	case evt.Chat == types.StatusBroadcastJID:
+		w.recordStatusViews(evt)
+		return
+	case evt.Type == types.ReceiptTypeReadSelf, evt.Type == types.ReceiptTypeRead && evt.IsFromMe:
+		w.handleSelfRead(evt)
+		return
+	case evt.Type == types.ReceiptTypeDelivered:
 		status = "delivered"
-	case types.ReceiptTypeRead:
+	case evt.Type == types.ReceiptTypeRead:
 		status = "read"
 	default:
 		return
@@ -309,6 +383,26 @@ func (w *EventHandler) handleReceipt(evt *events.Receipt) {
 	}
 }
 
+// handleSelfRead mirrors a chat read on the phone or another linked device into the inbox.
+func (w *EventHandler) handleSelfRead(evt *events.Receipt) {
+	ctx := context.Background()
+	chatJID := evt.Chat.String()
+
+	if err := w.db.MarkConversationRead(ctx, db.MarkConversationReadParams{
+		DeviceID:  pgtype.Text{String: w.clientID, Valid: true},
+		Recipient: chatJID,
+	}); err != nil {
+		logger.Error("Failed to mark chat %s as read: %v", chatJID, err)
+	}
+
+	if err := w.db.ResetThreadUnread(ctx, db.ResetThreadUnreadParams{
+		DeviceID: w.clientID,
+		ChatJid:  chatJID,
+	}); err != nil {
+		logger.Error("Failed to reset unread count of chat %s: %v", chatJID, err)
+	}
+}
+
 func (w *EventHandler) sendPresence() {
 	err := w.client.RetrieveDevice(w.clientID).SendPresence(context.Background(), types.PresenceAvailable)
 	if err != nil {
@@ -417,28 +511,11 @@ func (w *EventHandler) getEventType(rawEvt interface{}) string {
 	}
 }
 
-func (w *EventHandler) handleChatPresence(evt *events.ChatPresence) {
-	logger.Debug("ChatPresence: %v", evt)
-}
-
-func (w *EventHandler) handlePresence(evt *events.Presence) {
-	logger.Debug("Presence: %v", evt)
-}
-
-func (w *EventHandler) handleJoinedGroup(evt *events.JoinedGroup) {
-	logger.Info("JoinedGroup: %v", evt)
-}
-
 func (w *EventHandler) handleGroupInfo(evt *events.GroupInfo) {
 	logger.Debug("GroupInfo: %v", evt)
-}
-
-func (w *EventHandler) handlePicture(evt *events.Picture) {
-	logger.Info("Picture changed: %v", evt)
-}
-
-func (w *EventHandler) handleUserAbout(evt *events.UserAbout) {
-	logger.Info("UserAbout changed: %v", evt)
+	w.storeGroupChanges(evt)
+	w.storeGroupLinks(evt)
+	w.publishJoinRequests(evt)
 }
 
 func (w *EventHandler) handleIdentityChange(evt *events.IdentityChange) {
@@ -2,9 +2,15 @@ package wa
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
 )
 
 // GetJoinedGroups retrieves the list of groups the specified client has joined.
@@ -22,3 +28,275 @@ func (w *WhatsappClient) GetJoinedGroups(clientID string) ([]*types.GroupInfo, e
 
 	return nil, fmt.Errorf("client %s not found", clientID)
 }
+
+// GetGroupInfo fetches the current state of a group from WhatsApp and stores it.
+func (w *WhatsappClient) GetGroupInfo(clientID string, group types.JID) (*types.GroupInfo, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	info, err := client.GetGroupInfo(context.Background(), group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group info: %w", err)
+	}
+	if err := upsertGroup(context.Background(), w.db, clientID, info); err != nil {
+		logger.Error("Failed to store group %s: %v", group, err)
+	}
+	return info, nil
+}
+
+// CreateGroup creates a group with the given participants. Participants that could not be
+// added carry an error code in the returned group info.
+func (w *WhatsappClient) CreateGroup(clientID, name string, participants []types.JID) (*types.GroupInfo, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	info, err := client.CreateGroup(context.Background(), whatsmeow.ReqCreateGroup{
+		Name:         name,
+		Participants: participants,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	if err := upsertGroup(context.Background(), w.db, clientID, info); err != nil {
+		logger.Error("Failed to store group %s: %v", info.JID, err)
+	}
+	return info, nil
+}
+
+// UpdateGroupParticipants adds, removes, promotes or demotes participants of a group and
+// returns the outcome per participant.
+func (w *WhatsappClient) UpdateGroupParticipants(clientID string, group types.JID, participants []types.JID, action whatsmeow.ParticipantChange) ([]types.GroupParticipant, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	result, err := client.UpdateGroupParticipants(context.Background(), group, participants, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s group participants: %w", action, err)
+	}
+	w.refreshGroup(client, clientID, group)
+	return result, nil
+}
+
+// SetGroupName renames a group.
+func (w *WhatsappClient) SetGroupName(clientID string, group types.JID, name string) error {
+	return w.updateGroup(clientID, group, "name", func(client *whatsmeow.Client) error {
+		return client.SetGroupName(context.Background(), group, name)
+	})
+}
+
+// SetGroupDescription changes the description of a group; an empty description removes it.
+func (w *WhatsappClient) SetGroupDescription(clientID string, group types.JID, description string) error {
+	return w.updateGroup(clientID, group, "description", func(client *whatsmeow.Client) error {
+		return client.SetGroupTopic(context.Background(), group, "", "", description)
+	})
+}
+
+// SetGroupPhoto changes the picture of a group to a JPEG image, or removes it when avatar
+// is nil, and returns the new picture ID.
+func (w *WhatsappClient) SetGroupPhoto(clientID string, group types.JID, avatar []byte) (string, error) {
+	var pictureID string
+	err := w.updateGroup(clientID, group, "picture", func(client *whatsmeow.Client) error {
+		var err error
+		pictureID, err = client.SetGroupPhoto(context.Background(), group, avatar)
+		return err
+	})
+	return pictureID, err
+}
+
+// SetGroupAnnounce makes a group announce-only, where only admins can send messages.
+func (w *WhatsappClient) SetGroupAnnounce(clientID string, group types.JID, announce bool) error {
+	return w.updateGroup(clientID, group, "announce setting", func(client *whatsmeow.Client) error {
+		return client.SetGroupAnnounce(context.Background(), group, announce)
+	})
+}
+
+// SetGroupLocked allows only admins to edit the group info when locked.
+func (w *WhatsappClient) SetGroupLocked(clientID string, group types.JID, locked bool) error {
+	return w.updateGroup(clientID, group, "locked setting", func(client *whatsmeow.Client) error {
+		return client.SetGroupLocked(context.Background(), group, locked)
+	})
+}
+
+// LeaveGroup leaves a group and forgets it.
+func (w *WhatsappClient) LeaveGroup(clientID string, group types.JID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.LeaveGroup(context.Background(), group); err != nil {
+		return fmt.Errorf("failed to leave group: %w", err)
+	}
+	w.forgetGroup(context.Background(), clientID, group)
+	return nil
+}
+
+// forgetGroup deletes a group the device is no longer part of, with its participants.
+func (w *WhatsappClient) forgetGroup(ctx context.Context, clientID string, group types.JID) {
+	if err := w.db.DeleteWhatsAppGroup(ctx, db.DeleteWhatsAppGroupParams{
+		DeviceID: pgtype.Text{String: clientID, Valid: true},
+		GroupID:  group.String(),
+	}); err != nil {
+		logger.Error("Failed to delete group %s: %v", group, err)
+	}
+	if err := w.db.DeleteGroupParticipants(ctx, db.DeleteGroupParticipantsParams{
+		DeviceID: clientID,
+		GroupJid: group.String(),
+	}); err != nil {
+		logger.Error("Failed to delete participants of group %s: %v", group, err)
+	}
+}
+
+// GetGroupInviteLink returns the invite link of a group. With reset the current link is
+// revoked and a new one is returned.
+func (w *WhatsappClient) GetGroupInviteLink(clientID string, group types.JID, reset bool) (string, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", fmt.Errorf("client %s not found", clientID)
+	}
+
+	link, err := client.GetGroupInviteLink(context.Background(), group, reset)
+	if err != nil {
+		return "", fmt.Errorf("failed to get group invite link: %w", err)
+	}
+	return link, nil
+}
+
+// JoinGroupWithLink joins a group with an invite code or link. Groups that require admin
+// approval only receive a join request, in which case joined is false.
+func (w *WhatsappClient) JoinGroupWithLink(clientID, code string) (group types.JID, joined bool, err error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return types.EmptyJID, false, fmt.Errorf("client %s not found", clientID)
+	}
+
+	ctx := context.Background()
+	group, err = client.JoinGroupWithLink(ctx, code)
+	if err != nil {
+		return types.EmptyJID, false, fmt.Errorf("failed to join group: %w", err)
+	}
+
+	info, err := client.GetGroupInfo(ctx, group)
+	if errors.Is(err, whatsmeow.ErrNotInGroup) {
+		return group, false, nil
+	}
+	if err != nil {
+		logger.Warn("Failed to refresh group %s: %v", group, err)
+		return group, true, nil
+	}
+	if err := upsertGroup(ctx, w.db, clientID, info); err != nil {
+		logger.Error("Failed to store group %s: %v", group, err)
+	}
+	return group, true, nil
+}
+
+// GetGroupJoinRequests lists the pending requests to join a group.
+func (w *WhatsappClient) GetGroupJoinRequests(clientID string, group types.JID) ([]types.GroupParticipantRequest, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	requests, err := client.GetGroupRequestParticipants(context.Background(), group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group join requests: %w", err)
+	}
+	return requests, nil
+}
+
+// UpdateGroupJoinRequests approves or rejects requests to join a group and returns the
+// outcome per requester.
+func (w *WhatsappClient) UpdateGroupJoinRequests(clientID string, group types.JID, participants []types.JID, action whatsmeow.ParticipantRequestChange) ([]types.GroupParticipant, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	result, err := client.UpdateGroupRequestParticipants(context.Background(), group, participants, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s group join requests: %w", action, err)
+	}
+	if action == whatsmeow.ParticipantChangeApprove {
+		w.refreshGroup(client, clientID, group)
+	}
+	return result, nil
+}
+
+// updateGroup runs a change of group info and stores the group as it is afterwards.
+func (w *WhatsappClient) updateGroup(clientID string, group types.JID, what string, update func(client *whatsmeow.Client) error) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := update(client); err != nil {
+		return fmt.Errorf("failed to set group %s: %w", what, err)
+	}
+	w.refreshGroup(client, clientID, group)
+	return nil
+}
+
+// refreshGroup stores the current state of a group after it was changed. The change
+// already succeeded, so failures are only logged.
+func (w *WhatsappClient) refreshGroup(client *whatsmeow.Client, clientID string, group types.JID) {
+	ctx := context.Background()
+	info, err := client.GetGroupInfo(ctx, group)
+	if err != nil {
+		logger.Warn("Failed to refresh group %s: %v", group, err)
+		return
+	}
+	if err := upsertGroup(ctx, w.db, clientID, info); err != nil {
+		logger.Error("Failed to store group %s: %v", group, err)
+	}
+}
+
+// GroupJoinRequestEvent is the live event published when someone asks to join a group the
+// device administers, or withdraws that request.
+type GroupJoinRequestEvent struct {
+	GroupJID       string `json:"group_jid"`
+	ParticipantJID string `json:"participant_jid"`
+	Action         string `json:"action"`
+	RequestMethod  string `json:"request_method,omitempty"`
+}
+
+// Actions of a GroupJoinRequestEvent
+const (
+	GroupJoinRequestCreated = "created"
+	GroupJoinRequestRevoked = "revoked"
+)
+
+// publishJoinRequests publishes the join requests in a group change. whatsmeow does not
+// parse them, so they are read from the unknown changes of the event.
+func (w *EventHandler) publishJoinRequests(evt *events.GroupInfo) {
+	for _, change := range evt.UnknownChanges {
+		var action string
+		switch change.Tag {
+		case "created_membership_requests":
+			action = GroupJoinRequestCreated
+		case "revoked_membership_requests":
+			action = GroupJoinRequestRevoked
+		default:
+			continue
+		}
+
+		method := change.AttrGetter().OptionalString("request_method")
+		for _, participant := range change.GetChildrenByTag("participant") {
+			jid, ok := participant.Attrs["jid"].(types.JID)
+			if !ok {
+				continue
+			}
+			w.eventBroker.Publish(w.clientID, LiveEventGroupJoinRequest, GroupJoinRequestEvent{
+				GroupJID:       evt.JID.String(),
+				ParticipantJID: jid.String(),
+				Action:         action,
+				RequestMethod:  method,
+			})
+		}
+	}
+}
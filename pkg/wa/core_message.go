@@ -8,6 +8,7 @@ import (
 	"github.com/fransfilastap/kontak/pkg/logger"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -117,3 +118,18 @@ func (w *WhatsappClient) SendMediaMessage(clientID string, recipient string, med
 	}
 	return sendResp.ID, nil
 }
+
+// SendReaction reacts to an existing message in a chat. An empty reaction removes a previous one.
+func (w *WhatsappClient) SendReaction(clientID string, chat types.JID, sender types.JID, messageID string, reaction string) (string, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", fmt.Errorf("client %s not found", clientID)
+	}
+
+	msg := client.BuildReaction(chat, sender, messageID, reaction)
+	resp, err := client.SendMessage(context.Background(), chat, msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to send reaction: %v", err)
+	}
+	return resp.ID, nil
+}
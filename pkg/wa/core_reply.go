@@ -0,0 +1,241 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// sendTextReply sends an automated text message into a chat from the handler's device
+// and records it in message_logs and message_threads like any other outgoing message.
+func (w *EventHandler) sendTextReply(ctx context.Context, chatJID string, text string) error {
+	waMessageID, err := w.client.SendMessage(w.clientID, chatJID, text)
+	if err != nil {
+		return err
+	}
+
+	w.logAutomatedReply(ctx, chatJID, "text", text, waMessageID)
+	return nil
+}
+
+// sendTemplateReply renders a message template with variables and sends it into a chat.
+func (w *EventHandler) sendTemplateReply(ctx context.Context, chatJID string, templateID pgtype.UUID, variables map[string]interface{}) error {
+	template, err := w.db.GetMessageTemplateByID(ctx, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to load template: %w", err)
+	}
+	return w.sendTextReply(ctx, chatJID, RenderTemplate(template.Content, variables))
+}
+
+// sendMediaReply downloads the media at mediaURL and sends it into a chat as an automated reply.
+func (w *EventHandler) sendMediaReply(ctx context.Context, chatJID, mediaURL, fileName string) error {
+	data, err := w.loadReplyMedia(ctx, mediaURL)
+	if err != nil {
+		return err
+	}
+	if fileName == "" {
+		fileName = filepath.Base(mediaURL)
+	}
+
+	contentType := http.DetectContentType(data)
+	waMessageID, err := w.client.SendMediaMessage(w.clientID, chatJID, data, fileName, contentType)
+	if err != nil {
+		return err
+	}
+
+	w.logAutomatedReply(ctx, chatJID, mediaMessageType(contentType), fileName, waMessageID)
+	return nil
+}
+
+// logAutomatedReply stores a message the device sent on its own into message_logs and message_threads.
+// Unlike agent replies, automated ones leave the thread's away message state untouched.
+func (w *EventHandler) logAutomatedReply(ctx context.Context, chatJID, messageType, content, waMessageID string) {
+	logAutomatedMessage(ctx, w.db, w.clientID, chatJID, messageType, content, waMessageID)
+}
+
+// logAutomatedMessage is logAutomatedReply for callers outside the event handler, such as background services.
+func logAutomatedMessage(ctx context.Context, dbQueries db.Querier, deviceID, chatJID, messageType, content, waMessageID string) {
+	recipientType := "individual"
+	if strings.HasSuffix(chatJID, "@g.us") {
+		recipientType = "group"
+	}
+
+	_, err := dbQueries.LogOutgoingMessage(ctx, db.LogOutgoingMessageParams{
+		DeviceID:      pgtype.Text{String: deviceID, Valid: true},
+		Recipient:     chatJID,
+		RecipientType: pgtype.Text{String: recipientType, Valid: true},
+		MessageType:   pgtype.Text{String: messageType, Valid: true},
+		Content:       content,
+		WaMessageID:   pgtype.Text{String: waMessageID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to log automated reply to %s: %v", chatJID, err)
+	}
+
+	if err := dbQueries.UpsertThread(ctx, db.UpsertThreadParams{
+		DeviceID:    deviceID,
+		ChatJid:     chatJID,
+		ChatType:    recipientType,
+		Content:     content,
+		MessageType: messageType,
+		Direction:   "outgoing",
+		IsIncoming:  false,
+		IsAutomated: true,
+	}); err != nil {
+		logger.Error("Failed to upsert thread for automated reply to %s: %v", chatJID, err)
+	}
+}
+
+// replyVariables are the placeholders available to automated reply texts and templates:
+// the sender's push name and phone, overridden by what is stored about the contact.
+func (w *EventHandler) replyVariables(ctx context.Context, evt *events.Message) map[string]interface{} {
+	variables := map[string]interface{}{
+		"name":  evt.Info.PushName,
+		"phone": evt.Info.Sender.User,
+	}
+
+	sender := evt.Info.Sender.ToNonAD()
+	if pn := w.phoneJID(ctx, sender); pn.Valid {
+		sender, _ = types.ParseJID(pn.String)
+		variables["phone"] = sender.User
+	}
+	contact, err := ContactVariables(ctx, w.db, w.clientID, sender.String())
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to load contact variables of %s: %v", sender, err)
+		}
+		return variables
+	}
+	for key, value := range contact {
+		variables[key] = value
+	}
+	return variables
+}
+
+// maxReplyMediaSize is the largest media an automated reply downloads, the size limit of
+// WhatsApp documents. Images, videos and audio are limited further by WhatsApp itself.
+const maxReplyMediaSize = 100 << 20
+
+// ErrMediaURLNotAllowed is returned for media URLs that point into a private network.
+var ErrMediaURLNotAllowed = errors.New("media url must point to a public address")
+
+// replyMediaClient downloads reply media. It refuses to connect to private, loopback and
+// link-local addresses, also after redirects and DNS changes, so reply media cannot be
+// used to reach internal services.
+var replyMediaClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+					return fmt.Errorf("%w: %s", ErrMediaURLNotAllowed, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+}
+
+// isPublicIP reports whether ip is reachable on the internet rather than a private,
+// loopback, link-local or otherwise special address.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	// Carrier-grade NAT, used for internal addresses by some clouds
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
+
+// ValidateMediaURL checks a media URL before it is saved: either media of the device or
+// an http or https URL whose host resolves only to public addresses.
+func ValidateMediaURL(ctx context.Context, mediaURL string) error {
+	if strings.HasPrefix(mediaURL, MediaURLPrefix) {
+		return nil
+	}
+	u, err := url.Parse(mediaURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("media url must be an http or https URL")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("media url host cannot be resolved: %w", err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s", ErrMediaURLNotAllowed, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// loadReplyMedia reads media either from the media storage of the device (for /api/media/ URLs)
+// or by downloading it over HTTP.
+func (w *EventHandler) loadReplyMedia(ctx context.Context, mediaURL string) ([]byte, error) {
+	if strings.HasPrefix(mediaURL, MediaURLPrefix) {
+		return w.client.loadMedia(ctx, w.clientID, mediaURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media url: %w", err)
+	}
+	resp, err := replyMediaClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download media: unexpected status %d", resp.StatusCode)
+	}
+
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReplyMediaSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if len(data) > maxReplyMediaSize {
+		return nil, fmt.Errorf("media is larger than %d MB", maxReplyMediaSize>>20)
+	}
+	return data, nil
+}
+
+// mediaMessageType maps a content type onto the message types used in message_logs.
+func mediaMessageType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "video/"):
+		return "video"
+	case strings.HasPrefix(contentType, "audio/"):
+		return "audio"
+	default:
+		return "document"
+	}
+}
@@ -57,6 +57,15 @@ func (cm *DeviceStore) GetDeviceByIDAndUserID(ctx context.Context, id string, us
 	return dvc, nil
 }
 
+// GetDeviceForMember retrieves a device by ID if the user owns it or has been added to its team.
+func (cm *DeviceStore) GetDeviceForMember(ctx context.Context, id string, userID int32) (db.Client, error) {
+	dvc, err := cm.store.GetClientForMember(ctx, id, userID)
+	if err != nil {
+		return db.Client{}, fmt.Errorf("failed to get device: %w", ErrDeviceNotFound)
+	}
+	return dvc, nil
+}
+
 // GetDevices retrieves all clients from the database.
 func (cm *DeviceStore) GetDevices(ctx context.Context) ([]db.Client, error) {
 	dvc, err := cm.store.GetClients(ctx)
@@ -144,6 +153,6 @@ func (cm *DeviceStore) SyncContacts(ctx context.Context, clientID string, contac
 	return cm.store.SyncContacts(ctx, clientID, contacts)
 }
 
-func (cm *DeviceStore) GetContacts(ctx context.Context, clientID string) ([]db.WhatsappContact, error) {
+func (cm *DeviceStore) GetContacts(ctx context.Context, clientID string) ([]db.GetDeviceContactsRow, error) {
 	return cm.store.GetContacts(ctx, clientID)
 }
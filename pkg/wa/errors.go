@@ -7,4 +7,5 @@ var (
 	ErrClientNotConnected = errors.New("client not connected")
 	ErrClientNotReady     = errors.New("client not ready")
 	ErrMessageSendFailed  = errors.New("message send failed")
+	ErrRecipientOptedOut  = errors.New("recipient has opted out")
 )
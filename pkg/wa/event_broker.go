@@ -0,0 +1,76 @@
+package wa
+
+import (
+	"sync"
+	"time"
+)
+
+// Live event types published to the event stream
+const (
+	LiveEventChatPresence     = "chat_presence"
+	LiveEventPresence         = "presence"
+	LiveEventGroupJoinRequest = "group_join_request"
+)
+
+// liveEventBuffer is how many events a slow subscriber may lag behind before events are dropped.
+const liveEventBuffer = 64
+
+// LiveEvent is a device event pushed to connected clients as it happens.
+type LiveEvent struct {
+	Type     string      `json:"type"`
+	DeviceID string      `json:"device_id"`
+	Time     time.Time   `json:"time"`
+	Data     interface{} `json:"data"`
+}
+
+// EventBroker fans out live events of a device to its subscribers, such as inbox UIs
+// connected to the event stream. Delivery is best effort: events are dropped for
+// subscribers that do not keep up rather than blocking the WhatsApp event handler.
+type EventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan LiveEvent]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subscribers: make(map[string]map[chan LiveEvent]struct{})}
+}
+
+// Subscribe returns a channel with the live events of a device and a function that
+// unsubscribes and closes the channel.
+func (b *EventBroker) Subscribe(deviceID string) (<-chan LiveEvent, func()) {
+	ch := make(chan LiveEvent, liveEventBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[deviceID] == nil {
+		b.subscribers[deviceID] = make(map[chan LiveEvent]struct{})
+	}
+	b.subscribers[deviceID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[deviceID], ch)
+			if len(b.subscribers[deviceID]) == 0 {
+				delete(b.subscribers, deviceID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to every subscriber of a device.
+func (b *EventBroker) Publish(deviceID, eventType string, data interface{}) {
+	evt := LiveEvent{Type: eventType, DeviceID: deviceID, Time: time.Now(), Data: data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[deviceID] {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package wa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Flow step types.
+const (
+	FlowStepMessage   = "message"
+	FlowStepMenu      = "menu"
+	FlowStepInput     = "input"
+	FlowStepCondition = "condition"
+	FlowStepHandoff   = "handoff"
+	FlowStepEnd       = "end"
+)
+
+// Flow session statuses.
+const (
+	FlowSessionActive    = "active"
+	FlowSessionCompleted = "completed"
+	FlowSessionHandoff   = "handoff"
+	FlowSessionExpired   = "expired"
+	FlowSessionClosed    = "closed"
+)
+
+// Flow trigger types.
+const (
+	FlowTriggerKeyword = "keyword"
+	FlowTriggerAny     = "any"
+)
+
+// maxFlowTransitions stops a misconfigured flow from looping forever through steps that don't wait for input.
+const maxFlowTransitions = 50
+
+// FlowDefinition is the JSON document describing a flow as a graph of named steps.
+type FlowDefinition struct {
+	Start          string              `json:"start"`
+	Steps          map[string]FlowStep `json:"steps"`
+	TimeoutMessage string              `json:"timeout_message,omitempty"`
+}
+
+// FlowStep is a single node of a flow. Which fields apply depends on Type:
+// message, handoff and end send Message; menu sends Message plus its Options and waits for a choice;
+// input sends Message and waits for an answer stored under Field; condition branches on collected answers.
+type FlowStep struct {
+	Type           string          `json:"type"`
+	Message        string          `json:"message,omitempty"`
+	Options        []FlowOption    `json:"options,omitempty"`
+	Field          string          `json:"field,omitempty"`
+	Validation     *FlowValidation `json:"validation,omitempty"`
+	InvalidMessage string          `json:"invalid_message,omitempty"`
+	Conditions     []FlowCondition `json:"conditions,omitempty"`
+	Next           string          `json:"next,omitempty"`
+}
+
+// FlowOption is a menu choice selected by replying with its Key.
+type FlowOption struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Next  string `json:"next"`
+}
+
+// FlowValidation restricts what an input step accepts.
+// Type is one of text, number, email, phone or regex.
+type FlowValidation struct {
+	Type      string `json:"type"`
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength int    `json:"min_length,omitempty"`
+	MaxLength int    `json:"max_length,omitempty"`
+}
+
+// FlowCondition routes to Next when the answer stored under Field satisfies Operator and Value.
+// Operator is one of equals, not_equals, contains or matches.
+type FlowCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Next     string `json:"next"`
+}
+
+// FlowResult is the outcome of advancing a session: the messages to send, the step the
+// session now waits on and its new status.
+type FlowResult struct {
+	Replies []string
+	Step    string
+	Status  string
+}
+
+// ParseFlowDefinition decodes a flow definition and validates it.
+func ParseFlowDefinition(raw []byte) (FlowDefinition, error) {
+	var def FlowDefinition
+	if err := json.Unmarshal(raw, &def); err != nil {
+		return FlowDefinition{}, fmt.Errorf("invalid flow definition: %w", err)
+	}
+	if err := def.Validate(); err != nil {
+		return FlowDefinition{}, err
+	}
+	return def, nil
+}
+
+// Validate checks that every step is well formed and only points at steps that exist.
+func (d FlowDefinition) Validate() error {
+	if _, ok := d.Steps[d.Start]; !ok {
+		return fmt.Errorf("flow start step %q does not exist", d.Start)
+	}
+
+	target := func(id, next string) error {
+		if next == "" {
+			return nil
+		}
+		if _, ok := d.Steps[next]; !ok {
+			return fmt.Errorf("step %q points to unknown step %q", id, next)
+		}
+		return nil
+	}
+
+	for id, step := range d.Steps {
+		switch step.Type {
+		case FlowStepMessage, FlowStepHandoff, FlowStepEnd:
+		case FlowStepMenu:
+			if len(step.Options) == 0 {
+				return fmt.Errorf("menu step %q has no options", id)
+			}
+			for _, option := range step.Options {
+				if option.Key == "" {
+					return fmt.Errorf("menu step %q has an option without a key", id)
+				}
+				if err := target(id, option.Next); err != nil {
+					return err
+				}
+			}
+		case FlowStepInput:
+			if step.Field == "" {
+				return fmt.Errorf("input step %q has no field", id)
+			}
+			if err := step.Validation.validate(); err != nil {
+				return fmt.Errorf("input step %q: %w", id, err)
+			}
+		case FlowStepCondition:
+			for _, condition := range step.Conditions {
+				switch condition.Operator {
+				case "equals", "not_equals", "contains":
+				case "matches":
+					if _, err := compilePattern(condition.Value); err != nil {
+						return fmt.Errorf("condition step %q has an invalid pattern: %w", id, err)
+					}
+				default:
+					return fmt.Errorf("condition step %q has unknown operator %q", id, condition.Operator)
+				}
+				if err := target(id, condition.Next); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("step %q has unknown type %q", id, step.Type)
+		}
+		if err := target(id, step.Next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Begin enters the start step of the flow.
+func (d FlowDefinition) Begin(answers map[string]string, variables map[string]interface{}) FlowResult {
+	return d.enter(d.Start, answers, variables, nil)
+}
+
+// Advance feeds the contact's reply into the step the session is waiting on.
+// Answers collected by input and menu steps are written into answers.
+func (d FlowDefinition) Advance(stepID, input string, answers map[string]string, variables map[string]interface{}) FlowResult {
+	step, ok := d.Steps[stepID]
+	if !ok {
+		return FlowResult{Status: FlowSessionCompleted}
+	}
+	input = strings.TrimSpace(input)
+
+	switch step.Type {
+	case FlowStepMenu:
+		for _, option := range step.Options {
+			if strings.EqualFold(input, option.Key) || (option.Label != "" && strings.EqualFold(input, option.Label)) {
+				if step.Field != "" {
+					answers[step.Field] = option.Key
+				}
+				next := option.Next
+				if next == "" {
+					next = step.Next
+				}
+				return d.enter(next, answers, variables, nil)
+			}
+		}
+	case FlowStepInput:
+		if step.Validation.accepts(input) {
+			answers[step.Field] = input
+			return d.enter(step.Next, answers, variables, nil)
+		}
+	default:
+		return d.enter(step.Next, answers, variables, nil)
+	}
+
+	invalid := step.InvalidMessage
+	if invalid == "" {
+		invalid = "Sorry, I didn't understand that."
+	}
+	return FlowResult{
+		Replies: []string{renderFlowText(invalid, answers, variables), d.prompt(step, answers, variables)},
+		Step:    stepID,
+		Status:  FlowSessionActive,
+	}
+}
+
+// enter runs steps starting at stepID until one waits for input or the flow ends.
+func (d FlowDefinition) enter(stepID string, answers map[string]string, variables map[string]interface{}, replies []string) FlowResult {
+	for range maxFlowTransitions {
+		step, ok := d.Steps[stepID]
+		if stepID == "" || !ok {
+			return FlowResult{Replies: replies, Status: FlowSessionCompleted}
+		}
+
+		switch step.Type {
+		case FlowStepMenu, FlowStepInput:
+			replies = append(replies, d.prompt(step, answers, variables))
+			return FlowResult{Replies: replies, Step: stepID, Status: FlowSessionActive}
+		case FlowStepCondition:
+			stepID = step.branch(answers)
+			continue
+		}
+
+		if step.Message != "" {
+			replies = append(replies, renderFlowText(step.Message, answers, variables))
+		}
+		switch step.Type {
+		case FlowStepHandoff:
+			return FlowResult{Replies: replies, Step: stepID, Status: FlowSessionHandoff}
+		case FlowStepEnd:
+			return FlowResult{Replies: replies, Step: stepID, Status: FlowSessionCompleted}
+		}
+		stepID = step.Next
+	}
+	return FlowResult{Replies: replies, Status: FlowSessionCompleted}
+}
+
+// prompt renders the message of a waiting step, listing the options of a menu.
+func (d FlowDefinition) prompt(step FlowStep, answers map[string]string, variables map[string]interface{}) string {
+	text := renderFlowText(step.Message, answers, variables)
+	if step.Type != FlowStepMenu {
+		return text
+	}
+	var lines []string
+	if text != "" {
+		lines = append(lines, text)
+	}
+	for _, option := range step.Options {
+		if option.Label != "" {
+			lines = append(lines, fmt.Sprintf("%s. %s", option.Key, option.Label))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s FlowStep) branch(answers map[string]string) string {
+	for _, condition := range s.Conditions {
+		value := answers[condition.Field]
+		var matched bool
+		switch condition.Operator {
+		case "equals":
+			matched = strings.EqualFold(value, condition.Value)
+		case "not_equals":
+			matched = !strings.EqualFold(value, condition.Value)
+		case "contains":
+			matched = strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+		case "matches":
+			re, err := compilePattern(condition.Value)
+			matched = err == nil && re.MatchString(value)
+		}
+		if matched {
+			return condition.Next
+		}
+	}
+	return s.Next
+}
+
+func (v *FlowValidation) validate() error {
+	if v == nil {
+		return nil
+	}
+	switch v.Type {
+	case "", "text", "number", "email", "phone":
+	case "regex":
+		if _, err := compilePattern(v.Pattern); err != nil {
+			return fmt.Errorf("invalid validation pattern: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown validation type %q", v.Type)
+	}
+	return nil
+}
+
+func (v *FlowValidation) accepts(input string) bool {
+	if input == "" {
+		return false
+	}
+	if v == nil {
+		return true
+	}
+	length := len([]rune(input))
+	if v.MinLength > 0 && length < v.MinLength {
+		return false
+	}
+	if v.MaxLength > 0 && length > v.MaxLength {
+		return false
+	}
+
+	switch v.Type {
+	case "number":
+		_, err := strconv.ParseFloat(input, 64)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(input)
+		return err == nil && addr.Address == input
+	case "phone":
+		digits := 0
+		for _, r := range input {
+			switch {
+			case unicode.IsDigit(r):
+				digits++
+			case strings.ContainsRune("+-() ", r):
+			default:
+				return false
+			}
+		}
+		return digits >= 6 && digits <= 15
+	case "regex":
+		re, err := compilePattern(v.Pattern)
+		return err == nil && re.MatchString(input)
+	}
+	return true
+}
+
+// renderFlowText fills {{placeholders}} from the collected answers and the contact variables.
+func renderFlowText(text string, answers map[string]string, variables map[string]interface{}) string {
+	merged := make(map[string]interface{}, len(answers)+len(variables))
+	for key, value := range variables {
+		merged[key] = value
+	}
+	for key, value := range answers {
+		merged[key] = value
+	}
+	return RenderTemplate(text, merged)
+}
@@ -0,0 +1,69 @@
+package wa
+
+import (
+	"context"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+)
+
+// FlowHandoffTTL is how long a chat handed off to a human stays out of reach of the flows.
+const FlowHandoffTTL = 24 * time.Hour
+
+// FlowService expires idle flow sessions and releases stale handoffs in the background.
+type FlowService struct {
+	db       db.Querier
+	waClient *WhatsappClient
+}
+
+func NewFlowService(db db.Querier, waClient *WhatsappClient) *FlowService {
+	return &FlowService{
+		db:       db,
+		waClient: waClient,
+	}
+}
+
+func (s *FlowService) Start(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireIdleSessions(ctx)
+			s.releaseStaleHandoffs(ctx)
+		}
+	}
+}
+
+func (s *FlowService) expireIdleSessions(ctx context.Context) {
+	sessions, err := s.db.GetExpiredFlowSessions(ctx)
+	if err != nil {
+		logger.Error("Failed to get expired flow sessions: %v", err)
+		return
+	}
+
+	for _, session := range sessions {
+		flow, def, err := loadFlowVersion(ctx, s.db, session.FlowID, session.FlowVersion)
+		if err != nil {
+			logger.Error("Failed to load flow for session %s: %v", UUID2String(session.ID), err)
+			continue
+		}
+
+		expireFlowSession(ctx, s.db, s.waClient, flow, def, session)
+	}
+}
+
+func (s *FlowService) releaseStaleHandoffs(ctx context.Context) {
+	released, err := s.db.CloseStaleHandoffSessions(ctx, int32(FlowHandoffTTL.Seconds()))
+	if err != nil {
+		logger.Error("Failed to release stale flow handoffs: %v", err)
+		return
+	}
+	if released > 0 {
+		logger.Info("Released %d flow handoffs older than %v", released, FlowHandoffTTL)
+	}
+}
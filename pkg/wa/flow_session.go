@@ -0,0 +1,245 @@
+package wa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// FlowWebhookPayload is posted to a flow's webhook_url once a session ends.
+type FlowWebhookPayload struct {
+	Event       string            `json:"event"`
+	FlowID      string            `json:"flow_id"`
+	FlowName    string            `json:"flow_name"`
+	FlowVersion int32             `json:"flow_version"`
+	DeviceID    string            `json:"device_id"`
+	ChatJID     string            `json:"chat_jid"`
+	Status      string            `json:"status"`
+	Answers     map[string]string `json:"answers"`
+	StartedAt   time.Time         `json:"started_at"`
+	EndedAt     time.Time         `json:"ended_at"`
+}
+
+// handleFlow starts or advances the chat's flow session. It reports whether the
+// message was consumed by a flow, in which case no other automation answers it.
+// While a session is handed off to a human the flow engine stays silent.
+func (w *EventHandler) handleFlow(evt *events.Message, text string) bool {
+	if evt.Info.IsGroup {
+		return false
+	}
+	ctx := context.Background()
+	chatJID := evt.Info.Chat.String()
+
+	session, err := w.db.GetOpenFlowSession(ctx, db.GetOpenFlowSessionParams{
+		DeviceID: w.clientID,
+		ChatJid:  chatJID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Failed to load flow session for chat %s: %v", chatJID, err)
+		return false
+	}
+
+	if err == nil {
+		if session.Status == FlowSessionHandoff {
+			return true
+		}
+		if consumed := w.continueFlow(ctx, session, evt, text); consumed {
+			return true
+		}
+	}
+	return w.startFlow(ctx, evt, text)
+}
+
+// continueFlow feeds the message into an active session. A session that sat idle past the
+// flow's timeout is expired instead, leaving the message free to start a new flow.
+func (w *EventHandler) continueFlow(ctx context.Context, session db.FlowSession, evt *events.Message, text string) bool {
+	flow, def, err := loadFlowVersion(ctx, w.db, session.FlowID, session.FlowVersion)
+	if err != nil {
+		logger.Error("Failed to load flow for session %s: %v", UUID2String(session.ID), err)
+		return false
+	}
+
+	if flow.TimeoutSeconds > 0 && time.Since(session.LastActivityAt.Time) > time.Duration(flow.TimeoutSeconds)*time.Second {
+		expireFlowSession(ctx, w.db, w.client, flow, def, session)
+		return false
+	}
+
+	answers := decodeFlowAnswers(session.Answers)
+
+	result := def.Advance(session.CurrentStep, text, answers, w.replyVariables(ctx, evt))
+	w.applyFlowResult(ctx, flow, session, answers, result)
+	return true
+}
+
+// startFlow begins the first active flow of the device whose trigger matches the message.
+func (w *EventHandler) startFlow(ctx context.Context, evt *events.Message, text string) bool {
+	flows, err := w.db.GetActiveFlows(ctx, w.clientID)
+	if err != nil {
+		logger.Error("Failed to load flows for device %s: %v", w.clientID, err)
+		return false
+	}
+
+	for _, flow := range flows {
+		if flow.TriggerType == FlowTriggerKeyword {
+			if _, ok := MatchKeyword(flow.TriggerKeywords, text); !ok {
+				continue
+			}
+		}
+
+		_, def, err := loadFlowVersion(ctx, w.db, flow.ID, flow.CurrentVersion)
+		if err != nil {
+			logger.Error("Failed to load flow %s: %v", flow.Name, err)
+			return false
+		}
+
+		session, err := w.db.CreateFlowSession(ctx, db.CreateFlowSessionParams{
+			FlowID:      flow.ID,
+			FlowVersion: flow.CurrentVersion,
+			DeviceID:    w.clientID,
+			ChatJid:     evt.Info.Chat.String(),
+			CurrentStep: def.Start,
+		})
+		if err != nil {
+			logger.Error("Failed to start flow %s in chat %s: %v", flow.Name, evt.Info.Chat, err)
+			return false
+		}
+		logger.Info("Started flow %s v%d in chat %s", flow.Name, flow.CurrentVersion, evt.Info.Chat)
+
+		answers := map[string]string{}
+		result := def.Begin(answers, w.replyVariables(ctx, evt))
+		w.applyFlowResult(ctx, flow, session, answers, result)
+		return true
+	}
+	return false
+}
+
+// applyFlowResult persists the session's new state and sends the step replies.
+func (w *EventHandler) applyFlowResult(ctx context.Context, flow db.Flow, session db.FlowSession, answers map[string]string, result FlowResult) {
+	chatJID := session.ChatJid
+	for _, reply := range result.Replies {
+		if reply == "" {
+			continue
+		}
+		if err := w.sendTextReply(ctx, chatJID, reply); err != nil {
+			logger.Error("Failed to send flow reply to %s: %v", chatJID, err)
+			break
+		}
+	}
+
+	if result.Status == FlowSessionActive {
+		if err := saveFlowSession(ctx, w.db, session, result.Step, answers, result.Status); err != nil {
+			logger.Error("Failed to save flow session %s: %v", UUID2String(session.ID), err)
+		}
+		return
+	}
+	finishFlowSession(ctx, w.db, flow, session, result.Step, answers, result.Status)
+}
+
+// loadFlowVersion returns a flow together with the parsed definition of one of its versions.
+func loadFlowVersion(ctx context.Context, dbQueries db.Querier, flowID pgtype.UUID, version int32) (db.Flow, FlowDefinition, error) {
+	flow, err := dbQueries.GetFlowByID(ctx, flowID)
+	if err != nil {
+		return db.Flow{}, FlowDefinition{}, err
+	}
+	flowVersion, err := dbQueries.GetFlowVersion(ctx, db.GetFlowVersionParams{FlowID: flowID, Version: version})
+	if err != nil {
+		return db.Flow{}, FlowDefinition{}, fmt.Errorf("flow version %d: %w", version, err)
+	}
+	def, err := ParseFlowDefinition(flowVersion.Definition)
+	if err != nil {
+		return db.Flow{}, FlowDefinition{}, err
+	}
+	return flow, def, nil
+}
+
+func saveFlowSession(ctx context.Context, dbQueries db.Querier, session db.FlowSession, step string, answers map[string]string, status string) error {
+	encoded, err := json.Marshal(answers)
+	if err != nil {
+		return err
+	}
+	return dbQueries.UpdateFlowSession(ctx, db.UpdateFlowSessionParams{
+		ID:          session.ID,
+		CurrentStep: step,
+		Answers:     encoded,
+		Status:      status,
+	})
+}
+
+// finishFlowSession stores the final state of a session and posts the collected answers
+// to the flow's webhook, if one is configured.
+func finishFlowSession(ctx context.Context, dbQueries db.Querier, flow db.Flow, session db.FlowSession, step string, answers map[string]string, status string) {
+	if err := saveFlowSession(ctx, dbQueries, session, step, answers, status); err != nil {
+		logger.Error("Failed to save flow session %s: %v", UUID2String(session.ID), err)
+		return
+	}
+	logger.Info("Flow %s ended in chat %s with status %s", flow.Name, session.ChatJid, status)
+	postFlowResult(flow, session, answers, status)
+}
+
+// postFlowResult posts the collected answers of a finished session to the flow's webhook,
+// if one is configured.
+func postFlowResult(flow db.Flow, session db.FlowSession, answers map[string]string, status string) {
+	if !flow.WebhookUrl.Valid || flow.WebhookUrl.String == "" {
+		return
+	}
+	payload := FlowWebhookPayload{
+		Event:       "flow." + status,
+		FlowID:      UUID2String(flow.ID),
+		FlowName:    flow.Name,
+		FlowVersion: session.FlowVersion,
+		DeviceID:    session.DeviceID,
+		ChatJID:     session.ChatJid,
+		Status:      status,
+		Answers:     answers,
+		StartedAt:   session.CreatedAt.Time,
+		EndedAt:     time.Now(),
+	}
+	go func() {
+		if _, err := postJSON(context.Background(), flow.WebhookUrl.String, flow.WebhookSecret.String, payload); err != nil {
+			logger.Error("Failed to post flow %s result to webhook: %v", flow.Name, err)
+		}
+	}()
+}
+
+// expireFlowSession finishes a session that sat idle past the flow's timeout and sends the
+// flow's timeout message, if it has one. The session is claimed first, so when the idle
+// sweep and an incoming message find it at the same time only one of them expires it.
+func expireFlowSession(ctx context.Context, dbQueries db.Querier, waClient *WhatsappClient, flow db.Flow, def FlowDefinition, session db.FlowSession) {
+	claimed, err := dbQueries.ExpireFlowSession(ctx, session.ID)
+	if err != nil {
+		logger.Error("Failed to expire flow session %s: %v", UUID2String(session.ID), err)
+		return
+	}
+	if claimed == 0 {
+		return
+	}
+	logger.Info("Flow %s ended in chat %s with status %s", flow.Name, session.ChatJid, FlowSessionExpired)
+
+	if def.TimeoutMessage != "" && waClient.IsConnected(session.DeviceID) {
+		waMessageID, err := waClient.SendMessage(session.DeviceID, session.ChatJid, def.TimeoutMessage)
+		if err != nil {
+			logger.Error("Failed to send flow timeout message to %s: %v", session.ChatJid, err)
+		} else {
+			logAutomatedMessage(ctx, dbQueries, session.DeviceID, session.ChatJid, "text", def.TimeoutMessage, waMessageID)
+		}
+	}
+	postFlowResult(flow, session, decodeFlowAnswers(session.Answers), FlowSessionExpired)
+}
+
+func decodeFlowAnswers(raw []byte) map[string]string {
+	answers := map[string]string{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &answers); err != nil {
+			logger.Warn("Discarding unreadable flow answers: %v", err)
+		}
+	}
+	return answers
+}
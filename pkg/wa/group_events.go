@@ -0,0 +1,220 @@
+package wa
+
+import (
+	"context"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Roles of group participants
+const (
+	GroupRoleMember     = "member"
+	GroupRoleAdmin      = "admin"
+	GroupRoleSuperAdmin = "superadmin"
+)
+
+// Actions recorded in the group history
+const (
+	GroupEventJoin        = "join"
+	GroupEventLeave       = "leave"
+	GroupEventPromote     = "promote"
+	GroupEventDemote      = "demote"
+	GroupEventName        = "name"
+	GroupEventDescription = "description"
+)
+
+// IsValidGroupEvent reports whether action is one of the GroupEvent* constants.
+func IsValidGroupEvent(action string) bool {
+	switch action {
+	case GroupEventJoin, GroupEventLeave, GroupEventPromote, GroupEventDemote, GroupEventName, GroupEventDescription:
+		return true
+	}
+	return false
+}
+
+func participantRole(p types.GroupParticipant) string {
+	switch {
+	case p.IsSuperAdmin:
+		return GroupRoleSuperAdmin
+	case p.IsAdmin:
+		return GroupRoleAdmin
+	default:
+		return GroupRoleMember
+	}
+}
+
+func (w *EventHandler) handleJoinedGroup(evt *events.JoinedGroup) {
+	logger.Info("Joined group %s (%s)", evt.JID, evt.Name)
+
+	ctx := context.Background()
+	if err := upsertGroup(ctx, w.db, w.clientID, &evt.GroupInfo); err != nil {
+		logger.Error("Failed to store group %s: %v", evt.JID, err)
+	}
+
+	client := w.client.RetrieveDevice(w.clientID)
+	if client == nil || client.Store.ID == nil {
+		return
+	}
+	actor := evt.Sender
+	if evt.SenderPN != nil {
+		actor = evt.SenderPN
+	}
+	w.recordGroupEvent(ctx, evt.JID, GroupEventJoin, client.Store.ID.ToNonAD(), actor, evt.Reason, time.Now())
+}
+
+// storeGroupChanges applies a group change to the stored group and its participants and
+// records it in the group history.
+func (w *EventHandler) storeGroupChanges(evt *events.GroupInfo) {
+	ctx := context.Background()
+	deviceID := pgtype.Text{String: w.clientID, Valid: true}
+	group := evt.JID.String()
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	actor := evt.Sender
+	if evt.SenderPN != nil {
+		actor = evt.SenderPN
+	}
+
+	for _, jid := range evt.Join {
+		if err := w.db.UpsertGroupParticipant(ctx, db.UpsertGroupParticipantParams{
+			DeviceID:       w.clientID,
+			GroupJid:       group,
+			ParticipantJid: jid.String(),
+			PhoneJid:       w.phoneJID(ctx, jid),
+			Role:           GroupRoleMember,
+			JoinedAt:       pgtype.Timestamptz{Time: at, Valid: true},
+		}); err != nil {
+			logger.Error("Failed to store participant %s of group %s: %v", jid, group, err)
+		}
+		w.recordGroupEvent(ctx, evt.JID, GroupEventJoin, jid, actor, evt.JoinReason, at)
+	}
+	left := false
+	for _, jid := range evt.Leave {
+		if w.isOwnJID(jid) {
+			left = true
+		}
+		if err := w.db.DeleteGroupParticipant(ctx, db.DeleteGroupParticipantParams{
+			DeviceID:       w.clientID,
+			GroupJid:       group,
+			ParticipantJid: jid.String(),
+		}); err != nil {
+			logger.Error("Failed to remove participant %s of group %s: %v", jid, group, err)
+		}
+		w.recordGroupEvent(ctx, evt.JID, GroupEventLeave, jid, actor, "", at)
+	}
+	// The device left or was removed from the group, so it gets no further updates of it
+	if left {
+		w.client.forgetGroup(ctx, w.clientID, evt.JID)
+		return
+	}
+	if len(evt.Join) > 0 || len(evt.Leave) > 0 {
+		if err := w.db.RefreshWhatsAppGroupParticipantCount(ctx, db.RefreshWhatsAppGroupParticipantCountParams{
+			DeviceID: deviceID,
+			GroupID:  group,
+		}); err != nil {
+			logger.Error("Failed to update participant count of group %s: %v", group, err)
+		}
+	}
+
+	for _, jid := range evt.Promote {
+		w.setParticipantRole(ctx, group, jid, GroupRoleAdmin)
+		w.recordGroupEvent(ctx, evt.JID, GroupEventPromote, jid, actor, "", at)
+	}
+	for _, jid := range evt.Demote {
+		w.setParticipantRole(ctx, group, jid, GroupRoleMember)
+		w.recordGroupEvent(ctx, evt.JID, GroupEventDemote, jid, actor, "", at)
+	}
+
+	if evt.Name != nil {
+		if err := w.db.SetWhatsAppGroupName(ctx, db.SetWhatsAppGroupNameParams{
+			DeviceID:  deviceID,
+			GroupID:   group,
+			GroupName: evt.Name.Name,
+		}); err != nil {
+			logger.Error("Failed to store name of group %s: %v", group, err)
+		}
+		w.recordGroupEvent(ctx, evt.JID, GroupEventName, types.EmptyJID, actor, evt.Name.Name, at)
+	}
+	if evt.Topic != nil {
+		topic := evt.Topic.Topic
+		if evt.Topic.TopicDeleted {
+			topic = ""
+		}
+		if err := w.db.SetWhatsAppGroupDescription(ctx, db.SetWhatsAppGroupDescriptionParams{
+			DeviceID:         deviceID,
+			GroupID:          group,
+			GroupDescription: pgtype.Text{String: topic, Valid: topic != ""},
+		}); err != nil {
+			logger.Error("Failed to store description of group %s: %v", group, err)
+		}
+		w.recordGroupEvent(ctx, evt.JID, GroupEventDescription, types.EmptyJID, actor, topic, at)
+	}
+}
+
+// isOwnJID reports whether jid is the phone number or LID of the device itself.
+func (w *EventHandler) isOwnJID(jid types.JID) bool {
+	client := w.client.RetrieveDevice(w.clientID)
+	if client == nil || client.Store.ID == nil {
+		return false
+	}
+	jid = jid.ToNonAD()
+	return jid == client.Store.ID.ToNonAD() || (!client.Store.LID.IsEmpty() && jid == client.Store.LID.ToNonAD())
+}
+
+func (w *EventHandler) setParticipantRole(ctx context.Context, group string, jid types.JID, role string) {
+	if err := w.db.SetGroupParticipantRole(ctx, db.SetGroupParticipantRoleParams{
+		Role:           role,
+		DeviceID:       w.clientID,
+		GroupJid:       group,
+		ParticipantJid: jid.String(),
+	}); err != nil {
+		logger.Error("Failed to store role of participant %s in group %s: %v", jid, group, err)
+	}
+}
+
+// recordGroupEvent adds a change to the group history. participant is empty for changes of
+// the group info, and actor is nil when WhatsApp does not say who made the change.
+func (w *EventHandler) recordGroupEvent(ctx context.Context, group types.JID, action string, participant types.JID, actor *types.JID, value string, at time.Time) {
+	params := db.CreateGroupEventParams{
+		DeviceID:   w.clientID,
+		GroupJid:   group.String(),
+		Action:     action,
+		Value:      pgtype.Text{String: value, Valid: value != ""},
+		OccurredAt: pgtype.Timestamptz{Time: at, Valid: true},
+	}
+	if !participant.IsEmpty() {
+		params.ParticipantJid = pgtype.Text{String: participant.String(), Valid: true}
+		params.PhoneJid = w.phoneJID(ctx, participant)
+	}
+	if actor != nil {
+		params.ActorJid = pgtype.Text{String: actor.ToNonAD().String(), Valid: true}
+	}
+
+	if err := w.db.CreateGroupEvent(ctx, params); err != nil {
+		logger.Error("Failed to record %s in group %s: %v", action, group, err)
+	}
+}
+
+// phoneJID looks up the phone number JID of a LID, so participants of LID groups can be
+// matched with contacts.
+func (w *EventHandler) phoneJID(ctx context.Context, jid types.JID) pgtype.Text {
+	if jid.Server != types.HiddenUserServer {
+		return pgtype.Text{}
+	}
+	client := w.client.RetrieveDevice(w.clientID)
+	if client == nil {
+		return pgtype.Text{}
+	}
+	pn, err := client.Store.LIDs.GetPNForLID(ctx, jid)
+	if err != nil || pn.IsEmpty() {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: pn.String(), Valid: true}
+}
@@ -0,0 +1,274 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Limits applied to history sync imports when a device has no settings of its own
+const (
+	DefaultHistorySyncMaxDays            = 90
+	DefaultHistorySyncMaxMessagesPerChat = 500
+)
+
+// LiveEventHistorySync is published after each imported history sync chunk.
+const LiveEventHistorySync = "history_sync"
+
+// DefaultHistorySyncSettings returns the history sync settings used for a device that has not configured any.
+func DefaultHistorySyncSettings(deviceID string) db.HistorySyncSetting {
+	return db.HistorySyncSetting{
+		DeviceID:           deviceID,
+		IsEnabled:          true,
+		MaxDays:            DefaultHistorySyncMaxDays,
+		MaxMessagesPerChat: DefaultHistorySyncMaxMessagesPerChat,
+	}
+}
+
+// historyMessage is a parsed history sync message that the inbox can store.
+type historyMessage struct {
+	evt           *events.Message
+	text          string
+	messageType   string
+	mediaFilename string
+	media         whatsmeow.DownloadableMessage
+	status        string
+}
+
+// handleHistorySync imports the conversations of a history sync chunk into the inbox.
+// WhatsApp sends the chat history in several chunks after pairing, and again in smaller
+// batches later on. Messages already in the inbox are skipped, so chunks can be replayed
+// safely. Media is not downloaded; its keys are kept so it can be fetched later.
+func (w *EventHandler) handleHistorySync(evt *events.HistorySync) {
+	ctx := context.Background()
+	data := evt.Data
+
+	settings, err := w.db.GetHistorySyncSettings(ctx, w.clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = DefaultHistorySyncSettings(w.clientID)
+	} else if err != nil {
+		logger.Error("Failed to load history sync settings for device %s: %v", w.clientID, err)
+		return
+	}
+	if !settings.IsEnabled {
+		logger.Debug("History sync is disabled for device %s, ignoring %s chunk", w.clientID, data.GetSyncType())
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -int(settings.MaxDays))
+	var conversations, messages int32
+	for _, conv := range data.GetConversations() {
+		n := w.importConversation(ctx, conv, cutoff, settings.MaxMessagesPerChat)
+		if n > 0 {
+			conversations++
+			messages += n
+		}
+	}
+
+	progress, err := w.db.UpdateHistorySyncProgress(ctx, db.UpdateHistorySyncProgressParams{
+		DeviceID:      w.clientID,
+		SyncType:      data.GetSyncType().String(),
+		ChunkOrder:    int32(data.GetChunkOrder()),
+		Progress:      int32(data.GetProgress()),
+		Conversations: conversations,
+		Messages:      messages,
+	})
+	if err != nil {
+		logger.Error("Failed to update history sync progress for device %s: %v", w.clientID, err)
+		return
+	}
+
+	logger.Info("Imported %d messages in %d chats from %s history sync chunk %d of device %s (%d%%)",
+		messages, conversations, data.GetSyncType(), data.GetChunkOrder(), w.clientID, data.GetProgress())
+	w.eventBroker.Publish(w.clientID, LiveEventHistorySync, progress)
+}
+
+// importConversation stores the newest messages of a conversation, within the age and
+// per-chat limits, and returns how many were new.
+func (w *EventHandler) importConversation(ctx context.Context, conv *waHistorySync.Conversation, cutoff time.Time, maxPerChat int32) int32 {
+	chatJID, err := types.ParseJID(conv.GetID())
+	if err != nil {
+		logger.Debug("Skipping history of unparseable chat %s: %v", conv.GetID(), err)
+		return 0
+	}
+	switch chatJID.Server {
+	case types.DefaultUserServer, types.HiddenUserServer, types.GroupServer:
+	default:
+		// Status updates, broadcasts and newsletters are not inbox chats
+		return 0
+	}
+	chat := chatJID.String()
+
+	stored, err := w.db.CountChatMessages(ctx, db.CountChatMessagesParams{
+		DeviceID:  pgtype.Text{String: w.clientID, Valid: true},
+		Recipient: chat,
+	})
+	if err != nil {
+		logger.Error("Failed to count stored messages of chat %s: %v", chat, err)
+		return 0
+	}
+	limit := int(maxPerChat) - int(stored)
+	if limit <= 0 {
+		return 0
+	}
+
+	msgs := w.parseHistoryMessages(chatJID, conv.GetMessages(), cutoff)
+
+	// The newest incoming messages are still unread on the phone
+	unread := conv.GetUnreadCount()
+	for _, msg := range msgs {
+		if unread == 0 {
+			break
+		}
+		if !msg.evt.Info.IsFromMe {
+			msg.status = "delivered"
+			unread--
+		}
+	}
+	if len(msgs) > limit {
+		msgs = msgs[:limit]
+	}
+
+	recipientType := "individual"
+	if chatJID.Server == types.GroupServer {
+		recipientType = "group"
+	}
+
+	var imported int32
+	var newest *historyMessage
+	for _, msg := range msgs {
+		if !w.importHistoryMessage(ctx, chat, recipientType, msg) {
+			continue
+		}
+		imported++
+		if newest == nil {
+			newest = msg
+		}
+	}
+	if newest == nil {
+		return 0
+	}
+
+	threadContent := newest.text
+	if threadContent == "" && newest.messageType != "text" {
+		threadContent = newest.messageType
+	}
+	direction := "incoming"
+	if newest.evt.Info.IsFromMe {
+		direction = "outgoing"
+	}
+	if err := w.db.UpsertHistoryThread(ctx, db.UpsertHistoryThreadParams{
+		DeviceID:             w.clientID,
+		ChatJid:              chat,
+		ChatType:             recipientType,
+		LastMessageAt:        pgtype.Timestamptz{Time: newest.evt.Info.Timestamp, Valid: true},
+		LastMessageContent:   threadContent,
+		LastMessageType:      newest.messageType,
+		LastMessageDirection: direction,
+		UnreadCount:          int32(conv.GetUnreadCount()),
+		IsPinned:             conv.GetPinned() > 0,
+		IsArchived:           conv.GetArchived(),
+	}); err != nil {
+		logger.Error("Failed to upsert thread for chat %s: %v", chat, err)
+	}
+
+	return imported
+}
+
+// parseHistoryMessages returns the storable messages sent after cutoff, newest first.
+func (w *EventHandler) parseHistoryMessages(chatJID types.JID, raw []*waHistorySync.HistorySyncMsg, cutoff time.Time) []*historyMessage {
+	client := w.client.runningClients[w.clientID]
+
+	msgs := make([]*historyMessage, 0, len(raw))
+	for _, item := range raw {
+		webMsg := item.GetMessage()
+		evt, err := client.ParseWebMessage(chatJID, webMsg)
+		if err != nil {
+			logger.Debug("Skipping unparseable history message in chat %s: %v", chatJID, err)
+			continue
+		}
+		if evt.Info.Timestamp.Before(cutoff) {
+			continue
+		}
+
+		text, messageType, mediaFilename, media := messageContent(evt.Message)
+		if messageType == "" {
+			continue
+		}
+		msgs = append(msgs, &historyMessage{
+			evt:           evt,
+			text:          text,
+			messageType:   messageType,
+			mediaFilename: mediaFilename,
+			media:         media,
+			status:        historyMessageStatus(evt.Info.IsFromMe, webMsg.GetStatus()),
+		})
+	}
+
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].evt.Info.Timestamp.After(msgs[j].evt.Info.Timestamp)
+	})
+	return msgs
+}
+
+// importHistoryMessage stores a history message and its media keys. It reports whether
+// the message was new.
+func (w *EventHandler) importHistoryMessage(ctx context.Context, chat, recipientType string, msg *historyMessage) bool {
+	info := msg.evt.Info
+	direction, senderJID := "incoming", pgtype.Text{String: info.Sender.String(), Valid: true}
+	if info.IsFromMe {
+		direction, senderJID = "outgoing", pgtype.Text{}
+	}
+
+	id, err := w.db.ImportHistoryMessage(ctx, db.ImportHistoryMessageParams{
+		DeviceID:      pgtype.Text{String: w.clientID, Valid: true},
+		Recipient:     chat,
+		RecipientType: pgtype.Text{String: recipientType, Valid: true},
+		MessageType:   pgtype.Text{String: msg.messageType, Valid: true},
+		Content:       msg.text,
+		MediaFilename: pgtype.Text{String: msg.mediaFilename, Valid: msg.mediaFilename != ""},
+		Status:        pgtype.Text{String: msg.status, Valid: true},
+		Direction:     direction,
+		SenderJid:     senderJID,
+		WaMessageID:   pgtype.Text{String: info.ID, Valid: true},
+		SentAt:        pgtype.Timestamptz{Time: info.Timestamp, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to import history message %s in chat %s: %v", info.ID, chat, err)
+		return false
+	}
+
+	if msg.media != nil {
+		w.storeMediaKeys(ctx, id, msg.media, MediaStatusOnDemand)
+	}
+	return true
+}
+
+// historyMessageStatus maps the WhatsApp status of a history message to a message_logs
+// status. Incoming messages count as read unless the chat marks them unread.
+func historyMessageStatus(isFromMe bool, status waWeb.WebMessageInfo_Status) string {
+	if !isFromMe {
+		return "read"
+	}
+	switch status {
+	case waWeb.WebMessageInfo_DELIVERY_ACK:
+		return "delivered"
+	case waWeb.WebMessageInfo_READ, waWeb.WebMessageInfo_PLAYED:
+		return "read"
+	}
+	return "sent"
+}
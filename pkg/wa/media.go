@@ -0,0 +1,338 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/fransfilastap/kontak/pkg/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waMmsRetry"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// MediaURLPrefix is the path under which stored media is served.
+const MediaURLPrefix = "/api/media/"
+
+// Download states of message media
+const (
+	MediaStatusPending        = "pending"
+	MediaStatusOnDemand       = "on_demand"
+	MediaStatusRetryRequested = "retry_requested"
+	MediaStatusDownloaded     = "downloaded"
+	MediaStatusFailed         = "failed"
+	MediaStatusDeleted        = "deleted"
+)
+
+const (
+	mediaDownloadMaxAttempts = 5
+	mediaDownloadBackoff     = time.Minute
+	mediaDownloadTimeout     = 2 * time.Minute
+	mediaDownloadBatchSize   = 10
+)
+
+// ErrMediaRetryRequested is returned when media has expired on the WhatsApp servers and
+// the phone was asked to upload it again. The download resumes once the phone answers.
+var ErrMediaRetryRequested = errors.New("media expired, re-requested from the phone")
+
+// ErrMediaDeleted is returned for media removed by the retention rules or a storage quota.
+var ErrMediaDeleted = errors.New("media was removed by the retention rules or a storage quota")
+
+// DefaultAutoDownloadTypes are the media types downloaded on arrival when a device has no settings of its own.
+var DefaultAutoDownloadTypes = []string{"image", "video", "document", "audio"}
+
+// MediaService downloads queued message media in the background and retries failed downloads.
+type MediaService struct {
+	db       db.Querier
+	waClient *WhatsappClient
+}
+
+func NewMediaService(db db.Querier, waClient *WhatsappClient) *MediaService {
+	return &MediaService{
+		db:       db,
+		waClient: waClient,
+	}
+}
+
+func (s *MediaService) Start(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processDueDownloads(ctx)
+		}
+	}
+}
+
+func (s *MediaService) processDueDownloads(ctx context.Context) {
+	ids, err := s.db.ClaimMediaDownloads(ctx, mediaDownloadBatchSize)
+	if err != nil {
+		logger.Error("Failed to claim media downloads: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		_, err := s.waClient.FetchMessageMedia(ctx, id)
+		if err != nil && !errors.Is(err, ErrMediaRetryRequested) {
+			logger.Warn("Failed to download media of message %s: %v", UUID2String(id), err)
+		}
+	}
+}
+
+// FetchMessageMedia downloads the media of a stored message, unless that already happened,
+// and returns its URL. A failed download is rescheduled in the background queue; media that
+// expired on the server is re-requested from the phone and ErrMediaRetryRequested is returned.
+func (w *WhatsappClient) FetchMessageMedia(ctx context.Context, messageID pgtype.UUID) (string, error) {
+	media, err := w.db.GetMessageMedia(ctx, messageID)
+	if err != nil {
+		return "", err
+	}
+	if media.Status == MediaStatusDownloaded && media.MediaUrl.Valid {
+		return media.MediaUrl.String, nil
+	}
+	if media.Status == MediaStatusDeleted {
+		return "", ErrMediaDeleted
+	}
+
+	client, ok := w.runningClients[media.DeviceID.String]
+	if !ok {
+		err := fmt.Errorf("client %s not found", media.DeviceID.String)
+		w.scheduleMediaRetry(ctx, media, err)
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mediaDownloadTimeout)
+	defer cancel()
+
+	data, err := client.DownloadMediaWithPath(ctx, media.DirectPath, media.FileEncSha256, media.FileSha256, media.MediaKey,
+		whatsmeow.MediaType(media.MediaType), "", false)
+	if errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith404) || errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith410) {
+		return "", w.requestMediaRetry(ctx, client, media, err)
+	}
+	if err != nil {
+		w.scheduleMediaRetry(ctx, media, err)
+		return "", fmt.Errorf("failed to download media: %w", err)
+	}
+
+	mediaURL, err := w.storeMedia(ctx, media.DeviceID.String, media.MediaFilename.String, media.Mimetype.String, data)
+	if err != nil {
+		w.scheduleMediaRetry(ctx, media, err)
+		return "", err
+	}
+
+	if err := w.db.CompleteMediaDownload(ctx, db.CompleteMediaDownloadParams{
+		MessageID: messageID,
+		MediaUrl:  pgtype.Text{String: mediaURL, Valid: true},
+	}); err != nil {
+		return "", fmt.Errorf("failed to store media url: %w", err)
+	}
+	return mediaURL, nil
+}
+
+// scheduleMediaRetry queues another download attempt with exponential backoff, or gives
+// up once the attempts are used up.
+func (w *WhatsappClient) scheduleMediaRetry(ctx context.Context, media db.GetMessageMediaRow, cause error) {
+	var retryAt pgtype.Timestamptz
+	if media.Attempts < mediaDownloadMaxAttempts {
+		retryAt = pgtype.Timestamptz{Time: time.Now().Add(mediaDownloadBackoff << media.Attempts), Valid: true}
+	}
+
+	if err := w.db.FailMediaDownload(ctx, db.FailMediaDownloadParams{
+		RetryAt:   retryAt,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+		MessageID: media.MessageID,
+	}); err != nil {
+		logger.Error("Failed to reschedule media download of message %s: %v", UUID2String(media.MessageID), err)
+	}
+}
+
+// requestMediaRetry asks the phone to upload expired media again. The phone answers with a
+// MediaRetry event carrying the new path.
+func (w *WhatsappClient) requestMediaRetry(ctx context.Context, client *whatsmeow.Client, media db.GetMessageMediaRow, cause error) error {
+	if media.Attempts >= mediaDownloadMaxAttempts {
+		w.scheduleMediaRetry(ctx, media, cause)
+		return fmt.Errorf("failed to download media: %w", cause)
+	}
+
+	info := types.MessageInfo{
+		ID: media.WaMessageID.String,
+		MessageSource: types.MessageSource{
+			IsFromMe: media.Direction == "outgoing",
+			IsGroup:  media.RecipientType.String == "group",
+		},
+	}
+	var err error
+	if info.Chat, err = types.ParseJID(media.Recipient); err != nil {
+		w.scheduleMediaRetry(ctx, media, err)
+		return fmt.Errorf("invalid chat jid: %w", err)
+	}
+	if media.SenderJid.Valid {
+		info.Sender, _ = types.ParseJID(media.SenderJid.String)
+	}
+
+	if err := client.SendMediaRetryReceipt(ctx, &info, media.MediaKey); err != nil {
+		w.scheduleMediaRetry(ctx, media, err)
+		return fmt.Errorf("failed to request media retry: %w", err)
+	}
+
+	if err := w.db.MarkMediaRetryRequested(ctx, db.MarkMediaRetryRequestedParams{
+		MessageID: media.MessageID,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+	}); err != nil {
+		logger.Error("Failed to mark media retry of message %s: %v", UUID2String(media.MessageID), err)
+	}
+	logger.Debug("Requested media retry for message %s", info.ID)
+	return ErrMediaRetryRequested
+}
+
+// storeMedia saves media of a device in the media storage and returns its URL.
+func (w *WhatsappClient) storeMedia(ctx context.Context, deviceID, filename, mimetype string, data []byte) (string, error) {
+	key, sum := storage.Key(data, filename)
+	if err := w.mediaStorage.Put(ctx, key, mimetype, data); err != nil {
+		return "", err
+	}
+
+	if err := w.db.CreateMediaObject(ctx, db.CreateMediaObjectParams{
+		DeviceID:   deviceID,
+		StorageKey: key,
+		Sha256:     sum,
+		SizeBytes:  int64(len(data)),
+		Mimetype:   pgtype.Text{String: mimetype, Valid: mimetype != ""},
+	}); err != nil {
+		return "", fmt.Errorf("failed to record media object: %w", err)
+	}
+	return MediaURLPrefix + key, nil
+}
+
+// loadMedia reads media of a device from the media storage by its URL.
+func (w *WhatsappClient) loadMedia(ctx context.Context, deviceID, mediaURL string) ([]byte, error) {
+	key, _ := strings.CutPrefix(mediaURL, MediaURLPrefix)
+	devices, err := w.db.GetMediaObjectDevices(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up media: %w", err)
+	}
+	if !slices.Contains(devices, deviceID) {
+		return nil, storage.ErrNotFound
+	}
+
+	r, err := w.mediaStorage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// SignMediaURL returns a media URL that opens without a login until expiry.
+func (w *WhatsappClient) SignMediaURL(mediaURL string, expiry time.Duration) string {
+	if !strings.HasPrefix(mediaURL, MediaURLPrefix) {
+		return mediaURL
+	}
+	return w.urlSigner.Sign(mediaURL, expiry)
+}
+
+// queueMedia stores the media keys of a new message and queues the download if the device
+// downloads this media type on arrival; other media waits until it is first opened.
+func (w *EventHandler) queueMedia(ctx context.Context, messageID pgtype.UUID, messageType string, media whatsmeow.DownloadableMessage) {
+	status := MediaStatusOnDemand
+	if w.autoDownloads(ctx, messageType) {
+		status = MediaStatusPending
+	}
+	w.storeMediaKeys(ctx, messageID, media, status)
+}
+
+func (w *EventHandler) autoDownloads(ctx context.Context, messageType string) bool {
+	settings, err := w.db.GetMediaDownloadSettings(ctx, w.clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return slices.Contains(DefaultAutoDownloadTypes, messageType)
+	}
+	if err != nil {
+		logger.Error("Failed to load media download settings for device %s: %v", w.clientID, err)
+		return true
+	}
+	return slices.Contains(settings.AutoDownloadTypes, messageType)
+}
+
+// storeMediaKeys keeps what is needed to download the media of a message later.
+func (w *EventHandler) storeMediaKeys(ctx context.Context, messageID pgtype.UUID, media whatsmeow.DownloadableMessage, status string) {
+	if media.GetDirectPath() == "" {
+		return
+	}
+
+	params := db.CreateMessageMediaParams{
+		MessageID:     messageID,
+		MediaType:     string(whatsmeow.GetMediaType(media)),
+		DirectPath:    media.GetDirectPath(),
+		MediaKey:      media.GetMediaKey(),
+		FileEncSha256: media.GetFileEncSHA256(),
+		FileSha256:    media.GetFileSHA256(),
+		Status:        status,
+	}
+	if m, ok := media.(interface{ GetMimetype() string }); ok && m.GetMimetype() != "" {
+		// Strip parameters such as "; codecs=opus"
+		params.Mimetype = pgtype.Text{String: strings.TrimSpace(strings.Split(m.GetMimetype(), ";")[0]), Valid: true}
+	}
+	if m, ok := media.(interface{ GetFileLength() uint64 }); ok && m.GetFileLength() > 0 {
+		params.FileLength = pgtype.Int8{Int64: int64(m.GetFileLength()), Valid: true}
+	}
+
+	if err := w.db.CreateMessageMedia(ctx, params); err != nil {
+		logger.Error("Failed to store media keys of message %s: %v", UUID2String(messageID), err)
+	}
+}
+
+// handleMediaRetry resumes the download of expired media once the phone has uploaded it again.
+func (w *EventHandler) handleMediaRetry(evt *events.MediaRetry) {
+	ctx := context.Background()
+
+	media, err := w.db.GetMessageMediaByWaID(ctx, db.GetMessageMediaByWaIDParams{
+		DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+		WaMessageID: pgtype.Text{String: evt.MessageID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.Debug("Ignoring media retry for unknown message %s", evt.MessageID)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load media of message %s: %v", evt.MessageID, err)
+		return
+	}
+
+	notification, err := whatsmeow.DecryptMediaRetryNotification(evt, media.MediaKey)
+	if err == nil && notification.GetResult() != waMmsRetry.MediaRetryNotification_SUCCESS {
+		err = fmt.Errorf("phone could not upload media: %s", notification.GetResult())
+	}
+	if err != nil {
+		// The phone no longer has the media, so retrying will not help
+		logger.Warn("Media retry for message %s failed: %v", evt.MessageID, err)
+		if err := w.db.FailMediaDownload(ctx, db.FailMediaDownloadParams{
+			LastError: pgtype.Text{String: err.Error(), Valid: true},
+			MessageID: media.MessageID,
+		}); err != nil {
+			logger.Error("Failed to mark media of message %s as failed: %v", evt.MessageID, err)
+		}
+		return
+	}
+
+	if err := w.db.UpdateMediaDirectPath(ctx, db.UpdateMediaDirectPathParams{
+		MessageID:  media.MessageID,
+		DirectPath: notification.GetDirectPath(),
+	}); err != nil {
+		logger.Error("Failed to update media path of message %s: %v", evt.MessageID, err)
+		return
+	}
+	logger.Debug("Media of message %s is available again, queued download", evt.MessageID)
+}
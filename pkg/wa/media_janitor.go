@@ -0,0 +1,282 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/fransfilastap/kontak/pkg/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Reasons the media janitor removes media for
+const (
+	MediaCleanupRetention   = "retention"
+	MediaCleanupDeviceQuota = "device_quota"
+	MediaCleanupUserQuota   = "user_quota"
+)
+
+const (
+	mediaJanitorInterval  = time.Hour
+	mediaJanitorBatchSize = 500
+)
+
+// MediaJanitor enforces the media retention rules and storage quotas in the background.
+// Removed media leaves its messages behind as placeholders, and every cleanup that removed
+// something is recorded per device.
+type MediaJanitor struct {
+	db           db.Querier
+	mediaStorage storage.Storage
+	userQuota    int64
+}
+
+// NewMediaJanitor creates a MediaJanitor. userQuota is the number of bytes the devices of
+// one user may store together, or 0 for no limit.
+func NewMediaJanitor(db db.Querier, mediaStorage storage.Storage, userQuota int64) *MediaJanitor {
+	return &MediaJanitor{
+		db:           db,
+		mediaStorage: mediaStorage,
+		userQuota:    userQuota,
+	}
+}
+
+func (j *MediaJanitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(mediaJanitorInterval)
+	defer ticker.Stop()
+
+	// Clean up right away so a restart does not postpone the next run by an hour
+	j.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+// mediaCleanup counts what one cleanup removed from a device.
+type mediaCleanup struct {
+	messages int64
+	objects  int32
+	bytes    int64
+}
+
+func (j *MediaJanitor) run(ctx context.Context) {
+	settings, err := j.db.ListMediaRetentionSettings(ctx)
+	if err != nil {
+		logger.Error("Failed to load media retention settings: %v", err)
+		return
+	}
+
+	for _, s := range settings {
+		j.applyRetention(ctx, s)
+	}
+	j.enforceQuotas(ctx, settings)
+}
+
+// applyRetention removes the media of a device that is older than the retention of its type.
+func (j *MediaJanitor) applyRetention(ctx context.Context, settings db.MediaRetentionSetting) {
+	rules := map[string]pgtype.Int4{
+		"image":    settings.ImageRetentionDays,
+		"video":    settings.VideoRetentionDays,
+		"document": settings.DocumentRetentionDays,
+		"audio":    settings.AudioRetentionDays,
+	}
+
+	var result mediaCleanup
+	for messageType, days := range rules {
+		if !days.Valid {
+			continue
+		}
+
+		before := time.Now().AddDate(0, 0, -int(days.Int32))
+		for {
+			keys, err := j.db.ExpireMediaBefore(ctx, db.ExpireMediaBeforeParams{
+				DeviceID:    pgtype.Text{String: settings.DeviceID, Valid: true},
+				MessageType: pgtype.Text{String: messageType, Valid: true},
+				Before:      pgtype.Timestamptz{Time: before, Valid: true},
+				QueryLimit:  mediaJanitorBatchSize,
+			})
+			if err != nil {
+				logger.Error("Failed to expire %s media of device %s: %v", messageType, settings.DeviceID, err)
+				break
+			}
+
+			result.messages += int64(len(keys))
+			seen := make(map[string]bool, len(keys))
+			for _, key := range keys {
+				if !seen[key] {
+					seen[key] = true
+					j.deleteObject(ctx, settings.DeviceID, key, &result)
+				}
+			}
+			if len(keys) < mediaJanitorBatchSize {
+				break
+			}
+		}
+	}
+
+	j.report(ctx, settings.DeviceID, MediaCleanupRetention, result)
+}
+
+// enforceQuotas evicts the oldest media of devices and users that store more than their quota.
+func (j *MediaJanitor) enforceQuotas(ctx context.Context, settings []db.MediaRetentionSetting) {
+	usage, err := j.db.ListDeviceMediaUsage(ctx)
+	if err != nil {
+		logger.Error("Failed to load media usage: %v", err)
+		return
+	}
+
+	used := make(map[string]int64, len(usage))
+	owners := make(map[string]int32, len(usage))
+	userDevices := make(map[int32][]string)
+	userUsed := make(map[int32]int64)
+	for _, u := range usage {
+		used[u.DeviceID] = u.SizeBytes
+		if u.UserID.Valid {
+			owners[u.DeviceID] = u.UserID.Int32
+			userDevices[u.UserID.Int32] = append(userDevices[u.UserID.Int32], u.DeviceID)
+			userUsed[u.UserID.Int32] += u.SizeBytes
+		}
+	}
+
+	for _, s := range settings {
+		if !s.QuotaBytes.Valid || used[s.DeviceID] <= s.QuotaBytes.Int64 {
+			continue
+		}
+		freed := j.evict(ctx, []string{s.DeviceID}, used[s.DeviceID]-s.QuotaBytes.Int64, MediaCleanupDeviceQuota)
+		if userID, ok := owners[s.DeviceID]; ok {
+			userUsed[userID] -= freed
+		}
+	}
+
+	if j.userQuota <= 0 {
+		return
+	}
+	for userID, devices := range userDevices {
+		if userUsed[userID] > j.userQuota {
+			j.evict(ctx, devices, userUsed[userID]-j.userQuota, MediaCleanupUserQuota)
+		}
+	}
+}
+
+// evict removes the oldest media of the given devices until at least excess bytes are freed
+// and returns the bytes freed.
+func (j *MediaJanitor) evict(ctx context.Context, deviceIDs []string, excess int64, reason string) int64 {
+	results := make(map[string]*mediaCleanup)
+	var freed int64
+
+	for freed < excess {
+		objects, err := j.db.ListOldestMediaObjects(ctx, db.ListOldestMediaObjectsParams{
+			DeviceIds:  deviceIDs,
+			QueryLimit: mediaJanitorBatchSize,
+		})
+		if err != nil {
+			logger.Error("Failed to list media objects for eviction: %v", err)
+			break
+		}
+
+		deleted := 0
+		for _, o := range objects {
+			if freed >= excess {
+				break
+			}
+			result, ok := results[o.DeviceID]
+			if !ok {
+				result = &mediaCleanup{}
+				results[o.DeviceID] = result
+			}
+
+			messages, err := j.db.ExpireMediaObject(ctx, db.ExpireMediaObjectParams{
+				DeviceID: pgtype.Text{String: o.DeviceID, Valid: true},
+				MediaUrl: pgtype.Text{String: MediaURLPrefix + o.StorageKey, Valid: true},
+			})
+			if err != nil {
+				logger.Error("Failed to expire media %s of device %s: %v", o.StorageKey, o.DeviceID, err)
+				continue
+			}
+			result.messages += messages
+
+			prevObjects, prevBytes := result.objects, result.bytes
+			j.deleteObject(ctx, o.DeviceID, o.StorageKey, result)
+			if result.objects > prevObjects {
+				deleted++
+				freed += result.bytes - prevBytes
+			}
+		}
+
+		// Stop when nothing could be removed, so a failing object does not loop forever
+		if deleted == 0 || len(objects) < mediaJanitorBatchSize {
+			break
+		}
+	}
+
+	for deviceID, result := range results {
+		j.report(ctx, deviceID, reason, *result)
+	}
+	return freed
+}
+
+// deleteObject removes the media object of a device once none of its messages shows it
+// anymore, and the stored data once no device references it.
+func (j *MediaJanitor) deleteObject(ctx context.Context, deviceID, key string, result *mediaCleanup) {
+	size, err := j.db.DeleteUnusedMediaObject(ctx, db.DeleteUnusedMediaObjectParams{
+		DeviceID:   deviceID,
+		StorageKey: key,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Still shown by a newer message, or already gone
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to delete media object %s of device %s: %v", key, deviceID, err)
+		return
+	}
+	result.objects++
+	result.bytes += size
+
+	// Cached profile pictures are kept above; should one go anyway, forget it so the next
+	// refresh downloads it again instead of being told it did not change
+	if err := j.db.ClearProfilePictureByURL(ctx, db.ClearProfilePictureByURLParams{
+		DeviceID:   deviceID,
+		PictureUrl: pgtype.Text{String: MediaURLPrefix + key, Valid: true},
+	}); err != nil {
+		logger.Error("Failed to clear profile picture %s of device %s: %v", key, deviceID, err)
+	}
+
+	devices, err := j.db.GetMediaObjectDevices(ctx, key)
+	if err != nil {
+		logger.Error("Failed to look up media %s: %v", key, err)
+		return
+	}
+	if len(devices) > 0 {
+		return
+	}
+	if err := j.mediaStorage.Delete(ctx, key); err != nil {
+		logger.Error("Failed to delete media %s from storage: %v", key, err)
+	}
+}
+
+// report logs and records a cleanup that removed something.
+func (j *MediaJanitor) report(ctx context.Context, deviceID, reason string, result mediaCleanup) {
+	if result.messages == 0 && result.objects == 0 {
+		return
+	}
+
+	logger.Info("Media janitor (%s) removed %d media objects (%d bytes) and cleared %d messages of device %s",
+		reason, result.objects, result.bytes, result.messages, deviceID)
+	if err := j.db.CreateMediaCleanupRun(ctx, db.CreateMediaCleanupRunParams{
+		DeviceID:        deviceID,
+		Reason:          reason,
+		MessagesCleared: int32(result.messages),
+		ObjectsDeleted:  result.objects,
+		BytesFreed:      result.bytes,
+	}); err != nil {
+		logger.Error("Failed to record media cleanup of device %s: %v", deviceID, err)
+	}
+}
@@ -0,0 +1,123 @@
+package wa
+
+import (
+	"context"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// handleMessageChange applies edits, deletions for everyone and reactions, which WhatsApp
+// delivers as messages of their own that point at the message they change. It reports
+// whether evt was such a change; the stored message is updated in place and the change
+// shows up in the inbox sync feed.
+func (w *EventHandler) handleMessageChange(evt *events.Message) bool {
+	if reaction := evt.Message.GetReactionMessage(); reaction != nil {
+		w.handleReaction(evt, reaction)
+		return true
+	}
+
+	protocol := evt.Message.GetProtocolMessage()
+	if protocol == nil {
+		return false
+	}
+
+	switch protocol.GetType() {
+	case waE2E.ProtocolMessage_MESSAGE_EDIT:
+		w.handleEdit(protocol)
+	case waE2E.ProtocolMessage_REVOKE:
+		w.handleRevoke(protocol)
+	default:
+		return false
+	}
+	return true
+}
+
+func (w *EventHandler) handleReaction(evt *events.Message, reaction *waE2E.ReactionMessage) {
+	ctx := context.Background()
+	targetID := reaction.GetKey().GetID()
+	senderJID := evt.Info.Sender.ToNonAD().String()
+
+	// An empty reaction removes the previous one
+	if reaction.GetText() == "" {
+		if err := w.db.DeleteMessageReaction(ctx, db.DeleteMessageReactionParams{
+			DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+			WaMessageID: pgtype.Text{String: targetID, Valid: true},
+			SenderJid:   senderJID,
+		}); err != nil {
+			logger.Error("Failed to remove reaction of %s on message %s: %v", senderJID, targetID, err)
+		}
+		return
+	}
+
+	reactedAt := evt.Info.Timestamp
+	if ms := reaction.GetSenderTimestampMS(); ms > 0 {
+		reactedAt = time.UnixMilli(ms)
+	}
+
+	n, err := w.db.UpsertMessageReaction(ctx, db.UpsertMessageReactionParams{
+		SenderJid:   senderJID,
+		Emoji:       reaction.GetText(),
+		ReactedAt:   pgtype.Timestamptz{Time: reactedAt, Valid: true},
+		DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+		WaMessageID: pgtype.Text{String: targetID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to store reaction of %s on message %s: %v", senderJID, targetID, err)
+	} else if n == 0 {
+		logger.Debug("Ignoring reaction on unknown message %s", targetID)
+	}
+}
+
+func (w *EventHandler) handleEdit(protocol *waE2E.ProtocolMessage) {
+	targetID := protocol.GetKey().GetID()
+	text := editedText(protocol.GetEditedMessage())
+	if text == "" {
+		return
+	}
+
+	n, err := w.db.EditMessageContent(context.Background(), db.EditMessageContentParams{
+		DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+		WaMessageID: pgtype.Text{String: targetID, Valid: true},
+		Content:     text,
+	})
+	if err != nil {
+		logger.Error("Failed to apply edit to message %s: %v", targetID, err)
+	} else if n == 0 {
+		logger.Debug("Ignoring edit of unknown message %s", targetID)
+	}
+}
+
+func (w *EventHandler) handleRevoke(protocol *waE2E.ProtocolMessage) {
+	targetID := protocol.GetKey().GetID()
+	n, err := w.db.DeleteMessageByWaID(context.Background(), db.DeleteMessageByWaIDParams{
+		DeviceID:    pgtype.Text{String: w.clientID, Valid: true},
+		WaMessageID: pgtype.Text{String: targetID, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to delete revoked message %s: %v", targetID, err)
+	} else if n > 0 {
+		logger.Debug("Deleted revoked message %s", targetID)
+	}
+}
+
+// editedText returns the new text of an edited message; only text and captions can be edited.
+func editedText(msg *waE2E.Message) string {
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation()
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetCaption()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetCaption()
+	}
+	return ""
+}
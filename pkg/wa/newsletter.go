@@ -0,0 +1,298 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// Live event types published for channels. They use the same names as the event
+// subscriptions that enable them.
+const (
+	LiveEventNewsletterJoin   = "newsletter_join"
+	LiveEventNewsletterLeave  = "newsletter_leave"
+	LiveEventNewsletterUpdate = "newsletter_live_update"
+)
+
+// Newsletter is a WhatsApp channel as exposed by the API.
+type Newsletter struct {
+	JID         string    `json:"jid"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	InviteCode  string    `json:"invite_code"`
+	Followers   int       `json:"followers"`
+	Role        string    `json:"role,omitempty"`
+	Verified    bool      `json:"verified"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewsletterPost is a message of a channel with its view and reaction counts.
+type NewsletterPost struct {
+	ServerID    int            `json:"server_id"`
+	MessageID   string         `json:"message_id"`
+	Type        string         `json:"type"`
+	Text        string         `json:"text,omitempty"`
+	MessageType string         `json:"message_type,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Views       int            `json:"views"`
+	Reactions   map[string]int `json:"reactions"`
+}
+
+// NewsletterLeaveEvent is the live event published when the device stops following a channel.
+type NewsletterLeaveEvent struct {
+	JID  string `json:"jid"`
+	Role string `json:"role"`
+}
+
+// NewsletterUpdateEvent is the live event published when the view or reaction counts of
+// channel posts change.
+type NewsletterUpdateEvent struct {
+	JID   string           `json:"jid"`
+	Time  time.Time        `json:"time"`
+	Posts []NewsletterPost `json:"posts"`
+}
+
+func newNewsletter(meta *types.NewsletterMetadata) Newsletter {
+	n := Newsletter{
+		JID:         meta.ID.String(),
+		Name:        meta.ThreadMeta.Name.Text,
+		Description: meta.ThreadMeta.Description.Text,
+		InviteCode:  meta.ThreadMeta.InviteCode,
+		Followers:   meta.ThreadMeta.SubscriberCount,
+		Verified:    meta.ThreadMeta.VerificationState == types.NewsletterVerificationStateVerified,
+		CreatedAt:   meta.ThreadMeta.CreationTime.Time,
+	}
+	if meta.ViewerMeta != nil {
+		n.Role = string(meta.ViewerMeta.Role)
+	}
+	return n
+}
+
+func newNewsletterPost(msg *types.NewsletterMessage) NewsletterPost {
+	post := NewsletterPost{
+		ServerID:  msg.MessageServerID,
+		MessageID: msg.MessageID,
+		Type:      msg.Type,
+		Timestamp: msg.Timestamp,
+		Views:     msg.ViewsCount,
+		Reactions: msg.ReactionCounts,
+	}
+	if post.Reactions == nil {
+		post.Reactions = map[string]int{}
+	}
+	if msg.Message != nil {
+		post.Text, post.MessageType, _, _ = messageContent(msg.Message)
+	}
+	return post
+}
+
+// CreateNewsletter creates a channel owned by the device.
+func (w *WhatsappClient) CreateNewsletter(clientID, name, description string) (Newsletter, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return Newsletter{}, fmt.Errorf("client %s not found", clientID)
+	}
+
+	meta, err := client.CreateNewsletter(context.Background(), whatsmeow.CreateNewsletterParams{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return Newsletter{}, fmt.Errorf("failed to create channel: %w", err)
+	}
+	return newNewsletter(meta), nil
+}
+
+// GetNewsletters lists the channels the device owns, administers or follows.
+func (w *WhatsappClient) GetNewsletters(clientID string) ([]Newsletter, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	list, err := client.GetSubscribedNewsletters(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	newsletters := make([]Newsletter, 0, len(list))
+	for _, meta := range list {
+		newsletters = append(newsletters, newNewsletter(meta))
+	}
+	return newsletters, nil
+}
+
+// GetNewsletter returns a channel with its current follower count.
+func (w *WhatsappClient) GetNewsletter(clientID string, jid types.JID) (Newsletter, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return Newsletter{}, fmt.Errorf("client %s not found", clientID)
+	}
+
+	meta, err := client.GetNewsletterInfo(context.Background(), jid)
+	if err != nil {
+		return Newsletter{}, fmt.Errorf("failed to get channel: %w", err)
+	}
+	return newNewsletter(meta), nil
+}
+
+// GetNewsletterPosts returns the latest posts of a channel with their view and reaction
+// counts, newest last. before pages back from a server ID; 0 starts at the newest post.
+func (w *WhatsappClient) GetNewsletterPosts(clientID string, jid types.JID, count, before int) ([]NewsletterPost, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	messages, err := client.GetNewsletterMessages(context.Background(), jid, &whatsmeow.GetNewsletterMessagesParams{
+		Count:  count,
+		Before: before,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel posts: %w", err)
+	}
+	posts := make([]NewsletterPost, 0, len(messages))
+	for _, msg := range messages {
+		posts = append(posts, newNewsletterPost(msg))
+	}
+	return posts, nil
+}
+
+// PostNewsletterText posts a text update to a channel and returns the message ID and the
+// server ID that the view and reaction counts refer to.
+func (w *WhatsappClient) PostNewsletterText(clientID string, jid types.JID, text string) (string, int, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", 0, fmt.Errorf("client %s not found", clientID)
+	}
+
+	resp, err := client.SendMessage(context.Background(), jid, &waE2E.Message{
+		Conversation: proto.String(text),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to post to channel: %w", err)
+	}
+	return resp.ID, resp.ServerID, nil
+}
+
+// PostNewsletterMedia posts an image, video, audio or document update to a channel. Channel
+// media is not encrypted, so it is uploaded differently from chat media.
+func (w *WhatsappClient) PostNewsletterMedia(clientID string, jid types.JID, data []byte, fileName, contentType, caption string) (string, int, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", 0, fmt.Errorf("client %s not found", clientID)
+	}
+
+	var mediaType whatsmeow.MediaType
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		mediaType = whatsmeow.MediaImage
+	case strings.HasPrefix(contentType, "video/"):
+		mediaType = whatsmeow.MediaVideo
+	case strings.HasPrefix(contentType, "audio/"):
+		mediaType = whatsmeow.MediaAudio
+	default:
+		mediaType = whatsmeow.MediaDocument
+	}
+
+	ctx := context.Background()
+	upload, err := client.UploadNewsletter(ctx, data, mediaType)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	var msg waE2E.Message
+	switch mediaType {
+	case whatsmeow.MediaImage:
+		msg.ImageMessage = &waE2E.ImageMessage{
+			Caption:    proto.String(caption),
+			Mimetype:   proto.String(contentType),
+			URL:        &upload.URL,
+			DirectPath: &upload.DirectPath,
+			FileSHA256: upload.FileSHA256,
+			FileLength: &upload.FileLength,
+		}
+	case whatsmeow.MediaVideo:
+		msg.VideoMessage = &waE2E.VideoMessage{
+			Caption:    proto.String(caption),
+			Mimetype:   proto.String(contentType),
+			URL:        &upload.URL,
+			DirectPath: &upload.DirectPath,
+			FileSHA256: upload.FileSHA256,
+			FileLength: &upload.FileLength,
+		}
+	case whatsmeow.MediaAudio:
+		msg.AudioMessage = &waE2E.AudioMessage{
+			Mimetype:   proto.String(contentType),
+			URL:        &upload.URL,
+			DirectPath: &upload.DirectPath,
+			FileSHA256: upload.FileSHA256,
+			FileLength: &upload.FileLength,
+		}
+	default:
+		msg.DocumentMessage = &waE2E.DocumentMessage{
+			Title:      proto.String(fileName),
+			FileName:   proto.String(fileName),
+			Caption:    proto.String(caption),
+			Mimetype:   proto.String(contentType),
+			URL:        &upload.URL,
+			DirectPath: &upload.DirectPath,
+			FileSHA256: upload.FileSHA256,
+			FileLength: &upload.FileLength,
+		}
+	}
+
+	resp, err := client.SendMessage(ctx, jid, &msg, whatsmeow.SendRequestExtra{MediaHandle: upload.Handle})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to post to channel: %w", err)
+	}
+	return resp.ID, resp.ServerID, nil
+}
+
+// SubscribeNewsletterUpdates asks WhatsApp to report view and reaction count changes of a
+// channel on the event stream. The subscription expires after the returned duration and
+// must be renewed to keep receiving updates.
+func (w *WhatsappClient) SubscribeNewsletterUpdates(clientID string, jid types.JID) (time.Duration, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return 0, fmt.Errorf("client %s not found", clientID)
+	}
+
+	duration, err := client.NewsletterSubscribeLiveUpdates(context.Background(), jid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to subscribe to channel updates: %w", err)
+	}
+	return duration, nil
+}
+
+func (w *EventHandler) handleNewsletterJoin(evt *events.NewsletterJoin) {
+	logger.Info("Joined channel %s", evt.ID)
+	w.eventBroker.Publish(w.clientID, LiveEventNewsletterJoin, newNewsletter(&evt.NewsletterMetadata))
+}
+
+func (w *EventHandler) handleNewsletterLeave(evt *events.NewsletterLeave) {
+	logger.Info("Left channel %s", evt.ID)
+	w.eventBroker.Publish(w.clientID, LiveEventNewsletterLeave, NewsletterLeaveEvent{
+		JID:  evt.ID.String(),
+		Role: string(evt.Role),
+	})
+}
+
+func (w *EventHandler) handleNewsletterLiveUpdate(evt *events.NewsletterLiveUpdate) {
+	data := NewsletterUpdateEvent{
+		JID:   evt.JID.String(),
+		Time:  evt.Time,
+		Posts: make([]NewsletterPost, 0, len(evt.Messages)),
+	}
+	for _, msg := range evt.Messages {
+		data.Posts = append(data.Posts, newNewsletterPost(msg))
+	}
+	w.eventBroker.Publish(w.clientID, LiveEventNewsletterUpdate, data)
+}
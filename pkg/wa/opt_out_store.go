@@ -0,0 +1,146 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Opt-out sources recorded alongside each suppression list entry.
+const (
+	OptOutSourceKeyword = "keyword"
+	OptOutSourceManual  = "manual"
+	OptOutSourceAPI     = "api"
+)
+
+// DefaultOptOutKeywords are used until a user saves their own opt-out settings.
+var DefaultOptOutKeywords = []string{"STOP", "UNSUBSCRIBE", "BERHENTI"}
+
+// DefaultOptOutConfirmation is the reply sent after a keyword opt-out when confirmations are enabled.
+const DefaultOptOutConfirmation = "You have been unsubscribed and will no longer receive messages from us."
+
+// OptOutStore manages the per-user suppression list of recipients that asked not to be messaged.
+type OptOutStore struct {
+	dbQueries db.Querier
+}
+
+// NewOptOutStore creates a new instance of OptOutStore.
+func NewOptOutStore(dbQueries db.Querier) *OptOutStore {
+	return &OptOutStore{
+		dbQueries: dbQueries,
+	}
+}
+
+// IsOptedOut reports whether the recipient is on the user's suppression list.
+// The recipient may be a phone number or a full JID.
+func (s *OptOutStore) IsOptedOut(ctx context.Context, userID int32, recipient string) (bool, error) {
+	jid, err := getJID(recipient)
+	if err != nil {
+		return false, err
+	}
+
+	return s.dbQueries.IsOptedOut(ctx, db.IsOptedOutParams{
+		UserID: userID,
+		Jid:    jid.String(),
+	})
+}
+
+// Add puts the recipient on the user's suppression list.
+func (s *OptOutStore) Add(ctx context.Context, userID int32, recipient, deviceID, source, keyword, reason string) (db.OptOut, error) {
+	jid, err := getJID(recipient)
+	if err != nil {
+		return db.OptOut{}, err
+	}
+
+	optOut, err := s.dbQueries.AddOptOut(ctx, db.AddOptOutParams{
+		UserID:   userID,
+		Jid:      jid.String(),
+		DeviceID: pgtype.Text{String: deviceID, Valid: deviceID != ""},
+		Source:   source,
+		Keyword:  pgtype.Text{String: keyword, Valid: keyword != ""},
+		Reason:   pgtype.Text{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		return db.OptOut{}, fmt.Errorf("failed to add opt-out: %w", err)
+	}
+	return optOut, nil
+}
+
+// Remove takes the recipient off the user's suppression list.
+func (s *OptOutStore) Remove(ctx context.Context, userID int32, recipient string) error {
+	jid, err := getJID(recipient)
+	if err != nil {
+		return err
+	}
+
+	return s.dbQueries.RemoveOptOut(ctx, db.RemoveOptOutParams{
+		UserID: userID,
+		Jid:    jid.String(),
+	})
+}
+
+// List returns every suppression list entry for the user.
+func (s *OptOutStore) List(ctx context.Context, userID int32) ([]db.OptOut, error) {
+	optOuts, err := s.dbQueries.GetOptOuts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if optOuts == nil {
+		optOuts = []db.OptOut{}
+	}
+	return optOuts, nil
+}
+
+// GetSettings returns the user's opt-out settings, falling back to the defaults when none were saved.
+func (s *OptOutStore) GetSettings(ctx context.Context, userID int32) (db.OptOutSetting, error) {
+	settings, err := s.dbQueries.GetOptOutSettings(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.OptOutSetting{
+			UserID:              userID,
+			Keywords:            DefaultOptOutKeywords,
+			ConfirmationMessage: DefaultOptOutConfirmation,
+		}, nil
+	}
+	return settings, err
+}
+
+// SaveSettings stores the user's opt-out keywords and confirmation preferences.
+func (s *OptOutStore) SaveSettings(ctx context.Context, userID int32, keywords []string, sendConfirmation bool, confirmationMessage string) (db.OptOutSetting, error) {
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.ToUpper(strings.TrimSpace(keyword))
+		if keyword != "" {
+			normalized = append(normalized, keyword)
+		}
+	}
+	if confirmationMessage == "" {
+		confirmationMessage = DefaultOptOutConfirmation
+	}
+
+	return s.dbQueries.UpsertOptOutSettings(ctx, db.UpsertOptOutSettingsParams{
+		UserID:              userID,
+		Keywords:            normalized,
+		SendConfirmation:    sendConfirmation,
+		ConfirmationMessage: confirmationMessage,
+	})
+}
+
+// MatchKeyword returns the configured keyword the text consists of, if any.
+// Matching is case-insensitive and ignores surrounding whitespace and punctuation.
+func MatchKeyword(keywords []string, text string) (string, bool) {
+	text = strings.ToUpper(strings.Trim(strings.TrimSpace(text), ".!?"))
+	if text == "" {
+		return "", false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(keyword, text) {
+			return keyword, true
+		}
+	}
+	return "", false
+}
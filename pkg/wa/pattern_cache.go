@@ -0,0 +1,38 @@
+package wa
+
+import (
+	"regexp"
+	"sync"
+)
+
+// maxCachedPatterns bounds the pattern cache. Patterns come from stored rules and flows, so
+// the cache is only cleared when many of them are edited over time.
+const maxCachedPatterns = 1024
+
+var patternCache = struct {
+	mu       sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}{patterns: make(map[string]*regexp.Regexp)}
+
+// compilePattern compiles a regex of a rule or flow once and reuses it for every message.
+// Invalid patterns are not cached, they are refused when the rule or flow is saved.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternCache.mu.RLock()
+	re, ok := patternCache.patterns[pattern]
+	patternCache.mu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.mu.Lock()
+	if len(patternCache.patterns) >= maxCachedPatterns {
+		patternCache.patterns = make(map[string]*regexp.Regexp)
+	}
+	patternCache.patterns[pattern] = re
+	patternCache.mu.Unlock()
+	return re, nil
+}
@@ -0,0 +1,136 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// DefaultCountryCode is the calling code given to phone numbers written in national format
+// with a leading 0, e.g. 62 turns 0812… into 62812…. Empty rejects national numbers.
+var DefaultCountryCode = "62"
+
+// ErrInvalidPhoneNumber is returned for input that cannot be turned into an E.164 number.
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// NumberCheck is the result of looking up a phone number on WhatsApp.
+type NumberCheck struct {
+	Input string `json:"input"`
+	// Phone is the number in E.164 format, empty when the input is not a valid number
+	Phone        string `json:"phone,omitempty"`
+	Valid        bool   `json:"valid"`
+	OnWhatsApp   bool   `json:"on_whatsapp"`
+	JID          string `json:"jid,omitempty"`
+	IsBusiness   bool   `json:"is_business"`
+	VerifiedName string `json:"verified_name,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// NormalizePhoneNumber turns a phone number into the digits of its E.164 form, without the
+// leading +. Spaces, dashes, dots and parentheses are ignored; 00 is read as the
+// international prefix and a single leading 0 as the national trunk prefix of countryCode.
+func NormalizePhoneNumber(number, countryCode string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		if countryCode == "" {
+			return "", fmt.Errorf("%w: %s has no country code", ErrInvalidPhoneNumber, number)
+		}
+		digits = countryCode + digits[1:]
+	}
+
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPhoneNumber, number)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPhoneNumber, number)
+		}
+	}
+	return digits, nil
+}
+
+// PhoneJID returns the user JID of a phone number normalized with DefaultCountryCode.
+func PhoneJID(number string) (types.JID, error) {
+	phone, err := NormalizePhoneNumber(number, DefaultCountryCode)
+	if err != nil {
+		return types.JID{}, err
+	}
+	return types.NewJID(phone, types.DefaultUserServer), nil
+}
+
+// NormalizeRecipient returns the JID of a message recipient given as a phone number or a
+// JID. Phone numbers are normalized with DefaultCountryCode; JIDs are kept as they are.
+func NormalizeRecipient(recipient string) (string, error) {
+	jid, err := getJID(recipient)
+	if err != nil {
+		return "", err
+	}
+	return jid.String(), nil
+}
+
+// CheckNumbers normalizes phone numbers and looks them up on WhatsApp. Results are in the
+// order of numbers; invalid numbers are reported without being looked up.
+func (w *WhatsappClient) CheckNumbers(clientID string, numbers []string) ([]NumberCheck, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+
+	checks := make([]NumberCheck, len(numbers))
+	var queries []string
+	for i, number := range numbers {
+		checks[i].Input = number
+		phone, err := NormalizePhoneNumber(number, DefaultCountryCode)
+		if err != nil {
+			checks[i].Error = err.Error()
+			continue
+		}
+		checks[i].Phone = "+" + phone
+		checks[i].Valid = true
+		queries = append(queries, checks[i].Phone)
+	}
+	if len(queries) == 0 {
+		return checks, nil
+	}
+
+	responses, err := client.IsOnWhatsApp(context.Background(), queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check numbers: %w", err)
+	}
+	found := make(map[string]types.IsOnWhatsAppResponse, len(responses))
+	for _, resp := range responses {
+		found[strings.TrimPrefix(resp.Query, "+")] = resp
+	}
+
+	for i := range checks {
+		if !checks[i].Valid {
+			continue
+		}
+		resp, ok := found[strings.TrimPrefix(checks[i].Phone, "+")]
+		if !ok || !resp.IsIn {
+			continue
+		}
+		checks[i].OnWhatsApp = true
+		checks[i].JID = resp.JID.String()
+		if resp.VerifiedName != nil {
+			checks[i].IsBusiness = true
+			checks[i].VerifiedName = resp.VerifiedName.Details.GetVerifiedName()
+		}
+	}
+	return checks, nil
+}
@@ -0,0 +1,120 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Chat presence states as exposed by the API. WhatsApp itself only knows composing and
+// paused; recording is composing with audio media.
+const (
+	ChatStateComposing = "composing"
+	ChatStateRecording = "recording"
+	ChatStatePaused    = "paused"
+)
+
+// IsValidChatState reports whether state is one of the ChatState* constants.
+func IsValidChatState(state string) bool {
+	switch state {
+	case ChatStateComposing, ChatStateRecording, ChatStatePaused:
+		return true
+	}
+	return false
+}
+
+// ChatPresenceEvent is the live event published when someone starts or stops typing in a chat.
+type ChatPresenceEvent struct {
+	ChatJID   string `json:"chat_jid"`
+	SenderJID string `json:"sender_jid"`
+	State     string `json:"state"`
+}
+
+// PresenceEvent is the live event published when a subscribed contact goes online or offline.
+type PresenceEvent struct {
+	JID      string     `json:"jid"`
+	IsOnline bool       `json:"is_online"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// SendChatPresence shows the device as typing, recording audio or idle in a chat.
+func (w *WhatsappClient) SendChatPresence(clientID string, chat types.JID, state string) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	presence, media := types.ChatPresenceComposing, types.ChatPresenceMediaText
+	switch state {
+	case ChatStateRecording:
+		media = types.ChatPresenceMediaAudio
+	case ChatStatePaused:
+		presence = types.ChatPresencePaused
+	}
+
+	if err := client.SendChatPresence(context.Background(), chat, presence, media); err != nil {
+		return fmt.Errorf("failed to send chat presence: %w", err)
+	}
+	return nil
+}
+
+// SubscribePresence asks WhatsApp to report when a contact goes online or offline. The
+// subscription lasts until the device disconnects.
+func (w *WhatsappClient) SubscribePresence(clientID string, jid types.JID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	if err := client.SubscribePresence(context.Background(), jid); err != nil {
+		return fmt.Errorf("failed to subscribe to presence: %w", err)
+	}
+	return nil
+}
+
+func (w *EventHandler) handleChatPresence(evt *events.ChatPresence) {
+	state := ChatStateComposing
+	switch {
+	case evt.State == types.ChatPresencePaused:
+		state = ChatStatePaused
+	case evt.Media == types.ChatPresenceMediaAudio:
+		state = ChatStateRecording
+	}
+
+	w.eventBroker.Publish(w.clientID, LiveEventChatPresence, ChatPresenceEvent{
+		ChatJID:   evt.Chat.String(),
+		SenderJID: evt.Sender.ToNonAD().String(),
+		State:     state,
+	})
+}
+
+func (w *EventHandler) handlePresence(evt *events.Presence) {
+	jid := evt.From.ToNonAD().String()
+	data := PresenceEvent{JID: jid, IsOnline: !evt.Unavailable}
+
+	// Someone online is seen right now; otherwise WhatsApp may hide the last seen time
+	lastSeen := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	if evt.Unavailable {
+		lastSeen = pgtype.Timestamptz{Time: evt.LastSeen, Valid: !evt.LastSeen.IsZero()}
+	}
+	if lastSeen.Valid {
+		data.LastSeen = &lastSeen.Time
+	}
+
+	if err := w.db.SetContactPresence(context.Background(), db.SetContactPresenceParams{
+		DeviceID:   pgtype.Text{String: w.clientID, Valid: true},
+		Jid:        jid,
+		IsOnline:   !evt.Unavailable,
+		LastSeenAt: lastSeen,
+	}); err != nil {
+		logger.Error("Failed to store presence of %s: %v", jid, err)
+	}
+
+	w.eventBroker.Publish(w.clientID, LiveEventPresence, data)
+}
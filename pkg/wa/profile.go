@@ -0,0 +1,321 @@
+package wa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// userInfoBatchSize is how many users are asked for their about text and picture ID at once.
+const userInfoBatchSize = 100
+
+var profilePictureClient = &http.Client{Timeout: 30 * time.Second}
+
+// ContactBusinessHours are the opening hours of a contact's business on one day of the
+// week. Mode is specific_hours, open_24h or appointment_only; the times are minutes since
+// midnight.
+type ContactBusinessHours struct {
+	Day       string `json:"day"`
+	Mode      string `json:"mode"`
+	OpenTime  string `json:"open_time,omitempty"`
+	CloseTime string `json:"close_time,omitempty"`
+}
+
+// RefreshProfile fetches the picture, about text and business profile of a contact, or the
+// picture of a group, and caches them.
+func (w *WhatsappClient) RefreshProfile(clientID string, jid types.JID) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	ctx := context.Background()
+	existing := ""
+	if profile, err := w.db.GetProfile(ctx, db.GetProfileParams{DeviceID: clientID, Jid: jid.String()}); err == nil {
+		existing = profile.PictureID.String
+	}
+	if err := w.refreshPicture(ctx, client, clientID, jid, existing); err != nil {
+		return err
+	}
+	if jid.Server == types.DefaultUserServer {
+		return w.refreshUsers(ctx, client, clientID, []types.JID{jid})
+	}
+	return nil
+}
+
+// RefreshDeviceProfiles refreshes the cached profiles of all contacts and groups of a
+// device. Pictures that did not change since the last refresh are not downloaded again.
+func (w *WhatsappClient) RefreshDeviceProfiles(clientID string) error {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return fmt.Errorf("client %s not found", clientID)
+	}
+
+	ctx := context.Background()
+	deviceID := pgtype.Text{String: clientID, Valid: true}
+	contacts, err := w.db.GetDeviceContacts(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get contacts: %w", err)
+	}
+	groups, err := w.db.GetDeviceGroups(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get groups: %w", err)
+	}
+	pictures, err := w.db.ListProfilePictureIDs(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to get profile pictures: %w", err)
+	}
+	pictureIDs := make(map[string]string, len(pictures))
+	for _, p := range pictures {
+		pictureIDs[p.Jid] = p.PictureID.String
+	}
+
+	var users, jids []types.JID
+	for _, contact := range contacts {
+		jid, err := types.ParseJID(contact.Jid)
+		if err != nil || jid.Server != types.DefaultUserServer {
+			continue
+		}
+		users = append(users, jid)
+		jids = append(jids, jid)
+	}
+	for _, group := range groups {
+		if jid, err := types.ParseJID(group.GroupID); err == nil {
+			jids = append(jids, jid)
+		}
+	}
+
+	for start := 0; start < len(users); start += userInfoBatchSize {
+		end := min(start+userInfoBatchSize, len(users))
+		if err := w.refreshUsers(ctx, client, clientID, users[start:end]); err != nil {
+			logger.Error("Failed to refresh profiles of device %s: %v", clientID, err)
+		}
+	}
+	for _, jid := range jids {
+		if err := w.refreshPicture(ctx, client, clientID, jid, pictureIDs[jid.String()]); err != nil {
+			logger.Error("Failed to refresh picture of %s: %v", jid, err)
+		}
+	}
+	logger.Info("Refreshed profiles of %d contacts and %d groups of device %s", len(users), len(groups), clientID)
+	return nil
+}
+
+// refreshUsers stores the about text of users and fetches the business profile of those
+// that are businesses.
+func (w *WhatsappClient) refreshUsers(ctx context.Context, client *whatsmeow.Client, clientID string, jids []types.JID) error {
+	infos, err := client.GetUserInfo(ctx, jids)
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	now := time.Now()
+	for jid, info := range infos {
+		if err := w.db.SetProfileAbout(ctx, db.SetProfileAboutParams{
+			DeviceID:       clientID,
+			Jid:            jid.String(),
+			About:          pgtype.Text{String: info.Status, Valid: info.Status != ""},
+			AboutUpdatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		}); err != nil {
+			logger.Error("Failed to store about text of %s: %v", jid, err)
+		}
+
+		params := db.SetBusinessProfileParams{DeviceID: clientID, Jid: jid.String()}
+		if info.VerifiedName != nil {
+			if params, err = fetchBusinessProfile(ctx, client, clientID, jid); err != nil {
+				logger.Error("Failed to get business profile of %s: %v", jid, err)
+				continue
+			}
+		}
+		if err := w.db.SetBusinessProfile(ctx, params); err != nil {
+			logger.Error("Failed to store business profile of %s: %v", jid, err)
+		}
+	}
+	return nil
+}
+
+// fetchBusinessProfile gets the business profile of a user. whatsmeow leaves out the
+// description and websites, so the query is sent here and those are read from the response.
+func fetchBusinessProfile(ctx context.Context, client *whatsmeow.Client, clientID string, jid types.JID) (db.SetBusinessProfileParams, error) {
+	resp, err := client.DangerousInternals().SendIQ(ctx, whatsmeow.DangerousInfoQuery{
+		Namespace: "w:biz",
+		Type:      "get",
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag:   "business_profile",
+			Attrs: waBinary.Attrs{"v": "244"},
+			Content: []waBinary.Node{{
+				Tag:   "profile",
+				Attrs: waBinary.Attrs{"jid": jid},
+			}},
+		}},
+	})
+	if err != nil {
+		return db.SetBusinessProfileParams{}, err
+	}
+	node, ok := resp.GetOptionalChildByTag("business_profile")
+	if !ok {
+		return db.SetBusinessProfileParams{}, errors.New("business profile missing in response")
+	}
+	profile, err := client.DangerousInternals().ParseBusinessProfile(&node)
+	if err != nil {
+		return db.SetBusinessProfileParams{}, err
+	}
+
+	params := db.SetBusinessProfileParams{
+		DeviceID:         clientID,
+		Jid:              jid.String(),
+		IsBusiness:       true,
+		BusinessAddress:  pgtype.Text{String: profile.Address, Valid: profile.Address != ""},
+		BusinessEmail:    pgtype.Text{String: profile.Email, Valid: profile.Email != ""},
+		BusinessTimezone: pgtype.Text{String: profile.BusinessHoursTimeZone, Valid: profile.BusinessHoursTimeZone != ""},
+	}
+	if len(profile.Categories) > 0 {
+		params.BusinessCategory = pgtype.Text{String: profile.Categories[0].Name, Valid: true}
+	}
+	profileNode := node.GetChildByTag("profile")
+	if description, ok := profileNode.GetChildByTag("description").Content.([]byte); ok && len(description) > 0 {
+		params.BusinessDescription = pgtype.Text{String: string(description), Valid: true}
+	}
+	for _, child := range profileNode.GetChildren() {
+		if website, ok := child.Content.([]byte); ok && child.Tag == "website" && len(website) > 0 {
+			params.BusinessWebsites = append(params.BusinessWebsites, string(website))
+		}
+	}
+	if len(profile.BusinessHours) > 0 {
+		hours := make([]ContactBusinessHours, 0, len(profile.BusinessHours))
+		for _, h := range profile.BusinessHours {
+			hours = append(hours, ContactBusinessHours{Day: h.DayOfWeek, Mode: h.Mode, OpenTime: h.OpenTime, CloseTime: h.CloseTime})
+		}
+		params.BusinessHours, _ = json.Marshal(hours)
+	}
+	return params, nil
+}
+
+// refreshPicture caches the current profile picture of a user or group. existingID is the
+// ID of the cached picture; WhatsApp answers without a picture when it is still current.
+func (w *WhatsappClient) refreshPicture(ctx context.Context, client *whatsmeow.Client, clientID string, jid types.JID, existingID string) error {
+	info, err := client.GetProfilePictureInfo(ctx, jid, &whatsmeow.GetProfilePictureParams{ExistingID: existingID})
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) {
+		if existingID == "" {
+			return nil
+		}
+		return w.db.SetProfilePicture(ctx, db.SetProfilePictureParams{
+			DeviceID:         clientID,
+			Jid:              jid.String(),
+			PictureUpdatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get profile picture: %w", err)
+	}
+	if info == nil {
+		return nil
+	}
+	return w.storePicture(ctx, clientID, jid, info, time.Now())
+}
+
+// storePicture downloads a profile picture into the media storage.
+func (w *WhatsappClient) storePicture(ctx context.Context, clientID string, jid types.JID, info *types.ProfilePictureInfo, changedAt time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, info.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := profilePictureClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download profile picture: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download profile picture: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download profile picture: %w", err)
+	}
+
+	pictureURL, err := w.storeMedia(ctx, clientID, "picture.jpg", "image/jpeg", data)
+	if err != nil {
+		return fmt.Errorf("failed to store profile picture: %w", err)
+	}
+	return w.db.SetProfilePicture(ctx, db.SetProfilePictureParams{
+		DeviceID:         clientID,
+		Jid:              jid.String(),
+		PictureID:        pgtype.Text{String: info.ID, Valid: true},
+		PictureUrl:       pgtype.Text{String: pictureURL, Valid: true},
+		PictureUpdatedAt: pgtype.Timestamptz{Time: changedAt, Valid: true},
+	})
+}
+
+func (w *EventHandler) handlePicture(evt *events.Picture) {
+	ctx := context.Background()
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	if evt.Remove {
+		w.removePicture(ctx, evt.JID, at)
+		return
+	}
+
+	client := w.client.RetrieveDevice(w.clientID)
+	if client == nil {
+		return
+	}
+	// Downloading the picture would hold up the events behind this one
+	go func() {
+		info, err := client.GetProfilePictureInfo(ctx, evt.JID, nil)
+		if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) {
+			w.removePicture(ctx, evt.JID, at)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get new picture of %s: %v", evt.JID, err)
+			return
+		}
+		if info == nil {
+			return
+		}
+		if err := w.client.storePicture(ctx, w.clientID, evt.JID, info, at); err != nil {
+			logger.Error("Failed to store new picture of %s: %v", evt.JID, err)
+		}
+	}()
+}
+
+// removePicture forgets the cached picture of a user or group that removed it or hid it from us.
+func (w *EventHandler) removePicture(ctx context.Context, jid types.JID, at time.Time) {
+	if err := w.db.SetProfilePicture(ctx, db.SetProfilePictureParams{
+		DeviceID:         w.clientID,
+		Jid:              jid.String(),
+		PictureUpdatedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	}); err != nil {
+		logger.Error("Failed to remove picture of %s: %v", jid, err)
+	}
+}
+
+func (w *EventHandler) handleUserAbout(evt *events.UserAbout) {
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if err := w.db.SetProfileAbout(context.Background(), db.SetProfileAboutParams{
+		DeviceID:       w.clientID,
+		Jid:            evt.JID.String(),
+		About:          pgtype.Text{String: evt.Status, Valid: evt.Status != ""},
+		AboutUpdatedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	}); err != nil {
+		logger.Error("Failed to store about text of %s: %v", evt.JID, err)
+	}
+}
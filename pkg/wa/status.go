@@ -0,0 +1,230 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// TextStatus is a text status update. Colors are ARGB; a zero TextColor means white.
+type TextStatus struct {
+	Text            string
+	BackgroundColor uint32
+	TextColor       uint32
+	Font            string
+}
+
+// StatusAudience is who a status update is sent to, as set by the status privacy setting
+// on the phone.
+type StatusAudience struct {
+	// Type is contacts, blacklist (contacts except List) or whitelist (only List)
+	Type       string   `json:"type"`
+	List       []string `json:"list"`
+	Recipients []string `json:"recipients"`
+}
+
+// IsValidStatusFont reports whether font names a text status font, such as system_bold or
+// courierprime_bold.
+func IsValidStatusFont(font string) bool {
+	_, ok := waE2E.ExtendedTextMessage_FontType_value[strings.ToUpper(font)]
+	return ok
+}
+
+// PostTextStatus posts a text status update on a colored background.
+func (w *WhatsappClient) PostTextStatus(clientID string, status TextStatus) (string, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", fmt.Errorf("client %s not found", clientID)
+	}
+
+	textColor := status.TextColor
+	if textColor == 0 {
+		textColor = 0xFFFFFFFF
+	}
+	msg := &waE2E.ExtendedTextMessage{
+		Text:           proto.String(status.Text),
+		BackgroundArgb: proto.Uint32(status.BackgroundColor),
+		TextArgb:       proto.Uint32(textColor),
+	}
+	if status.Font != "" {
+		font := waE2E.ExtendedTextMessage_FontType(waE2E.ExtendedTextMessage_FontType_value[strings.ToUpper(status.Font)])
+		msg.Font = &font
+	}
+
+	resp, err := client.SendMessage(context.Background(), types.StatusBroadcastJID, &waE2E.Message{ExtendedTextMessage: msg})
+	if err != nil {
+		return "", fmt.Errorf("failed to post status: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// PostMediaStatus posts an image or video status update with an optional caption.
+func (w *WhatsappClient) PostMediaStatus(clientID string, data []byte, contentType, caption string) (string, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return "", fmt.Errorf("client %s not found", clientID)
+	}
+
+	var mediaType whatsmeow.MediaType
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		mediaType = whatsmeow.MediaImage
+	case strings.HasPrefix(contentType, "video/"):
+		mediaType = whatsmeow.MediaVideo
+	default:
+		return "", fmt.Errorf("unsupported status media type %s", contentType)
+	}
+
+	ctx := context.Background()
+	upload, err := client.Upload(ctx, data, mediaType)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	var msg waE2E.Message
+	if mediaType == whatsmeow.MediaImage {
+		msg.ImageMessage = &waE2E.ImageMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(contentType),
+			URL:           &upload.URL,
+			DirectPath:    &upload.DirectPath,
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    &upload.FileLength,
+		}
+	} else {
+		msg.VideoMessage = &waE2E.VideoMessage{
+			Caption:       proto.String(caption),
+			Mimetype:      proto.String(contentType),
+			URL:           &upload.URL,
+			DirectPath:    &upload.DirectPath,
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    &upload.FileLength,
+		}
+	}
+
+	resp, err := client.SendMessage(ctx, types.StatusBroadcastJID, &msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to post status: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// GetStatusAudience returns who status updates of the device are sent to. WhatsApp sends a
+// status to the audience of the status privacy setting; it cannot be chosen per update.
+func (w *WhatsappClient) GetStatusAudience(clientID string) (StatusAudience, error) {
+	client, ok := w.runningClients[clientID]
+	if !ok {
+		return StatusAudience{}, fmt.Errorf("client %s not found", clientID)
+	}
+
+	ctx := context.Background()
+	settings, err := client.GetStatusPrivacy(ctx)
+	if err != nil {
+		return StatusAudience{}, fmt.Errorf("failed to get status privacy: %w", err)
+	}
+	privacy := settings[0]
+
+	audience := StatusAudience{
+		Type:       string(privacy.Type),
+		List:       make([]string, 0, len(privacy.List)),
+		Recipients: []string{},
+	}
+	listed := make(map[types.JID]bool, len(privacy.List))
+	for _, jid := range privacy.List {
+		audience.List = append(audience.List, jid.String())
+		listed[jid] = true
+	}
+
+	if privacy.Type == types.StatusPrivacyTypeWhitelist {
+		audience.Recipients = audience.List
+		return audience, nil
+	}
+
+	// Everyone else is reached through the address book, the same way whatsmeow picks the
+	// recipients when sending.
+	contacts, err := client.Store.Contacts.GetAllContacts(ctx)
+	if err != nil {
+		return StatusAudience{}, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	for jid, contact := range contacts {
+		if contact.FullName == "" || (privacy.Type == types.StatusPrivacyTypeBlacklist && listed[jid]) {
+			continue
+		}
+		audience.Recipients = append(audience.Recipients, jid.String())
+	}
+	return audience, nil
+}
+
+// StatusRecipientsOutside returns the recipients a status update would reach that are not
+// among viewers, so an update meant for a restricted audience is not shared more widely.
+func (w *WhatsappClient) StatusRecipientsOutside(clientID string, viewers []types.JID) ([]string, error) {
+	audience, err := w.GetStatusAudience(clientID)
+	if err != nil {
+		return nil, err
+	}
+	client := w.runningClients[clientID]
+
+	allowed := make(map[string]bool, len(viewers))
+	for _, jid := range viewers {
+		allowed[jid.User] = true
+	}
+
+	var outside []string
+	for _, recipient := range audience.Recipients {
+		jid, err := types.ParseJID(recipient)
+		if err != nil {
+			continue
+		}
+		if jid.Server == types.HiddenUserServer {
+			if pn, err := client.Store.LIDs.GetPNForLID(context.Background(), jid); err == nil && !pn.IsEmpty() && allowed[pn.User] {
+				continue
+			}
+		}
+		if !allowed[jid.User] {
+			outside = append(outside, recipient)
+		}
+	}
+	return outside, nil
+}
+
+// recordStatusViews stores who viewed a status update of the device. Viewers that turned
+// off read receipts never send one, so the count is a lower bound.
+func (w *EventHandler) recordStatusViews(evt *events.Receipt) {
+	if evt.IsFromMe || (evt.Type != types.ReceiptTypeRead && evt.Type != types.ReceiptTypePlayed) {
+		return
+	}
+
+	ctx := context.Background()
+	viewer := evt.Sender.ToNonAD()
+	phone := w.phoneJID(ctx, viewer)
+	at := evt.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	for _, msgID := range evt.MessageIDs {
+		if err := w.db.RecordStatusView(ctx, db.RecordStatusViewParams{
+			ViewerJid:   viewer.String(),
+			PhoneJid:    phone,
+			ViewedAt:    pgtype.Timestamptz{Time: at, Valid: true},
+			DeviceID:    w.clientID,
+			WaMessageID: msgID,
+		}); err != nil {
+			logger.Error("Failed to record view of status %s: %v", msgID, err)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package wa
 
 import (
 	"context"
+	"time"
+
 	"github.com/fransfilastap/kontak/pkg/db"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/oklog/ulid/v2"
@@ -18,6 +20,7 @@ type Store interface {
 	GetClient(ctx context.Context, id string) (db.Client, error)
 	GetClientsByUserID(ctx context.Context, userID int32) ([]db.Client, error)
 	GetClientByIDAndUserID(ctx context.Context, clientID string, userID int32) (db.Client, error)
+	GetClientForMember(ctx context.Context, clientID string, userID int32) (db.Client, error)
 	CreateClient(ctx context.Context, name, mobileNumber string, userID int32) (db.Client, error)
 	DeleteClient(ctx context.Context, id string) error
 
@@ -36,7 +39,7 @@ type Store interface {
 
 	// Contact operations
 	SyncContacts(ctx context.Context, clientID string, contacts map[types.JID]types.ContactInfo) error
-	GetContacts(ctx context.Context, clientID string) ([]db.WhatsappContact, error)
+	GetContacts(ctx context.Context, clientID string) ([]db.GetDeviceContactsRow, error)
 }
 
 // PostgresStore implements the Store interface using PostgreSQL
@@ -81,6 +84,14 @@ func (s *PostgresStore) GetClientByIDAndUserID(ctx context.Context, clientID str
 	})
 }
 
+// GetClientForMember retrieves a client by ID if the user owns it or is one of its team members
+func (s *PostgresStore) GetClientForMember(ctx context.Context, clientID string, userID int32) (db.Client, error) {
+	return s.dbQueries.GetClientForMember(ctx, db.GetClientForMemberParams{
+		ID:     clientID,
+		UserID: pgtype.Int4{Int32: userID, Valid: true},
+	})
+}
+
 // CreateClient creates a new client in the database
 func (s *PostgresStore) CreateClient(ctx context.Context, name, mobileNumber string, userID int32) (db.Client, error) {
 	return s.dbQueries.CreateNewClient(ctx, db.CreateNewClientParams{
@@ -150,23 +161,7 @@ func (s *PostgresStore) SetClientJID(ctx context.Context, clientID, jid string)
 
 func (s *PostgresStore) SyncGroups(ctx context.Context, clientID string, groups []*types.GroupInfo) error {
 	for _, group := range groups {
-		err := s.dbQueries.UpsertWhatsAppGroup(ctx, db.UpsertWhatsAppGroupParams{
-			DeviceID: pgtype.Text{
-				String: clientID,
-				Valid:  true,
-			},
-			GroupID:   group.JID.String(),
-			GroupName: group.Name,
-			GroupDescription: pgtype.Text{
-				String: group.Name,
-				Valid:  true,
-			},
-			ParticipantCount: pgtype.Int4{
-				Int32: int32(len(group.Participants)),
-				Valid: true,
-			},
-		})
-		if err != nil {
+		if err := upsertGroup(ctx, s.dbQueries, clientID, group); err != nil {
 			return err
 		}
 	}
@@ -174,6 +169,66 @@ func (s *PostgresStore) SyncGroups(ctx context.Context, clientID string, groups
 	return nil
 }
 
+// upsertGroup stores the current state of a joined group and its participants.
+func upsertGroup(ctx context.Context, q db.Querier, clientID string, group *types.GroupInfo) error {
+	err := q.UpsertWhatsAppGroup(ctx, db.UpsertWhatsAppGroupParams{
+		DeviceID: pgtype.Text{
+			String: clientID,
+			Valid:  true,
+		},
+		GroupID:   group.JID.String(),
+		GroupName: group.Name,
+		GroupDescription: pgtype.Text{
+			String: group.Topic,
+			Valid:  group.Topic != "",
+		},
+		ParticipantCount: pgtype.Int4{
+			Int32: int32(len(group.Participants)),
+			Valid: true,
+		},
+		IsAnnounce:  group.IsAnnounce,
+		IsLocked:    group.IsLocked,
+		UpdatedAt:   pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		IsCommunity: group.IsParent,
+		ParentGroupID: pgtype.Text{
+			String: group.LinkedParentJID.String(),
+			Valid:  !group.LinkedParentJID.IsEmpty(),
+		},
+		IsAnnouncementGroup: group.IsDefaultSubGroup,
+	})
+	if err != nil {
+		return err
+	}
+
+	jids := make([]string, 0, len(group.Participants))
+	for _, p := range group.Participants {
+		// Participants that could not be added to a new group carry an error code
+		if p.Error != 0 {
+			continue
+		}
+		var phone pgtype.Text
+		if !p.PhoneNumber.IsEmpty() && p.PhoneNumber != p.JID {
+			phone = pgtype.Text{String: p.PhoneNumber.String(), Valid: true}
+		}
+		if err := q.UpsertGroupParticipant(ctx, db.UpsertGroupParticipantParams{
+			DeviceID:       clientID,
+			GroupJid:       group.JID.String(),
+			ParticipantJid: p.JID.String(),
+			PhoneJid:       phone,
+			Role:           participantRole(p),
+		}); err != nil {
+			return err
+		}
+		jids = append(jids, p.JID.String())
+	}
+
+	return q.DeleteStaleGroupParticipants(ctx, db.DeleteStaleGroupParticipantsParams{
+		DeviceID:        clientID,
+		GroupJid:        group.JID.String(),
+		ParticipantJids: jids,
+	})
+}
+
 func (s *PostgresStore) GetJoinedGroups(ctx context.Context, clientID string) ([]db.WhatsappGroup, error) {
 	groups, err := s.dbQueries.GetDeviceGroups(ctx, pgtype.Text{
 		String: clientID,
@@ -216,7 +271,7 @@ func (s *PostgresStore) SyncContacts(ctx context.Context, clientID string, conta
 	return nil
 }
 
-func (s *PostgresStore) GetContacts(ctx context.Context, clientID string) ([]db.WhatsappContact, error) {
+func (s *PostgresStore) GetContacts(ctx context.Context, clientID string) ([]db.GetDeviceContactsRow, error) {
 	return s.dbQueries.GetDeviceContacts(ctx, pgtype.Text{
 		String: clientID,
 		Valid:  true,
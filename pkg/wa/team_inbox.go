@@ -0,0 +1,71 @@
+package wa
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fransfilastap/kontak/pkg/db"
+	"github.com/fransfilastap/kontak/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Thread statuses used by the shared inbox.
+const (
+	ThreadStatusOpen     = "open"
+	ThreadStatusPending  = "pending"
+	ThreadStatusResolved = "resolved"
+)
+
+// Actions recorded in the thread audit trail.
+const (
+	ThreadActionAssigned      = "assigned"
+	ThreadActionStatusChanged = "status_changed"
+	ThreadActionNoteAdded     = "note_added"
+	ThreadActionNoteDeleted   = "note_deleted"
+)
+
+// IsValidThreadStatus reports whether status is one of the inbox thread statuses.
+func IsValidThreadStatus(status string) bool {
+	switch status {
+	case ThreadStatusOpen, ThreadStatusPending, ThreadStatusResolved:
+		return true
+	}
+	return false
+}
+
+// RecordThreadAudit appends an entry to a thread's audit trail. A zero userID records the
+// change as made by the system. Failures are logged rather than returned so that auditing
+// never blocks the change itself.
+func RecordThreadAudit(ctx context.Context, dbQueries db.Querier, deviceID, chatJID string, userID int32, action, oldValue, newValue string) {
+	err := dbQueries.CreateThreadAuditLog(ctx, db.CreateThreadAuditLogParams{
+		DeviceID: deviceID,
+		ChatJid:  chatJID,
+		UserID:   pgtype.Int4{Int32: userID, Valid: userID != 0},
+		Action:   action,
+		OldValue: pgtype.Text{String: oldValue, Valid: oldValue != ""},
+		NewValue: pgtype.Text{String: newValue, Valid: newValue != ""},
+	})
+	if err != nil {
+		logger.Error("Failed to record %s audit for chat %s on device %s: %v", action, chatJID, deviceID, err)
+	}
+}
+
+// reopenThread moves a pending or resolved thread back to open when the customer writes again.
+func (w *EventHandler) reopenThread(chatJID string) {
+	ctx := context.Background()
+
+	oldStatus, err := w.db.ReopenThread(ctx, db.ReopenThreadParams{
+		DeviceID: w.clientID,
+		ChatJid:  chatJID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to reopen thread %s: %v", chatJID, err)
+		return
+	}
+
+	RecordThreadAudit(ctx, w.db, w.clientID, chatJID, 0, ThreadActionStatusChanged, oldStatus, ThreadStatusOpen)
+}
@@ -0,0 +1,14 @@
+package wa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderTemplate replaces every {{key}} placeholder in content with its value from variables.
+func RenderTemplate(content string, variables map[string]interface{}) string {
+	for key, value := range variables {
+		content = strings.ReplaceAll(content, "{{"+key+"}}", fmt.Sprintf("%v", value))
+	}
+	return content
+}
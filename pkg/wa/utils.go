@@ -46,13 +46,7 @@ func getJID(recipient string) (types.JID, error) {
 		}
 		return jid, nil
 	}
-	formatJID := fmt.Sprintf("%s@s.whatsapp.net", recipient)
-	jid, err := types.ParseJID(formatJID)
-	if err != nil {
-		return types.JID{}, fmt.Errorf("failed to parse jid: %v", err)
-	}
-
-	return jid, nil
+	return PhoneJID(recipient)
 }
 
 func UUID2String(pgUUID pgtype.UUID) string {
@@ -0,0 +1,57 @@
+-- name: CreateAutoReplyRule :one
+INSERT INTO auto_reply_rules (device_id, name, priority, is_active, match_type, match_value, case_sensitive,
+                              message_types, chat_scope, sender_jids, group_jids, hours_condition, business_hours,
+                              reply_type, reply_text, template_id, media_url, media_filename, cooldown_seconds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+RETURNING *;
+
+-- name: UpdateAutoReplyRule :one
+UPDATE auto_reply_rules
+SET name             = $3,
+    priority         = $4,
+    is_active        = $5,
+    match_type       = $6,
+    match_value      = $7,
+    case_sensitive   = $8,
+    message_types    = $9,
+    chat_scope       = $10,
+    sender_jids      = $11,
+    group_jids       = $12,
+    hours_condition  = $13,
+    business_hours   = $14,
+    reply_type       = $15,
+    reply_text       = $16,
+    template_id      = $17,
+    media_url        = $18,
+    media_filename   = $19,
+    cooldown_seconds = $20
+WHERE id = $1 AND device_id = $2
+RETURNING *;
+
+-- name: DeleteAutoReplyRule :exec
+DELETE FROM auto_reply_rules
+WHERE id = $1 AND device_id = $2;
+
+-- name: GetAutoReplyRule :one
+SELECT *
+FROM auto_reply_rules
+WHERE id = $1 AND device_id = $2;
+
+-- name: GetAutoReplyRules :many
+SELECT *
+FROM auto_reply_rules
+WHERE device_id = $1
+ORDER BY priority DESC, created_at ASC;
+
+-- name: GetActiveAutoReplyRules :many
+SELECT *
+FROM auto_reply_rules
+WHERE device_id = $1 AND is_active = TRUE
+ORDER BY priority DESC, created_at ASC;
+
+-- name: ClaimAutoReplyCooldown :one
+INSERT INTO auto_reply_cooldowns (rule_id, chat_jid, last_replied_at)
+VALUES (@rule_id, @chat_jid, NOW())
+ON CONFLICT (rule_id, chat_jid) DO UPDATE SET last_replied_at = NOW()
+WHERE auto_reply_cooldowns.last_replied_at <= NOW() - (@cooldown_seconds::int * INTERVAL '1 second')
+RETURNING rule_id;
@@ -0,0 +1,23 @@
+-- name: GetAwayMessageSettings :one
+SELECT *
+FROM away_message_settings
+WHERE device_id = $1;
+
+-- name: UpsertAwayMessageSettings :one
+INSERT INTO away_message_settings (device_id, is_enabled, message, business_hours, resend_interval_minutes, include_groups)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (device_id) DO UPDATE SET
+    is_enabled = EXCLUDED.is_enabled,
+    message = EXCLUDED.message,
+    business_hours = EXCLUDED.business_hours,
+    resend_interval_minutes = EXCLUDED.resend_interval_minutes,
+    include_groups = EXCLUDED.include_groups
+RETURNING *;
+
+-- name: ClaimAwayMessage :one
+UPDATE message_threads
+SET away_sent_at = NOW()
+WHERE device_id = @device_id
+  AND chat_jid = @chat_jid
+  AND (away_sent_at IS NULL OR away_sent_at <= NOW() - (@resend_interval_minutes::int * INTERVAL '1 minute'))
+RETURNING id;
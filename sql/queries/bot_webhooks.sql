@@ -0,0 +1,20 @@
+-- name: GetBotWebhook :one
+SELECT *
+FROM bot_webhooks
+WHERE device_id = $1;
+
+-- name: UpsertBotWebhook :one
+INSERT INTO bot_webhooks (device_id, is_enabled, url, secret, timeout_seconds, fallback_message, include_groups)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (device_id) DO UPDATE SET
+    is_enabled = EXCLUDED.is_enabled,
+    url = EXCLUDED.url,
+    secret = EXCLUDED.secret,
+    timeout_seconds = EXCLUDED.timeout_seconds,
+    fallback_message = EXCLUDED.fallback_message,
+    include_groups = EXCLUDED.include_groups
+RETURNING *;
+
+-- name: DeleteBotWebhook :exec
+DELETE FROM bot_webhooks
+WHERE device_id = $1;
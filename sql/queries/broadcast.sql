@@ -9,7 +9,8 @@ INSERT INTO broadcast_jobs (
         media_filename,
         cooldown,
         is_scheduled,
-        scheduled_at
+        scheduled_at,
+        is_transactional
     )
 VALUES (
         @user_id,
@@ -21,7 +22,8 @@ VALUES (
         @media_filename,
         @cooldown,
         COALESCE(@is_scheduled::boolean, FALSE),
-        @scheduled_at::timestamptz
+        @scheduled_at::timestamptz,
+        COALESCE(@is_transactional::boolean, FALSE)
     )
 RETURNING *;
 -- name: GetBroadcastJobs :many
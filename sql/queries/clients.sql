@@ -58,4 +58,13 @@ WHERE user_id = $1 OR user_id IS NULL;
 SELECT *
 FROM clients
 WHERE id = $1 AND (user_id = $2 OR user_id IS NULL)
+LIMIT 1;
+
+-- name: GetClientForMember :one
+SELECT *
+FROM clients c
+WHERE c.id = @id
+  AND (c.user_id = @user_id
+       OR c.user_id IS NULL
+       OR EXISTS (SELECT 1 FROM device_members m WHERE m.device_id = c.id AND m.user_id = @user_id))
 LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,95 @@
+-- name: ListContactAttributes :many
+SELECT * FROM contact_attributes
+WHERE device_id = @device_id
+ORDER BY key;
+
+-- name: CreateContactAttribute :one
+INSERT INTO contact_attributes (device_id, key, label, type, options, required)
+VALUES (@device_id, @key, @label, @type, @options, @required)
+RETURNING *;
+
+-- name: UpdateContactAttribute :one
+UPDATE contact_attributes
+SET label = @label, options = @options, required = @required
+WHERE device_id = @device_id AND key = @key
+RETURNING *;
+
+-- name: DeleteContactAttribute :execrows
+DELETE FROM contact_attributes
+WHERE device_id = @device_id AND key = @key;
+
+-- name: RemoveContactAttributeValues :exec
+UPDATE contact_details
+SET attributes = attributes - @key::text
+WHERE device_id = @device_id AND attributes ? @key::text;
+
+-- name: EnsureContact :exec
+INSERT INTO whatsapp_contacts (device_id, jid, phone_number)
+VALUES (@device_id, @jid, @phone_number)
+ON CONFLICT (device_id, jid) DO NOTHING;
+
+-- name: UpsertContactDetails :one
+INSERT INTO contact_details (device_id, jid, email, external_id, notes, tags, attributes)
+VALUES (@device_id, @jid, @email, @external_id, @notes, @tags, @attributes)
+ON CONFLICT (device_id, jid) DO UPDATE SET
+    email = EXCLUDED.email,
+    external_id = EXCLUDED.external_id,
+    notes = EXCLUDED.notes,
+    tags = EXCLUDED.tags,
+    attributes = EXCLUDED.attributes
+RETURNING *;
+
+-- name: DeleteContactDetails :execrows
+DELETE FROM contact_details
+WHERE device_id = @device_id AND jid = @jid;
+
+-- name: GetContactWithDetails :one
+SELECT wc.jid,
+       wc.full_name,
+       wc.push_name,
+       wc.business_name,
+       wc.phone_number,
+       cd.email,
+       cd.external_id,
+       cd.notes,
+       COALESCE(cd.tags, '{}')::text[] AS tags,
+       COALESCE(cd.attributes, '{}')::jsonb AS attributes
+FROM whatsapp_contacts wc
+LEFT JOIN contact_details cd ON cd.device_id = wc.device_id AND cd.jid = wc.jid
+WHERE wc.device_id = @device_id AND wc.jid = @jid;
+
+-- name: SearchContacts :many
+SELECT wc.jid,
+       wc.full_name,
+       wc.push_name,
+       wc.business_name,
+       wc.phone_number,
+       cd.email,
+       cd.external_id,
+       cd.notes,
+       COALESCE(cd.tags, '{}')::text[] AS tags,
+       COALESCE(cd.attributes, '{}')::jsonb AS attributes
+FROM whatsapp_contacts wc
+LEFT JOIN contact_details cd ON cd.device_id = wc.device_id AND cd.jid = wc.jid
+WHERE wc.device_id = @device_id
+  AND (sqlc.narg('pattern')::text IS NULL
+       OR wc.full_name ILIKE sqlc.narg('pattern')
+       OR wc.push_name ILIKE sqlc.narg('pattern')
+       OR wc.business_name ILIKE sqlc.narg('pattern')
+       OR wc.jid ILIKE sqlc.narg('pattern')
+       OR cd.email ILIKE sqlc.narg('pattern')
+       OR cd.external_id ILIKE sqlc.narg('pattern')
+       OR cd.notes ILIKE sqlc.narg('pattern'))
+  AND (sqlc.narg('tags')::text[] IS NULL OR cd.tags @> sqlc.narg('tags')::text[])
+  AND (sqlc.narg('attributes')::jsonb IS NULL OR cd.attributes @> sqlc.narg('attributes')::jsonb)
+  AND (sqlc.narg('email')::varchar IS NULL OR lower(cd.email) = lower(sqlc.narg('email')))
+  AND (sqlc.narg('external_id')::varchar IS NULL OR cd.external_id = sqlc.narg('external_id'))
+ORDER BY COALESCE(wc.full_name, wc.push_name, wc.jid) ASC
+LIMIT @query_limit OFFSET @query_offset;
+
+-- name: ListContactTags :many
+SELECT tag::text AS tag, COUNT(*) AS contacts
+FROM contact_details, unnest(tags) AS tag
+WHERE device_id = @device_id
+GROUP BY tag
+ORDER BY tag;
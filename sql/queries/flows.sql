@@ -0,0 +1,109 @@
+-- name: CreateFlow :one
+INSERT INTO flows (device_id, name, trigger_type, trigger_keywords, is_active, webhook_url, timeout_seconds, webhook_secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: UpdateFlow :one
+UPDATE flows
+SET name             = $3,
+    trigger_type     = $4,
+    trigger_keywords = $5,
+    is_active        = $6,
+    webhook_url      = $7,
+    timeout_seconds  = $8,
+    webhook_secret   = $9
+WHERE id = $1 AND device_id = $2
+RETURNING *;
+
+-- name: SetFlowCurrentVersion :exec
+UPDATE flows
+SET current_version = $2
+WHERE id = $1;
+
+-- name: DeleteFlow :exec
+DELETE FROM flows
+WHERE id = $1 AND device_id = $2;
+
+-- name: GetFlow :one
+SELECT *
+FROM flows
+WHERE id = $1 AND device_id = $2;
+
+-- name: GetFlowByID :one
+SELECT *
+FROM flows
+WHERE id = $1;
+
+-- name: GetFlows :many
+SELECT *
+FROM flows
+WHERE device_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetActiveFlows :many
+SELECT *
+FROM flows
+WHERE device_id = $1 AND is_active = TRUE AND current_version > 0
+ORDER BY created_at ASC;
+
+-- name: CreateFlowVersion :one
+INSERT INTO flow_versions (flow_id, version, definition)
+VALUES (
+    @flow_id,
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM flow_versions WHERE flow_id = @flow_id),
+    @definition
+)
+RETURNING *;
+
+-- name: GetFlowVersion :one
+SELECT *
+FROM flow_versions
+WHERE flow_id = $1 AND version = $2;
+
+-- name: GetFlowVersions :many
+SELECT *
+FROM flow_versions
+WHERE flow_id = $1
+ORDER BY version DESC;
+
+-- name: CreateFlowSession :one
+INSERT INTO flow_sessions (flow_id, flow_version, device_id, chat_jid, current_step)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetOpenFlowSession :one
+SELECT *
+FROM flow_sessions
+WHERE device_id = $1 AND chat_jid = $2 AND status IN ('active', 'handoff');
+
+-- name: UpdateFlowSession :exec
+UPDATE flow_sessions
+SET current_step     = $2,
+    answers          = $3,
+    status           = $4,
+    last_activity_at = NOW()
+WHERE id = $1;
+
+-- name: CloseFlowSession :execrows
+UPDATE flow_sessions
+SET status = 'closed'
+WHERE device_id = $1 AND chat_jid = $2 AND status IN ('active', 'handoff');
+
+-- name: ExpireFlowSession :execrows
+UPDATE flow_sessions
+SET status = 'expired'
+WHERE id = $1 AND status = 'active';
+
+-- name: GetExpiredFlowSessions :many
+SELECT flow_sessions.*
+FROM flow_sessions
+JOIN flows ON flows.id = flow_sessions.flow_id
+WHERE flow_sessions.status = 'active'
+  AND flows.timeout_seconds > 0
+  AND flow_sessions.last_activity_at <= NOW() - (flows.timeout_seconds * INTERVAL '1 second');
+
+-- name: CloseStaleHandoffSessions :execrows
+UPDATE flow_sessions
+SET status = 'closed'
+WHERE status = 'handoff'
+  AND last_activity_at <= NOW() - (@max_age_seconds::int * INTERVAL '1 second');
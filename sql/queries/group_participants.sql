@@ -0,0 +1,51 @@
+-- name: UpsertGroupParticipant :exec
+INSERT INTO group_participants (device_id, group_jid, participant_jid, phone_jid, role, joined_at)
+VALUES (@device_id, @group_jid, @participant_jid, @phone_jid, @role, @joined_at)
+ON CONFLICT (device_id, group_jid, participant_jid) DO UPDATE SET
+    phone_jid = COALESCE(EXCLUDED.phone_jid, group_participants.phone_jid),
+    role = EXCLUDED.role,
+    joined_at = COALESCE(group_participants.joined_at, EXCLUDED.joined_at);
+
+-- name: SetGroupParticipantRole :exec
+UPDATE group_participants
+SET role = @role
+WHERE device_id = @device_id AND group_jid = @group_jid AND participant_jid = @participant_jid;
+
+-- name: DeleteGroupParticipant :exec
+DELETE FROM group_participants
+WHERE device_id = @device_id AND group_jid = @group_jid AND participant_jid = @participant_jid;
+
+-- name: DeleteStaleGroupParticipants :exec
+DELETE FROM group_participants
+WHERE device_id = @device_id AND group_jid = @group_jid
+  AND NOT participant_jid = ANY(@participant_jids::text[]);
+
+-- name: DeleteGroupParticipants :exec
+DELETE FROM group_participants
+WHERE device_id = @device_id AND group_jid = @group_jid;
+
+-- name: ListGroupParticipants :many
+SELECT
+    gp.participant_jid, gp.phone_jid, gp.role, gp.joined_at,
+    COALESCE(wc.full_name, wc.push_name, '') AS name
+FROM group_participants gp
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = gp.device_id AND wc.jid = COALESCE(gp.phone_jid, gp.participant_jid)
+WHERE gp.device_id = @device_id AND gp.group_jid = @group_jid
+ORDER BY gp.role = 'member', name ASC, gp.participant_jid ASC;
+
+-- name: CreateGroupEvent :exec
+INSERT INTO group_events (device_id, group_jid, action, participant_jid, phone_jid, actor_jid, value, occurred_at)
+VALUES (@device_id, @group_jid, @action, @participant_jid, @phone_jid, @actor_jid, @value, @occurred_at);
+
+-- name: ListGroupEvents :many
+SELECT
+    ge.id, ge.action, ge.participant_jid, ge.phone_jid, ge.actor_jid, ge.value, ge.occurred_at,
+    COALESCE(wc.full_name, wc.push_name, '') AS participant_name
+FROM group_events ge
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = ge.device_id AND wc.jid = COALESCE(ge.phone_jid, ge.participant_jid)
+WHERE ge.device_id = @device_id AND ge.group_jid = @group_jid
+  AND (sqlc.narg('action')::varchar IS NULL OR ge.action = sqlc.narg('action'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR ge.occurred_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR ge.occurred_at < sqlc.narg('until'))
+ORDER BY ge.occurred_at DESC, ge.id DESC
+LIMIT @query_limit OFFSET @query_offset;
@@ -0,0 +1,79 @@
+-- name: CountChatMessages :one
+SELECT COUNT(*)
+FROM message_logs
+WHERE device_id = $1 AND recipient = $2;
+
+-- name: GetHistorySyncProgress :one
+SELECT *
+FROM history_sync_progress
+WHERE device_id = $1;
+
+-- name: GetHistorySyncSettings :one
+SELECT *
+FROM history_sync_settings
+WHERE device_id = $1;
+
+-- name: ImportHistoryMessage :one
+INSERT INTO message_logs (device_id, recipient, recipient_type, message_type, content, media_filename, status, direction, sender_jid, wa_message_id, sent_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (wa_message_id) WHERE wa_message_id IS NOT NULL DO NOTHING
+RETURNING id;
+
+-- name: UpdateHistorySyncProgress :one
+INSERT INTO history_sync_progress (device_id, sync_type, chunk_order, progress, conversations, messages, completed_at)
+VALUES (@device_id, @sync_type, @chunk_order, @progress, @conversations, @messages,
+    CASE WHEN @progress::int >= 100 THEN NOW() END
+)
+ON CONFLICT (device_id) DO UPDATE SET
+    sync_type = EXCLUDED.sync_type,
+    chunk_order = EXCLUDED.chunk_order,
+    progress = EXCLUDED.progress,
+    conversations = CASE
+        WHEN history_sync_progress.completed_at IS NULL
+        THEN history_sync_progress.conversations + EXCLUDED.conversations
+        ELSE EXCLUDED.conversations
+    END,
+    messages = CASE
+        WHEN history_sync_progress.completed_at IS NULL
+        THEN history_sync_progress.messages + EXCLUDED.messages
+        ELSE EXCLUDED.messages
+    END,
+    started_at = CASE
+        WHEN history_sync_progress.completed_at IS NULL
+        THEN history_sync_progress.started_at
+        ELSE NOW()
+    END,
+    updated_at = NOW(),
+    completed_at = EXCLUDED.completed_at
+RETURNING *;
+
+-- name: UpsertHistorySyncSettings :one
+INSERT INTO history_sync_settings (device_id, is_enabled, max_days, max_messages_per_chat)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (device_id) DO UPDATE SET
+    is_enabled = EXCLUDED.is_enabled,
+    max_days = EXCLUDED.max_days,
+    max_messages_per_chat = EXCLUDED.max_messages_per_chat
+RETURNING *;
+
+-- name: UpsertHistoryThread :exec
+INSERT INTO message_threads (device_id, chat_jid, chat_type, last_message_at, last_message_content, last_message_type, last_message_direction, unread_count, is_pinned, is_archived)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (device_id, chat_jid) DO UPDATE SET
+    last_message_at = GREATEST(message_threads.last_message_at, EXCLUDED.last_message_at),
+    last_message_content = CASE
+        WHEN EXCLUDED.last_message_at > message_threads.last_message_at
+        THEN EXCLUDED.last_message_content
+        ELSE message_threads.last_message_content
+    END,
+    last_message_type = CASE
+        WHEN EXCLUDED.last_message_at > message_threads.last_message_at
+        THEN EXCLUDED.last_message_type
+        ELSE message_threads.last_message_type
+    END,
+    last_message_direction = CASE
+        WHEN EXCLUDED.last_message_at > message_threads.last_message_at
+        THEN EXCLUDED.last_message_direction
+        ELSE message_threads.last_message_direction
+    END,
+    updated_at = NOW();
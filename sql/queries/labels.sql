@@ -0,0 +1,60 @@
+-- name: GetLabels :many
+SELECT *
+FROM labels
+WHERE device_id = $1
+ORDER BY name ASC;
+
+-- name: GetLabel :one
+SELECT *
+FROM labels
+WHERE id = $1 AND device_id = $2;
+
+-- name: GetLabelByWaID :one
+SELECT *
+FROM labels
+WHERE device_id = $1 AND wa_label_id = $2;
+
+-- name: CreateLabel :one
+INSERT INTO labels (device_id, wa_label_id, name, color)
+SELECT @device_id, (COALESCE(MAX(CASE WHEN wa_label_id ~ '^[0-9]+$' THEN wa_label_id::int END), 0) + 1)::text, @name, @color
+FROM labels
+WHERE device_id = @device_id
+RETURNING *;
+
+-- name: UpsertLabelByWaID :one
+INSERT INTO labels (device_id, wa_label_id, name, color)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (device_id, wa_label_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    color = EXCLUDED.color
+RETURNING *;
+
+-- name: UpdateLabel :one
+UPDATE labels
+SET name = $3, color = $4
+WHERE id = $1 AND device_id = $2
+RETURNING *;
+
+-- name: DeleteLabel :exec
+DELETE FROM labels
+WHERE id = $1 AND device_id = $2;
+
+-- name: DeleteLabelByWaID :exec
+DELETE FROM labels
+WHERE device_id = $1 AND wa_label_id = $2;
+
+-- name: GetThreadLabels :many
+SELECT l.*
+FROM labels l
+JOIN thread_labels tl ON tl.label_id = l.id
+WHERE tl.device_id = $1 AND tl.chat_jid = $2
+ORDER BY l.name ASC;
+
+-- name: AddThreadLabel :exec
+INSERT INTO thread_labels (device_id, chat_jid, label_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (device_id, chat_jid, label_id) DO NOTHING;
+
+-- name: RemoveThreadLabel :exec
+DELETE FROM thread_labels
+WHERE device_id = $1 AND chat_jid = $2 AND label_id = $3;
@@ -0,0 +1,76 @@
+-- name: ClaimMediaDownloads :many
+UPDATE message_media
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + INTERVAL '5 minutes'
+WHERE message_id IN (
+    SELECT message_id
+    FROM message_media
+    WHERE status IN ('pending', 'retry_requested') AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING message_id;
+
+-- name: CompleteMediaDownload :exec
+WITH downloaded AS (
+    UPDATE message_media
+    SET status = 'downloaded', next_attempt_at = NULL, last_error = NULL
+    WHERE message_id = @message_id
+    RETURNING message_id
+)
+UPDATE message_logs
+SET media_url = @media_url
+WHERE id IN (SELECT message_id FROM downloaded);
+
+-- name: CreateMessageMedia :exec
+INSERT INTO message_media (message_id, media_type, mimetype, direct_path, media_key, file_enc_sha256, file_sha256, file_length, status, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $9::text = 'pending' THEN NOW() END)
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: FailMediaDownload :exec
+UPDATE message_media
+SET status = CASE WHEN sqlc.narg('retry_at')::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+    next_attempt_at = sqlc.narg('retry_at'),
+    last_error = @last_error
+WHERE message_id = @message_id;
+
+-- name: GetMediaDownloadSettings :one
+SELECT *
+FROM media_download_settings
+WHERE device_id = $1;
+
+-- name: GetMessageMedia :one
+SELECT mm.message_id, mm.media_type, mm.mimetype, mm.direct_path, mm.media_key, mm.file_enc_sha256, mm.file_sha256, mm.file_length,
+       mm.status, mm.attempts, ml.device_id, ml.recipient, ml.recipient_type, ml.direction, ml.sender_jid, ml.wa_message_id,
+       ml.media_url, ml.media_filename
+FROM message_media mm
+JOIN message_logs ml ON ml.id = mm.message_id
+WHERE mm.message_id = $1;
+
+-- name: GetMessageMediaByWaID :one
+SELECT mm.message_id, mm.media_key
+FROM message_media mm
+JOIN message_logs ml ON ml.id = mm.message_id
+WHERE ml.device_id = $1 AND ml.wa_message_id = $2;
+
+-- name: MarkMediaRetryRequested :exec
+UPDATE message_media
+SET status = 'retry_requested',
+    next_attempt_at = NOW() + INTERVAL '1 hour',
+    last_error = $2
+WHERE message_id = $1;
+
+-- name: UpdateMediaDirectPath :exec
+UPDATE message_media
+SET direct_path = $2,
+    status = 'pending',
+    next_attempt_at = NOW()
+WHERE message_id = $1;
+
+-- name: UpsertMediaDownloadSettings :one
+INSERT INTO media_download_settings (device_id, auto_download_types)
+VALUES ($1, $2)
+ON CONFLICT (device_id) DO UPDATE SET
+    auto_download_types = EXCLUDED.auto_download_types
+RETURNING *;
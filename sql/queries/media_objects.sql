@@ -0,0 +1,9 @@
+-- name: CreateMediaObject :exec
+INSERT INTO media_objects (device_id, storage_key, sha256, size_bytes, mimetype)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (device_id, storage_key) DO NOTHING;
+
+-- name: GetMediaObjectDevices :many
+SELECT device_id
+FROM media_objects
+WHERE storage_key = $1;
@@ -0,0 +1,122 @@
+-- name: CreateMediaCleanupRun :exec
+INSERT INTO media_cleanup_runs (device_id, reason, messages_cleared, objects_deleted, bytes_freed)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: DeleteUnusedMediaObject :one
+DELETE FROM media_objects o
+WHERE o.device_id = @device_id AND o.storage_key = @storage_key
+  AND NOT EXISTS (
+    SELECT 1 FROM message_logs m
+    WHERE m.device_id = o.device_id AND m.media_url = '/api/media/' || o.storage_key
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM profiles p
+    WHERE p.device_id = o.device_id AND p.picture_url = '/api/media/' || o.storage_key
+  )
+RETURNING o.size_bytes;
+
+-- name: ExpireMediaBefore :many
+WITH candidates AS (
+    SELECT id, media_url
+    FROM message_logs
+    WHERE device_id = @device_id AND message_type = @message_type
+      AND sent_at < @before::timestamptz AND media_url LIKE '/api/media/%'
+    ORDER BY sent_at
+    LIMIT @query_limit
+    FOR UPDATE
+),
+cleared AS (
+    UPDATE message_logs m
+    SET media_url = NULL, media_deleted_at = NOW()
+    FROM candidates c
+    WHERE m.id = c.id
+    RETURNING m.id
+),
+deleted_media AS (
+    UPDATE message_media
+    SET status = 'deleted', next_attempt_at = NULL
+    WHERE message_id IN (SELECT id FROM cleared)
+)
+SELECT substring(media_url FROM 12)::text AS storage_key
+FROM candidates;
+
+-- name: ExpireMediaObject :one
+WITH cleared AS (
+    UPDATE message_logs
+    SET media_url = NULL, media_deleted_at = NOW()
+    WHERE device_id = @device_id AND media_url = @media_url
+    RETURNING id
+),
+deleted_media AS (
+    UPDATE message_media
+    SET status = 'deleted', next_attempt_at = NULL
+    WHERE message_id IN (SELECT id FROM cleared)
+)
+SELECT COUNT(*)::bigint AS messages
+FROM cleared;
+
+-- name: GetDeviceMediaUsage :many
+SELECT (CASE
+        WHEN mimetype IS NULL THEN 'other'
+        WHEN mimetype LIKE 'image/%' THEN 'image'
+        WHEN mimetype LIKE 'video/%' THEN 'video'
+        WHEN mimetype LIKE 'audio/%' THEN 'audio'
+        ELSE 'document'
+    END)::text AS media_type,
+    COUNT(*)::bigint AS objects,
+    COALESCE(SUM(size_bytes), 0)::bigint AS size_bytes
+FROM media_objects
+WHERE device_id = $1
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetMediaRetentionSettings :one
+SELECT *
+FROM media_retention_settings
+WHERE device_id = $1;
+
+-- name: GetUserMediaUsage :one
+SELECT COALESCE(SUM(o.size_bytes), 0)::bigint AS size_bytes
+FROM media_objects o
+JOIN clients c ON c.id = o.device_id
+WHERE c.user_id = $1;
+
+-- name: ListDeviceMediaUsage :many
+SELECT o.device_id, c.user_id, COALESCE(SUM(o.size_bytes), 0)::bigint AS size_bytes
+FROM media_objects o
+JOIN clients c ON c.id = o.device_id
+GROUP BY o.device_id, c.user_id;
+
+-- name: ListMediaCleanupRuns :many
+SELECT *
+FROM media_cleanup_runs
+WHERE device_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: ListMediaRetentionSettings :many
+SELECT *
+FROM media_retention_settings;
+
+-- name: ListOldestMediaObjects :many
+SELECT o.device_id, o.storage_key, o.size_bytes
+FROM media_objects o
+WHERE o.device_id = ANY(@device_ids::text[])
+  -- Cached profile pictures are not messages; they are replaced when they change instead
+  AND NOT EXISTS (
+    SELECT 1 FROM profiles p
+    WHERE p.device_id = o.device_id AND p.picture_url = '/api/media/' || o.storage_key
+  )
+ORDER BY o.created_at, o.id
+LIMIT @query_limit;
+
+-- name: UpsertMediaRetentionSettings :one
+INSERT INTO media_retention_settings (device_id, image_retention_days, video_retention_days, document_retention_days, audio_retention_days, quota_bytes)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (device_id) DO UPDATE SET
+    image_retention_days = EXCLUDED.image_retention_days,
+    video_retention_days = EXCLUDED.video_retention_days,
+    document_retention_days = EXCLUDED.document_retention_days,
+    audio_retention_days = EXCLUDED.audio_retention_days,
+    quota_bytes = EXCLUDED.quota_bytes
+RETURNING *;
@@ -70,7 +70,10 @@ SELECT
     m.*,
     COALESCE(wc.full_name, wc.push_name, '') AS sender_name
 FROM message_logs m
-LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id AND wc.jid = m.sender_jid
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
 WHERE m.device_id = $1 AND m.recipient = $2
 ORDER BY m.sent_at ASC
 LIMIT $3 OFFSET $4;
@@ -80,5 +83,12 @@ UPDATE message_logs SET status = $2 WHERE wa_message_id = $1;
 
 -- name: MarkConversationRead :exec
 UPDATE message_logs
-SET status = 'read'
+SET status = 'read', read_at = NOW()
 WHERE device_id = $1 AND recipient = $2 AND direction = 'incoming' AND status != 'read';
+
+-- name: GetUnreadIncomingMessages :many
+SELECT wa_message_id, sender_jid
+FROM message_logs
+WHERE device_id = $1 AND recipient = $2 AND direction = 'incoming' AND status != 'read'
+  AND wa_message_id IS NOT NULL
+ORDER BY sent_at;
@@ -0,0 +1,71 @@
+-- name: GetMessageChanges :many
+SELECT
+    c.xid,
+    c.seq,
+    c.message_id,
+    c.chat_jid,
+    (CASE
+        WHEN c.deleted_at IS NOT NULL THEN 'deleted'
+        WHEN (c.created_xid, c.created_seq) > (@since_xid::bigint, @since_seq::bigint) THEN 'new'
+        ELSE 'updated'
+    END)::text AS change,
+    c.edited_at,
+    c.deleted_at,
+    m.wa_message_id,
+    m.sender_jid,
+    m.direction,
+    m.message_type,
+    m.content,
+    m.media_url,
+    m.media_filename,
+    m.status,
+    m.sent_at,
+    m.delivered_at,
+    m.read_at
+FROM message_changes c
+LEFT JOIN message_logs m ON m.id = c.message_id
+WHERE c.device_id = @device_id
+  AND (c.xid, c.seq) > (@since_xid::bigint, @since_seq::bigint)
+  -- Changes of transactions newer than the oldest one still running wait, as that one may
+  -- still commit changes that sort before them
+  AND c.xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY c.xid, c.seq
+LIMIT @query_limit;
+
+-- name: GetLatestMessageChangePosition :one
+SELECT xid, seq
+FROM message_changes
+WHERE device_id = $1
+  AND xid < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY xid DESC, seq DESC
+LIMIT 1;
+
+-- name: GetMessageReactions :many
+SELECT * FROM message_reactions
+WHERE message_id = ANY(@message_ids::uuid[])
+ORDER BY reacted_at;
+
+-- name: UpsertMessageReaction :execrows
+INSERT INTO message_reactions (message_id, sender_jid, emoji, reacted_at)
+SELECT m.id, @sender_jid::varchar, @emoji::varchar, @reacted_at::timestamptz
+FROM message_logs m
+WHERE m.device_id = @device_id AND m.wa_message_id = @wa_message_id
+ON CONFLICT (message_id, sender_jid) DO UPDATE SET
+    emoji = EXCLUDED.emoji,
+    reacted_at = EXCLUDED.reacted_at;
+
+-- name: DeleteMessageReaction :exec
+DELETE FROM message_reactions r
+USING message_logs m
+WHERE r.message_id = m.id
+  AND m.device_id = @device_id AND m.wa_message_id = @wa_message_id
+  AND r.sender_jid = @sender_jid;
+
+-- name: EditMessageContent :execrows
+UPDATE message_logs
+SET content = $3
+WHERE device_id = $1 AND wa_message_id = $2;
+
+-- name: DeleteMessageByWaID :execrows
+DELETE FROM message_logs
+WHERE device_id = $1 AND wa_message_id = $2;
@@ -9,23 +9,57 @@ SELECT
     t.last_message_type,
     t.last_message_direction,
     t.unread_count,
-    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name
+    t.status,
+    t.assigned_user_id,
+    t.is_pinned,
+    t.is_archived,
+    t.is_muted,
+    t.muted_until,
+    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name,
+    u.email AS assignee_email,
+    ARRAY(
+        SELECT tl.label_id
+        FROM thread_labels tl
+        WHERE tl.device_id = t.device_id AND tl.chat_jid = t.chat_jid
+    )::uuid[] AS label_ids
 FROM message_threads t
 LEFT JOIN whatsapp_contacts wc ON wc.device_id = t.device_id AND wc.jid = t.chat_jid
 LEFT JOIN whatsapp_groups wg ON wg.device_id = t.device_id AND wg.group_id = t.chat_jid
+LEFT JOIN users u ON u.id = t.assigned_user_id
 WHERE t.device_id = @device_id
-ORDER BY t.last_message_at DESC
+  AND (sqlc.narg('status')::varchar IS NULL OR t.status = sqlc.narg('status'))
+  AND (sqlc.narg('assigned_user_id')::int IS NULL OR t.assigned_user_id = sqlc.narg('assigned_user_id'))
+  AND (NOT @unassigned_only::bool OR t.assigned_user_id IS NULL)
+  AND (sqlc.narg('archived')::bool IS NULL OR t.is_archived = sqlc.narg('archived'))
+  AND (sqlc.narg('label_id')::uuid IS NULL OR EXISTS (
+      SELECT 1 FROM thread_labels tl
+      WHERE tl.device_id = t.device_id AND tl.chat_jid = t.chat_jid AND tl.label_id = sqlc.narg('label_id')
+  ))
+  AND (sqlc.narg('cursor_pinned')::bool IS NULL OR (t.is_pinned, t.last_message_at, t.id) < (
+      sqlc.narg('cursor_pinned'), sqlc.narg('cursor_at')::timestamptz, sqlc.narg('cursor_id')::uuid
+  ))
+  AND (sqlc.narg('before_pinned')::bool IS NULL OR (t.is_pinned, t.last_message_at, t.id) > (
+      sqlc.narg('before_pinned'), sqlc.narg('before_at')::timestamptz, sqlc.narg('before_id')::uuid
+  ))
+ORDER BY
+    CASE WHEN sqlc.narg('before_pinned')::bool IS NULL THEN t.is_pinned END DESC,
+    CASE WHEN sqlc.narg('before_pinned')::bool IS NULL THEN t.last_message_at END DESC,
+    CASE WHEN sqlc.narg('before_pinned')::bool IS NULL THEN t.id END DESC,
+    t.is_pinned ASC, t.last_message_at ASC, t.id ASC
 LIMIT @query_limit OFFSET @query_offset;
 
 -- name: GetThreadMessages :many
 SELECT
     m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
-    m.message_type, m.content, m.media_url, m.media_filename,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
     m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
     m.read_at, m.direction, m.wa_message_id, m.sender_jid,
     COALESCE(wc.full_name, wc.push_name, '') AS sender_name
 FROM message_logs m
-LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id AND wc.jid = m.sender_jid
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
 WHERE m.device_id = $1 AND m.recipient = $2
 ORDER BY m.sent_at ASC
 LIMIT $3 OFFSET $4;
@@ -45,9 +79,65 @@ ON CONFLICT (device_id, chat_jid) DO UPDATE SET
         THEN message_threads.unread_count + 1
         ELSE message_threads.unread_count
     END,
+    away_sent_at = CASE
+        WHEN EXCLUDED.last_message_direction = 'outgoing' AND NOT @is_automated::bool
+        THEN NULL
+        ELSE message_threads.away_sent_at
+    END,
     updated_at = NOW();
 
 -- name: ResetThreadUnread :exec
 UPDATE message_threads
 SET unread_count = 0, updated_at = NOW()
 WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: SetThreadPinned :exec
+UPDATE message_threads
+SET is_pinned = $3, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: SetThreadArchived :exec
+UPDATE message_threads
+SET is_archived = $3,
+    is_pinned = CASE WHEN $3 THEN FALSE ELSE is_pinned END,
+    updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: SetThreadMuted :exec
+UPDATE message_threads
+SET is_muted = $3, muted_until = $4, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: GetThreadMessagesBefore :many
+SELECT
+    m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
+    m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
+    m.read_at, m.direction, m.wa_message_id, m.sender_jid,
+    COALESCE(wc.full_name, wc.push_name, '') AS sender_name
+FROM message_logs m
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
+WHERE m.device_id = @device_id AND m.recipient = @recipient
+  AND (m.sent_at, m.id) < (@sent_at::timestamptz, @id::uuid)
+ORDER BY m.sent_at DESC, m.id DESC
+LIMIT @query_limit;
+
+-- name: GetThreadMessagesAfter :many
+SELECT
+    m.id, m.device_id, m.user_id, m.recipient, m.recipient_type,
+    m.message_type, m.content, m.media_url, m.media_filename, m.media_deleted_at,
+    m.buttons, m.template_id, m.status, m.sent_at, m.delivered_at,
+    m.read_at, m.direction, m.wa_message_id, m.sender_jid,
+    COALESCE(wc.full_name, wc.push_name, '') AS sender_name
+FROM message_logs m
+LEFT JOIN group_participants gp ON gp.device_id = m.device_id AND gp.group_jid = m.recipient
+    AND gp.participant_jid = regexp_replace(m.sender_jid, ':[0-9]+@', '@')
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id
+    AND wc.jid = COALESCE(gp.phone_jid, regexp_replace(m.sender_jid, ':[0-9]+@', '@'))
+WHERE m.device_id = @device_id AND m.recipient = @recipient
+  AND (m.sent_at, m.id) > (@sent_at::timestamptz, @id::uuid)
+ORDER BY m.sent_at ASC, m.id ASC
+LIMIT @query_limit;
@@ -0,0 +1,38 @@
+-- name: AddOptOut :one
+INSERT INTO opt_outs (user_id, jid, device_id, source, keyword, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, jid)
+    DO UPDATE SET device_id = EXCLUDED.device_id,
+                  source    = EXCLUDED.source,
+                  keyword   = EXCLUDED.keyword,
+                  reason    = EXCLUDED.reason
+RETURNING *;
+
+-- name: RemoveOptOut :exec
+DELETE FROM opt_outs
+WHERE user_id = $1 AND jid = $2;
+
+-- name: GetOptOuts :many
+SELECT *
+FROM opt_outs
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: IsOptedOut :one
+SELECT EXISTS (
+    SELECT 1 FROM opt_outs WHERE user_id = $1 AND jid = $2
+);
+
+-- name: GetOptOutSettings :one
+SELECT *
+FROM opt_out_settings
+WHERE user_id = $1;
+
+-- name: UpsertOptOutSettings :one
+INSERT INTO opt_out_settings (user_id, keywords, send_confirmation, confirmation_message)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id)
+    DO UPDATE SET keywords             = EXCLUDED.keywords,
+                  send_confirmation    = EXCLUDED.send_confirmation,
+                  confirmation_message = EXCLUDED.confirmation_message
+RETURNING *;
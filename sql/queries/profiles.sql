@@ -0,0 +1,45 @@
+-- name: GetProfile :one
+SELECT * FROM profiles
+WHERE device_id = @device_id AND jid = @jid;
+
+-- name: ListProfilePictureIDs :many
+SELECT jid, picture_id FROM profiles
+WHERE device_id = @device_id AND picture_id IS NOT NULL;
+
+-- name: SetProfilePicture :exec
+INSERT INTO profiles (device_id, jid, picture_id, picture_url, picture_updated_at)
+VALUES (@device_id, @jid, @picture_id, @picture_url, @picture_updated_at)
+ON CONFLICT (device_id, jid) DO UPDATE SET
+    picture_id = EXCLUDED.picture_id,
+    picture_url = EXCLUDED.picture_url,
+    picture_updated_at = EXCLUDED.picture_updated_at;
+
+-- name: SetProfileAbout :exec
+INSERT INTO profiles (device_id, jid, about, about_updated_at)
+VALUES (@device_id, @jid, @about, @about_updated_at)
+ON CONFLICT (device_id, jid) DO UPDATE SET
+    about = EXCLUDED.about,
+    about_updated_at = CASE
+        WHEN profiles.about IS DISTINCT FROM EXCLUDED.about THEN EXCLUDED.about_updated_at
+        ELSE profiles.about_updated_at
+    END;
+
+-- name: SetBusinessProfile :exec
+INSERT INTO profiles (device_id, jid, is_business, business_category, business_description, business_address,
+                      business_email, business_websites, business_hours, business_timezone)
+VALUES (@device_id, @jid, @is_business, @business_category, @business_description, @business_address,
+        @business_email, @business_websites, @business_hours, @business_timezone)
+ON CONFLICT (device_id, jid) DO UPDATE SET
+    is_business = EXCLUDED.is_business,
+    business_category = EXCLUDED.business_category,
+    business_description = EXCLUDED.business_description,
+    business_address = EXCLUDED.business_address,
+    business_email = EXCLUDED.business_email,
+    business_websites = EXCLUDED.business_websites,
+    business_hours = EXCLUDED.business_hours,
+    business_timezone = EXCLUDED.business_timezone;
+
+-- name: ClearProfilePictureByURL :exec
+UPDATE profiles
+SET picture_id = NULL, picture_url = NULL, picture_updated_at = NOW()
+WHERE device_id = @device_id AND picture_url = @picture_url;
@@ -0,0 +1,11 @@
+-- name: GetReadReceiptSettings :one
+SELECT *
+FROM read_receipt_settings
+WHERE device_id = $1;
+
+-- name: UpsertReadReceiptSettings :one
+INSERT INTO read_receipt_settings (device_id, is_enabled)
+VALUES ($1, $2)
+ON CONFLICT (device_id) DO UPDATE SET
+    is_enabled = EXCLUDED.is_enabled
+RETURNING *;
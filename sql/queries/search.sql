@@ -0,0 +1,82 @@
+-- name: GetSearchSettings :one
+SELECT *
+FROM search_settings
+WHERE device_id = $1;
+
+-- name: UpsertSearchSettings :one
+INSERT INTO search_settings (device_id, language)
+VALUES ($1, $2)
+ON CONFLICT (device_id) DO UPDATE SET
+    language = EXCLUDED.language
+RETURNING *;
+
+-- name: IsTextSearchConfig :one
+SELECT EXISTS (
+    SELECT 1 FROM pg_catalog.pg_ts_config WHERE cfgname = $1
+);
+
+-- name: ReindexMessageSearch :execrows
+UPDATE message_logs
+SET content = content
+WHERE device_id = $1;
+
+-- name: SearchMessages :many
+WITH q AS (
+    SELECT c.cfg, websearch_to_tsquery(c.cfg, @query) AS tsq
+    FROM (
+        SELECT COALESCE((SELECT ss.language FROM search_settings ss WHERE ss.device_id = @device_id), 'simple')::regconfig AS cfg
+    ) c
+)
+SELECT
+    m.id,
+    m.recipient AS chat_jid,
+    m.sender_jid,
+    m.direction,
+    m.message_type,
+    m.content,
+    m.media_filename,
+    m.sent_at,
+    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name,
+    ts_headline(
+        q.cfg,
+        translate(CASE WHEN m.content <> '' THEN m.content ELSE COALESCE(m.media_filename, '') END, chr(2) || chr(3), ''),
+        q.tsq,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MinWords=5, MaxWords=25, MaxFragments=2'
+    ) AS snippet,
+    ts_rank(s.document, q.tsq) AS rank
+FROM message_logs m
+JOIN message_search s ON s.message_id = m.id
+CROSS JOIN q
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = m.device_id AND wc.jid = m.recipient
+LEFT JOIN whatsapp_groups wg ON wg.device_id = m.device_id AND wg.group_id = m.recipient
+WHERE m.device_id = @device_id
+  AND s.document @@ q.tsq
+  AND (sqlc.narg('chat_jid')::varchar IS NULL OR m.recipient = sqlc.narg('chat_jid'))
+  AND (sqlc.narg('direction')::varchar IS NULL OR m.direction = sqlc.narg('direction'))
+  AND (sqlc.narg('message_type')::varchar IS NULL OR m.message_type = sqlc.narg('message_type'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR m.sent_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR m.sent_at < sqlc.narg('until'))
+ORDER BY rank DESC, m.sent_at DESC
+LIMIT @query_limit OFFSET @query_offset;
+
+-- name: SearchThreads :many
+SELECT
+    t.id,
+    t.chat_jid,
+    t.chat_type,
+    t.last_message_at,
+    t.last_message_content,
+    t.unread_count,
+    t.status,
+    COALESCE(wc.full_name, wc.push_name, wg.group_name, '') AS chat_name
+FROM message_threads t
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = t.device_id AND wc.jid = t.chat_jid
+LEFT JOIN whatsapp_groups wg ON wg.device_id = t.device_id AND wg.group_id = t.chat_jid
+WHERE t.device_id = @device_id
+  AND (wc.full_name ILIKE @pattern
+       OR wc.push_name ILIKE @pattern
+       OR wc.business_name ILIKE @pattern
+       OR wg.group_name ILIKE @pattern
+       OR t.chat_jid ILIKE @pattern)
+ORDER BY t.last_message_at DESC
+LIMIT @query_limit;
@@ -0,0 +1,36 @@
+-- name: CreateStatusPost :one
+INSERT INTO status_posts (device_id, wa_message_id, type, content, background_color, font, viewers)
+VALUES (@device_id, @wa_message_id, @type, @content, @background_color, @font, @viewers)
+RETURNING *;
+
+-- name: GetStatusPost :one
+SELECT * FROM status_posts
+WHERE device_id = @device_id AND id = @id;
+
+-- name: ListStatusPosts :many
+SELECT
+    sp.id, sp.wa_message_id, sp.type, sp.content, sp.background_color, sp.font, sp.viewers, sp.posted_at,
+    COUNT(sv.id) AS view_count
+FROM status_posts sp
+LEFT JOIN status_views sv ON sv.status_post_id = sp.id
+WHERE sp.device_id = @device_id
+GROUP BY sp.id
+ORDER BY sp.posted_at DESC
+LIMIT @query_limit OFFSET @query_offset;
+
+-- name: RecordStatusView :exec
+INSERT INTO status_views (status_post_id, viewer_jid, phone_jid, viewed_at)
+SELECT sp.id, @viewer_jid, @phone_jid, @viewed_at
+FROM status_posts sp
+WHERE sp.device_id = @device_id AND sp.wa_message_id = @wa_message_id
+ON CONFLICT (status_post_id, viewer_jid) DO NOTHING;
+
+-- name: ListStatusViews :many
+SELECT
+    sv.viewer_jid, sv.phone_jid, sv.viewed_at,
+    COALESCE(wc.full_name, wc.push_name, '') AS name
+FROM status_views sv
+JOIN status_posts sp ON sp.id = sv.status_post_id
+LEFT JOIN whatsapp_contacts wc ON wc.device_id = sp.device_id AND wc.jid = COALESCE(sv.phone_jid, sv.viewer_jid)
+WHERE sv.status_post_id = @status_post_id
+ORDER BY sv.viewed_at DESC;
@@ -0,0 +1,67 @@
+-- name: AddDeviceMember :exec
+INSERT INTO device_members (device_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (device_id, user_id) DO NOTHING;
+
+-- name: RemoveDeviceMember :execrows
+DELETE FROM device_members
+WHERE device_id = $1 AND user_id = $2;
+
+-- name: GetDeviceMembers :many
+SELECT m.device_id, m.user_id, u.email, m.created_at
+FROM device_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.device_id = $1
+ORDER BY m.created_at ASC;
+
+-- name: GetThread :one
+SELECT *
+FROM message_threads
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: AssignThread :exec
+UPDATE message_threads
+SET assigned_user_id = $3, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: SetThreadStatus :exec
+UPDATE message_threads
+SET status = $3, updated_at = NOW()
+WHERE device_id = $1 AND chat_jid = $2;
+
+-- name: ReopenThread :one
+UPDATE message_threads t
+SET status = 'open', updated_at = NOW()
+FROM message_threads old
+WHERE t.id = old.id
+  AND t.device_id = $1
+  AND t.chat_jid = $2
+  AND old.status <> 'open'
+RETURNING old.status;
+
+-- name: CreateThreadNote :one
+INSERT INTO thread_notes (device_id, chat_jid, user_id, content)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetThreadNotes :many
+SELECT n.id, n.device_id, n.chat_jid, n.user_id, u.email AS author_email, n.content, n.created_at
+FROM thread_notes n
+JOIN users u ON u.id = n.user_id
+WHERE n.device_id = $1 AND n.chat_jid = $2
+ORDER BY n.created_at ASC;
+
+-- name: DeleteThreadNote :execrows
+DELETE FROM thread_notes
+WHERE id = $1 AND device_id = $2 AND user_id = $3 AND chat_jid = $4;
+
+-- name: CreateThreadAuditLog :exec
+INSERT INTO thread_audit_logs (device_id, chat_jid, user_id, action, old_value, new_value)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetThreadAuditLogs :many
+SELECT a.id, a.device_id, a.chat_jid, a.user_id, u.email AS user_email, a.action, a.old_value, a.new_value, a.created_at
+FROM thread_audit_logs a
+LEFT JOIN users u ON u.id = a.user_id
+WHERE a.device_id = $1 AND a.chat_jid = $2
+ORDER BY a.created_at ASC;
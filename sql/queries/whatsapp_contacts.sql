@@ -16,7 +16,36 @@ ON CONFLICT (device_id, jid)
 
 
 -- name: GetDeviceContacts :many
-SELECT *
-FROM whatsapp_contacts
-WHERE device_id = $1
-ORDER BY COALESCE(full_name, push_name, jid) ASC;
+SELECT wc.*,
+       p.picture_url,
+       p.about,
+       COALESCE(p.is_business, FALSE) AS is_business,
+       p.business_category
+FROM whatsapp_contacts wc
+LEFT JOIN profiles p ON p.device_id = wc.device_id AND p.jid = wc.jid
+WHERE wc.device_id = $1
+ORDER BY COALESCE(wc.full_name, wc.push_name, wc.jid) ASC;
+
+-- name: SetContactPresence :exec
+INSERT INTO whatsapp_contacts (device_id, jid, is_online, last_seen_at)
+VALUES (@device_id, @jid, @is_online, sqlc.narg('last_seen_at'))
+ON CONFLICT (device_id, jid)
+    DO UPDATE SET is_online    = EXCLUDED.is_online,
+                  last_seen_at = COALESCE(EXCLUDED.last_seen_at, whatsapp_contacts.last_seen_at);
+
+-- name: UpsertContactPushName :exec
+INSERT INTO whatsapp_contacts (device_id, jid, push_name, phone_number, updated_at)
+VALUES (@device_id, @jid, @push_name, @phone_number, NOW())
+ON CONFLICT (device_id, jid)
+    DO UPDATE SET push_name  = EXCLUDED.push_name,
+                  updated_at = EXCLUDED.updated_at
+    WHERE whatsapp_contacts.push_name IS DISTINCT FROM EXCLUDED.push_name;
+
+-- name: DeleteStaleContacts :execrows
+DELETE FROM whatsapp_contacts wc
+WHERE wc.device_id = @device_id
+  AND NOT (wc.jid = ANY (@jids::text[]))
+  AND NOT EXISTS (SELECT 1
+                  FROM contact_details cd
+                  WHERE cd.device_id = wc.device_id
+                    AND cd.jid = wc.jid);
@@ -4,13 +4,23 @@ INSERT INTO whatsapp_groups (device_id,
                              group_name,
                              group_description,
                              participant_count,
-                             updated_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+                             is_announce,
+                             is_locked,
+                             updated_at,
+                             is_community,
+                             parent_group_id,
+                             is_announcement_group)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (device_id, group_id)
-    DO UPDATE SET group_name        = EXCLUDED.group_name,
-                  group_description = EXCLUDED.group_description,
-                  participant_count = EXCLUDED.participant_count,
-                  updated_at        = EXCLUDED.updated_at;
+    DO UPDATE SET group_name            = EXCLUDED.group_name,
+                  group_description     = EXCLUDED.group_description,
+                  participant_count     = EXCLUDED.participant_count,
+                  is_announce           = EXCLUDED.is_announce,
+                  is_locked             = EXCLUDED.is_locked,
+                  updated_at            = EXCLUDED.updated_at,
+                  is_community          = EXCLUDED.is_community,
+                  parent_group_id       = EXCLUDED.parent_group_id,
+                  is_announcement_group = EXCLUDED.is_announcement_group;
 
 
 -- name: GetDeviceGroups :many
@@ -18,3 +28,31 @@ SELECT *
 FROM whatsapp_groups
 WHERE device_id = $1
 ORDER BY group_name ASC;
+
+-- name: DeleteWhatsAppGroup :exec
+DELETE FROM whatsapp_groups
+WHERE device_id = $1 AND group_id = $2;
+
+-- name: SetWhatsAppGroupName :exec
+UPDATE whatsapp_groups
+SET group_name = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2;
+
+-- name: SetWhatsAppGroupDescription :exec
+UPDATE whatsapp_groups
+SET group_description = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2;
+
+-- name: RefreshWhatsAppGroupParticipantCount :exec
+UPDATE whatsapp_groups
+SET participant_count = (
+        SELECT COUNT(*) FROM group_participants gp
+        WHERE gp.device_id = whatsapp_groups.device_id AND gp.group_jid = whatsapp_groups.group_id
+    ),
+    updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2;
+
+-- name: SetWhatsAppGroupParent :exec
+UPDATE whatsapp_groups
+SET parent_group_id = $3, updated_at = NOW()
+WHERE device_id = $1 AND group_id = $2;
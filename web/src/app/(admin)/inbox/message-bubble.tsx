@@ -28,6 +28,8 @@ function MediaAttachment({ message }: { message: MessageLog }) {
   const type = message.message_type;
   const filename = message.media_filename || message.content;
   const hasUrl = !!message.media_url;
+  // Media removed by the retention rules keeps its message as a placeholder
+  const label = message.media_deleted_at ? `${filename} (media removed)` : filename;
 
   if (type === "image") {
     if (hasUrl) {
@@ -51,7 +53,7 @@ function MediaAttachment({ message }: { message: MessageLog }) {
     return (
       <div className="flex items-center gap-2 rounded-md bg-background/50 px-2.5 py-2 mb-1">
         <ImageIcon className="h-4 w-4 shrink-0 text-blue-500" />
-        <span className="text-xs truncate">{filename}</span>
+        <span className="text-xs truncate">{label}</span>
       </div>
     );
   }
@@ -74,7 +76,7 @@ function MediaAttachment({ message }: { message: MessageLog }) {
     return (
       <div className="flex items-center gap-2 rounded-md bg-background/50 px-2.5 py-2 mb-1">
         <VideoIcon className="h-4 w-4 shrink-0 text-purple-500" />
-        <span className="text-xs truncate">{filename}</span>
+        <span className="text-xs truncate">{label}</span>
       </div>
     );
   }
@@ -97,7 +99,7 @@ function MediaAttachment({ message }: { message: MessageLog }) {
     return (
       <div className="flex items-center gap-2 rounded-md bg-background/50 px-2.5 py-2 mb-1">
         <MusicIcon className="h-4 w-4 shrink-0 text-orange-500" />
-        <span className="text-xs truncate">{filename}</span>
+        <span className="text-xs truncate">{label}</span>
       </div>
     );
   }
@@ -112,7 +114,7 @@ function MediaAttachment({ message }: { message: MessageLog }) {
         className="flex items-center gap-2 rounded-md bg-background/50 px-2.5 py-2 mb-1 hover:bg-background/80 transition-colors cursor-pointer border shadow-sm"
       >
         <FileIcon className="h-4 w-4 shrink-0 text-emerald-500" />
-        <span className="text-xs truncate font-medium hover:underline">{filename}</span>
+        <span className="text-xs truncate font-medium hover:underline">{label}</span>
       </a>
     );
   }
@@ -34,7 +34,9 @@ export async function GET(
   const targetUrl = `${baseUrl}/api/media/${encodeURIComponent(decoded)}`;
 
   try {
-    const response = await fetch(targetUrl);
+    const response = await fetch(targetUrl, {
+      headers: { Authorization: `Bearer ${session.access_token}` },
+    });
 
     if (!response.ok) {
       return NextResponse.json(
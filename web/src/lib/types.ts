@@ -63,6 +63,7 @@ export type MessageLog = {
   content: string;
   media_url: string | null;
   media_filename: string | null;
+  media_deleted_at?: string | null;
   status: string;
   direction: string;
   wa_message_id: string | null;